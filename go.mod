@@ -8,6 +8,7 @@ require (
 	github.com/google/go-cmp v0.6.0
 	github.com/google/uuid v1.5.0
 	github.com/pkg/errors v0.9.1
+	google.golang.org/protobuf v1.34.2
 )
 
 require github.com/sergi/go-diff v1.3.1 // indirect
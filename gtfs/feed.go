@@ -0,0 +1,380 @@
+package gtfs
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// dateFormat is the format of dates in GTFS files
+const dateFormat = "20060102"
+
+// lookaheadDays is the number of days after the requested time that departures are searched for
+const lookaheadDays = 7
+
+// Feed stores the GTFS timetable, indexed by stop and service
+type Feed struct {
+	Agencies      []Agency
+	Stops         map[string]*Stop
+	Routes        map[string]*Route
+	Trips         map[string]*Trip
+	Calendars     map[string]*Calendar
+	CalendarDates map[string]map[string]int
+
+	// Location is the timezone of the timetable, from the first agency
+	Location *time.Location
+
+	stopTimes    []StopTime
+	byStop       map[string][]int
+	lastSequence map[string]int
+	byStopCode   map[string]string
+	byStopLower  map[string]string
+}
+
+// Departure represents a scheduled departure from a stop
+type Departure struct {
+	Stop     *Stop
+	Route    *Route
+	Trip     *Trip
+	StopTime StopTime
+	Time     time.Time
+}
+
+func (f *Feed) addAgency(r row) error {
+	f.Agencies = append(f.Agencies, Agency{
+		ID:       r.get("agency_id"),
+		Name:     r.get("agency_name"),
+		Timezone: r.get("agency_timezone"),
+	})
+
+	return nil
+}
+
+func (f *Feed) addStop(r row) error {
+	stop := &Stop{
+		ID:   r.get("stop_id"),
+		Code: r.get("stop_code"),
+		Name: r.get("stop_name"),
+	}
+
+	var err error
+	if stop.Latitude, err = r.float("stop_lat"); err != nil {
+		return err
+	}
+	if stop.Longitude, err = r.float("stop_lon"); err != nil {
+		return err
+	}
+
+	if f.Stops == nil {
+		f.Stops = make(map[string]*Stop)
+	}
+	f.Stops[stop.ID] = stop
+
+	return nil
+}
+
+func (f *Feed) addRoute(r row) error {
+	routeType, err := r.int("route_type")
+	if err != nil {
+		return err
+	}
+
+	if f.Routes == nil {
+		f.Routes = make(map[string]*Route)
+	}
+	f.Routes[r.get("route_id")] = &Route{
+		ID:        r.get("route_id"),
+		AgencyID:  r.get("agency_id"),
+		ShortName: r.get("route_short_name"),
+		LongName:  r.get("route_long_name"),
+		Type:      routeType,
+	}
+
+	return nil
+}
+
+func (f *Feed) addTrip(r row) error {
+	if f.Trips == nil {
+		f.Trips = make(map[string]*Trip)
+	}
+	f.Trips[r.get("trip_id")] = &Trip{
+		ID:          r.get("trip_id"),
+		RouteID:     r.get("route_id"),
+		ServiceID:   r.get("service_id"),
+		Headsign:    r.get("trip_headsign"),
+		DirectionID: r.get("direction_id"),
+	}
+
+	return nil
+}
+
+func (f *Feed) addStopTime(r row) error {
+	stopTime := StopTime{
+		TripID: r.get("trip_id"),
+		StopID: r.get("stop_id"),
+	}
+
+	var err error
+	if stopTime.StopSequence, err = r.int("stop_sequence"); err != nil {
+		return err
+	}
+	if stopTime.PickupType, err = r.int("pickup_type"); err != nil {
+		return err
+	}
+
+	arrival, hasArrival, err := r.time("arrival_time")
+	if err != nil {
+		return err
+	}
+	departure, hasDeparture, err := r.time("departure_time")
+	if err != nil {
+		return err
+	}
+
+	// Times are only required at timepoints, the stops in between are skipped
+	if !hasArrival && !hasDeparture {
+		return nil
+	}
+	if !hasArrival {
+		arrival = departure
+	}
+	if !hasDeparture {
+		departure = arrival
+	}
+	stopTime.ArrivalTime = arrival
+	stopTime.DepartureTime = departure
+
+	f.stopTimes = append(f.stopTimes, stopTime)
+
+	return nil
+}
+
+func (f *Feed) addCalendar(r row) error {
+	calendar := &Calendar{
+		ServiceID: r.get("service_id"),
+		StartDate: r.get("start_date"),
+		EndDate:   r.get("end_date"),
+	}
+
+	days := []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+	for i, day := range days {
+		calendar.Weekdays[i] = r.get(day) == "1"
+	}
+
+	if f.Calendars == nil {
+		f.Calendars = make(map[string]*Calendar)
+	}
+	f.Calendars[calendar.ServiceID] = calendar
+
+	return nil
+}
+
+func (f *Feed) addCalendarDate(r row) error {
+	exceptionType, err := r.int("exception_type")
+	if err != nil {
+		return err
+	}
+	if exceptionType != ServiceAdded && exceptionType != ServiceRemoved {
+		return r.errorf("invalid exception_type %d", exceptionType)
+	}
+
+	serviceID := r.get("service_id")
+	if f.CalendarDates == nil {
+		f.CalendarDates = make(map[string]map[string]int)
+	}
+	if f.CalendarDates[serviceID] == nil {
+		f.CalendarDates[serviceID] = make(map[string]int)
+	}
+	f.CalendarDates[serviceID][r.get("date")] = exceptionType
+
+	return nil
+}
+
+// index builds the indexes used to find departures once all the files are loaded
+func (f *Feed) index() error {
+	if f.Calendars == nil && f.CalendarDates == nil {
+		return errors.Errorf("GTFS feed must have %s or %s", calendarFile, calendarDatesFile)
+	}
+
+	f.Location = time.UTC
+	if len(f.Agencies) > 0 && f.Agencies[0].Timezone != "" {
+		location, err := time.LoadLocation(f.Agencies[0].Timezone)
+		if err != nil {
+			return errors.Wrapf(err, "invalid agency timezone")
+		}
+		f.Location = location
+	}
+
+	f.byStopCode = make(map[string]string)
+	f.byStopLower = make(map[string]string)
+	for id, stop := range f.Stops {
+		f.byStopLower[strings.ToLower(id)] = id
+		if stop.Code != "" {
+			f.byStopCode[strings.ToLower(stop.Code)] = id
+		}
+	}
+
+	sort.SliceStable(f.stopTimes, func(i, j int) bool {
+		return f.stopTimes[i].DepartureTime < f.stopTimes[j].DepartureTime
+	})
+
+	f.byStop = make(map[string][]int)
+	f.lastSequence = make(map[string]int)
+	for i, stopTime := range f.stopTimes {
+		f.byStop[stopTime.StopID] = append(f.byStop[stopTime.StopID], i)
+		if last, ok := f.lastSequence[stopTime.TripID]; !ok || stopTime.StopSequence > last {
+			f.lastSequence[stopTime.TripID] = stopTime.StopSequence
+		}
+	}
+
+	return nil
+}
+
+// FindStop returns the stop with the ID or stop code, ignoring case
+func (f *Feed) FindStop(idOrCode string) (*Stop, bool) {
+	key := strings.ToLower(strings.TrimSpace(idOrCode))
+
+	if id, ok := f.byStopLower[key]; ok {
+		return f.Stops[id], true
+	}
+	if id, ok := f.byStopCode[key]; ok {
+		return f.Stops[id], true
+	}
+
+	return nil, false
+}
+
+// ActiveOn returns whether the service runs on the date
+func (f *Feed) ActiveOn(serviceID string, date time.Time) bool {
+	day := date.Format(dateFormat)
+
+	switch f.CalendarDates[serviceID][day] {
+	case ServiceAdded:
+		return true
+	case ServiceRemoved:
+		return false
+	}
+
+	calendar, ok := f.Calendars[serviceID]
+	if !ok {
+		return false
+	}
+
+	return calendar.Weekdays[date.Weekday()] && day >= calendar.StartDate && day <= calendar.EndDate
+}
+
+// Departures returns up to limit scheduled departures from the stop at or after the time, earliest first.
+// Departures are searched for up to a week ahead.
+func (f *Feed) Departures(stopID string, from time.Time, limit int) []Departure {
+	stop, ok := f.FindStop(stopID)
+	if !ok || limit <= 0 {
+		return nil
+	}
+
+	from = from.In(f.Location)
+	date := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, f.Location)
+
+	var departures []Departure
+	// Start the day before as times can be after midnight of the service day
+	for day := -1; day <= lookaheadDays; day++ {
+		serviceDate := date.AddDate(0, 0, day)
+		departures = append(departures, f.departuresOn(stop, serviceDate, from)...)
+
+		if day < 0 {
+			continue
+		}
+
+		sort.SliceStable(departures, func(i, j int) bool {
+			return departures[i].Time.Before(departures[j].Time)
+		})
+
+		// Departures on the next service day can't be earlier than those found so far
+		if len(departures) >= limit && departures[limit-1].Time.Before(serviceDayStart(serviceDate.AddDate(0, 0, 1))) {
+			break
+		}
+	}
+
+	if len(departures) > limit {
+		departures = departures[:limit]
+	}
+
+	return departures
+}
+
+// ScheduledDeparture returns the scheduled departure of the trip from the stop on the service date,
+// in the YYYYMMDD format of GTFS dates, or false if the trip doesn't run on the date or has no time at the stop
+func (f *Feed) ScheduledDeparture(tripID string, stopID string, serviceDate string) (Departure, bool) {
+	stop, ok := f.FindStop(stopID)
+	if !ok {
+		return Departure{}, false
+	}
+	trip, ok := f.Trips[tripID]
+	if !ok {
+		return Departure{}, false
+	}
+	date, err := time.ParseInLocation(dateFormat, serviceDate, f.Location)
+	if err != nil || !f.ActiveOn(trip.ServiceID, date) {
+		return Departure{}, false
+	}
+
+	for _, i := range f.byStop[stop.ID] {
+		stopTime := f.stopTimes[i]
+		if stopTime.TripID != tripID {
+			continue
+		}
+
+		return Departure{
+			Stop:     stop,
+			Route:    f.Routes[trip.RouteID],
+			Trip:     trip,
+			StopTime: stopTime,
+			Time:     serviceDayStart(date).Add(time.Duration(stopTime.DepartureTime) * time.Second),
+		}, true
+	}
+
+	return Departure{}, false
+}
+
+// departuresOn returns the departures from the stop on the service date at or after the time,
+// leaving out trips that end at the stop or don't pick up passengers there
+func (f *Feed) departuresOn(stop *Stop, serviceDate time.Time, from time.Time) []Departure {
+	start := serviceDayStart(serviceDate)
+
+	var departures []Departure
+	for _, i := range f.byStop[stop.ID] {
+		stopTime := f.stopTimes[i]
+		if stopTime.PickupType == NoPickup || stopTime.StopSequence == f.lastSequence[stopTime.TripID] {
+			continue
+		}
+
+		departureTime := start.Add(time.Duration(stopTime.DepartureTime) * time.Second)
+		if departureTime.Before(from) {
+			continue
+		}
+
+		trip, ok := f.Trips[stopTime.TripID]
+		if !ok || !f.ActiveOn(trip.ServiceID, serviceDate) {
+			continue
+		}
+
+		departures = append(departures, Departure{
+			Stop:     stop,
+			Route:    f.Routes[trip.RouteID],
+			Trip:     trip,
+			StopTime: stopTime,
+			Time:     departureTime,
+		})
+	}
+
+	return departures
+}
+
+// serviceDayStart returns the time that stop times are relative to, which is noon minus 12 hours
+// so that the times are correct on days when the clocks change
+func serviceDayStart(serviceDate time.Time) time.Time {
+	noon := time.Date(serviceDate.Year(), serviceDate.Month(), serviceDate.Day(), 12, 0, 0, 0, serviceDate.Location())
+
+	return noon.Add(-12 * time.Hour)
+}
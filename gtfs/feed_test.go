@@ -0,0 +1,370 @@
+package gtfs_test
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/gtfs"
+	"github.com/google/go-cmp/cmp"
+)
+
+// zipFeed returns the path of a zip of the files in the testdata feed directory
+func zipFeed(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "feed.zip")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	files, _ := filepath.Glob("testdata/feed/*.txt")
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		zf, err := w.Create(filepath.Base(file))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if _, err := zf.Write(data); err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "Directory", path: "testdata/feed"},
+		{name: "Zip file", path: zipFeed(t)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			feed, err := gtfs.Load(test.path)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			if len(feed.Stops) != 3 || len(feed.Routes) != 2 || len(feed.Trips) != 6 {
+				t.Fatalf("Expected 3 stops, 2 routes and 6 trips; got %d, %d and %d", len(feed.Stops), len(feed.Routes), len(feed.Trips))
+			}
+			if feed.Location.String() != "Europe/London" {
+				t.Fatalf("Expected location Europe/London; got %s", feed.Location)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	valid := fstest.MapFS{
+		"stops.txt":      {Data: []byte("stop_id,stop_name\n020035811,The Green\n")},
+		"routes.txt":     {Data: []byte("route_id,route_short_name,route_type\nR42,42,3\n")},
+		"trips.txt":      {Data: []byte("route_id,service_id,trip_id\nR42,WEEKDAY,T42_1\n")},
+		"stop_times.txt": {Data: []byte("trip_id,arrival_time,departure_time,stop_id,stop_sequence\nT42_1,09:00:00,09:00:00,020035811,1\n")},
+		"calendar.txt":   {Data: []byte("service_id,monday,start_date,end_date\nWEEKDAY,1,20200101,20201231\n")},
+	}
+	with := func(name string, data string) fstest.MapFS {
+		fsys := fstest.MapFS{}
+		for k, v := range valid {
+			fsys[k] = v
+		}
+		if data == "" {
+			delete(fsys, name)
+		} else {
+			fsys[name] = &fstest.MapFile{Data: []byte(data)}
+		}
+		return fsys
+	}
+
+	tests := []struct {
+		name          string
+		fsys          fstest.MapFS
+		expectedError error
+	}{
+		{
+			name:          "Missing required file",
+			fsys:          with("stop_times.txt", ""),
+			expectedError: errors.New("open stop_times.txt: file does not exist"),
+		},
+		{
+			name:          "Missing calendar",
+			fsys:          with("calendar.txt", ""),
+			expectedError: errors.New("GTFS feed must have calendar.txt or calendar_dates.txt"),
+		},
+		{
+			name:          "Invalid time",
+			fsys:          with("stop_times.txt", "trip_id,departure_time,stop_id\nT42_1,9am,020035811\n"),
+			expectedError: errors.New(`stop_times.txt line 2: invalid departure_time "9am"`),
+		},
+		{
+			name:          "Invalid route type",
+			fsys:          with("routes.txt", "route_id,route_type\nR42,bus\n"),
+			expectedError: errors.New(`routes.txt line 2: invalid route_type "bus"`),
+		},
+		{
+			name:          "Invalid exception type",
+			fsys:          with("calendar_dates.txt", "service_id,date,exception_type\nWEEKDAY,20200508,3\n"),
+			expectedError: errors.New("calendar_dates.txt line 2: invalid exception_type 3"),
+		},
+		{
+			name:          "Invalid timezone",
+			fsys:          with("agency.txt", "agency_id,agency_timezone\nGP,Europe/Bedford\n"),
+			expectedError: errors.New("invalid agency timezone: unknown time zone Europe/Bedford"),
+		},
+		{
+			name:          "Malformed file",
+			fsys:          with("stops.txt", "stop_id,stop_name\n\"020035811,The Green\n"),
+			expectedError: errors.New("cannot read stops.txt: parse error on line 2, column 22: extraneous or missing \" in quoted-field"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := gtfs.LoadFS(test.fsys)
+
+			if err == nil {
+				t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+			}
+			if err.Error() != test.expectedError.Error() {
+				t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+			}
+		})
+	}
+}
+
+func TestDepartures(t *testing.T) {
+	feed, err := gtfs.Load("testdata/feed")
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	type departure struct {
+		TripID string
+		Time   string
+	}
+
+	tests := []struct {
+		name               string
+		stopID             string
+		from               string
+		limit              int
+		expectedDepartures []departure
+	}{
+		{
+			name:   "Weekday departures",
+			stopID: "020035811",
+			from:   "2020-03-30T12:00:00+01:00",
+			limit:  3,
+			expectedDepartures: []departure{
+				{TripID: "T42_2", Time: "2020-03-30T12:09:00+01:00"},
+				{TripID: "TX5_1", Time: "2020-03-30T12:16:00+01:00"},
+				{TripID: "T42_LATE", Time: "2020-03-31T00:30:00+01:00"},
+			},
+		},
+		{
+			name:   "Time after midnight from previous service day",
+			stopID: "020035811",
+			from:   "2020-03-31T00:15:00+01:00",
+			limit:  1,
+			expectedDepartures: []departure{
+				{TripID: "T42_LATE", Time: "2020-03-31T00:30:00+01:00"},
+			},
+		},
+		{
+			name:   "Sunday service on the day the clocks change",
+			stopID: "020035811",
+			from:   "2020-03-28T12:00:00Z",
+			limit:  1,
+			expectedDepartures: []departure{
+				{TripID: "T42_SUN", Time: "2020-03-29T10:00:00+01:00"},
+			},
+		},
+		{
+			name:   "Bank holiday runs Sunday service",
+			stopID: "020035811",
+			from:   "2020-05-08T08:00:00+01:00",
+			limit:  2,
+			expectedDepartures: []departure{
+				{TripID: "T42_SUN", Time: "2020-05-08T10:00:00+01:00"},
+				{TripID: "T42_SUN", Time: "2020-05-10T10:00:00+01:00"},
+			},
+		},
+		{
+			name:   "Stop code and departure time rather than arrival",
+			stopID: "BEDADGMT",
+			from:   "2020-03-30T12:12:00+01:00",
+			limit:  1,
+			expectedDepartures: []departure{
+				{TripID: "TX5_1", Time: "2020-03-30T12:16:00+01:00"},
+			},
+		},
+		{
+			name:   "Trips ending at the stop are skipped",
+			stopID: "0200BDA00101",
+			from:   "2020-03-30T09:30:00+01:00",
+			limit:  2,
+			expectedDepartures: []departure{
+				{TripID: "TX5_1", Time: "2020-03-30T11:30:00+01:00"},
+				{TripID: "TX5_2", Time: "2020-03-30T13:00:00+01:00"},
+			},
+		},
+		{
+			name:   "Stops that don't pick up passengers are skipped",
+			stopID: "020035811",
+			from:   "2020-03-30T12:10:00+01:00",
+			limit:  2,
+			expectedDepartures: []departure{
+				{TripID: "TX5_1", Time: "2020-03-30T12:16:00+01:00"},
+				{TripID: "T42_LATE", Time: "2020-03-31T00:30:00+01:00"},
+			},
+		},
+		{
+			name:   "Stops without times are skipped",
+			stopID: "020035812",
+			from:   "2020-03-30T08:00:00+01:00",
+			limit:  1,
+		},
+		{
+			name:   "After the end of the calendar",
+			stopID: "020035811",
+			from:   "2021-01-01T01:00:00Z",
+			limit:  1,
+		},
+		{
+			name:   "Unknown stop",
+			stopID: "020000000",
+			from:   "2020-03-30T12:00:00+01:00",
+			limit:  1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			from, _ := time.Parse(time.RFC3339, test.from)
+
+			var actual []departure
+			for _, d := range feed.Departures(test.stopID, from, test.limit) {
+				actual = append(actual, departure{TripID: d.Trip.ID, Time: d.Time.Format(time.RFC3339)})
+			}
+
+			if diff := cmp.Diff(test.expectedDepartures, actual); diff != "" {
+				t.Errorf("Departures() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScheduledDeparture(t *testing.T) {
+	feed, err := gtfs.Load("testdata/feed")
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	tests := []struct {
+		name         string
+		tripID       string
+		stopID       string
+		serviceDate  string
+		expectedTime string
+	}{
+		{
+			name:         "Departure on the service date",
+			tripID:       "T42_2",
+			stopID:       "020035811",
+			serviceDate:  "20200330",
+			expectedTime: "2020-03-30T12:09:00+01:00",
+		},
+		{
+			name:         "Time after midnight of the service date",
+			tripID:       "T42_LATE",
+			stopID:       "bedadgmt",
+			serviceDate:  "20200330",
+			expectedTime: "2020-03-31T00:30:00+01:00",
+		},
+		{
+			name:        "Trip doesn't run on the date",
+			tripID:      "T42_2",
+			stopID:      "020035811",
+			serviceDate: "20200329",
+		},
+		{
+			name:        "Trip has no time at the stop",
+			tripID:      "T42_1",
+			stopID:      "020035812",
+			serviceDate: "20200330",
+		},
+		{
+			name:        "Trip doesn't call at the stop",
+			tripID:      "T42_2",
+			stopID:      "020035812",
+			serviceDate: "20200330",
+		},
+		{
+			name:        "Unknown trip",
+			tripID:      "T99",
+			stopID:      "020035811",
+			serviceDate: "20200330",
+		},
+		{
+			name:        "Invalid service date",
+			tripID:      "T42_2",
+			stopID:      "020035811",
+			serviceDate: "2020-03-30",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			departure, ok := feed.ScheduledDeparture(test.tripID, test.stopID, test.serviceDate)
+
+			if test.expectedTime == "" {
+				if ok {
+					t.Fatalf("Expected no departure; got %s", departure.Time.Format(time.RFC3339))
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("Expected departure at %s; got none", test.expectedTime)
+			}
+			if actual := departure.Time.Format(time.RFC3339); actual != test.expectedTime {
+				t.Errorf("Expected departure at %s; got %s", test.expectedTime, actual)
+			}
+		})
+	}
+}
+
+func TestRouteMode(t *testing.T) {
+	tests := []struct {
+		routeType    int
+		expectedMode string
+	}{
+		{routeType: 0, expectedMode: "tram"},
+		{routeType: 1, expectedMode: "metro"},
+		{routeType: 2, expectedMode: "rail"},
+		{routeType: 3, expectedMode: "bus"},
+		{routeType: 4, expectedMode: "ferry"},
+		{routeType: 6, expectedMode: "cableway"},
+		{routeType: 11, expectedMode: "bus"},
+		{routeType: 200, expectedMode: "coach"},
+		{routeType: 700, expectedMode: "bus"},
+		{routeType: 1100, expectedMode: "unknown"},
+	}
+	for _, test := range tests {
+		route := gtfs.Route{Type: test.routeType}
+		if route.Mode() != test.expectedMode {
+			t.Errorf("Expected mode %s for route type %d; got %s", test.expectedMode, test.routeType, route.Mode())
+		}
+	}
+}
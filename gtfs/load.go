@@ -0,0 +1,190 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Files in the GTFS feed that are loaded, calendar.txt and calendar_dates.txt are both optional
+// but at least one of them must be present
+const (
+	agencyFile        = "agency.txt"
+	stopsFile         = "stops.txt"
+	routesFile        = "routes.txt"
+	tripsFile         = "trips.txt"
+	stopTimesFile     = "stop_times.txt"
+	calendarFile      = "calendar.txt"
+	calendarDatesFile = "calendar_dates.txt"
+)
+
+// Load returns the feed from the GTFS zip file, or a directory of the extracted files
+func Load(path string) (*Feed, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return LoadFS(os.DirFS(path))
+	}
+
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open GTFS zip %s", path)
+	}
+	defer r.Close()
+
+	return LoadFS(r)
+}
+
+// LoadFS returns the feed from the GTFS files in the file system
+func LoadFS(fsys fs.FS) (*Feed, error) {
+	feed := &Feed{}
+
+	loaders := []struct {
+		name     string
+		required bool
+		load     func(row) error
+	}{
+		{name: agencyFile, load: feed.addAgency},
+		{name: stopsFile, required: true, load: feed.addStop},
+		{name: routesFile, required: true, load: feed.addRoute},
+		{name: tripsFile, required: true, load: feed.addTrip},
+		{name: stopTimesFile, required: true, load: feed.addStopTime},
+		{name: calendarFile, load: feed.addCalendar},
+		{name: calendarDatesFile, load: feed.addCalendarDate},
+	}
+
+	for _, loader := range loaders {
+		err := readFile(fsys, loader.name, loader.load)
+		if errors.Is(err, fs.ErrNotExist) && !loader.required {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := feed.index(); err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+// row represents a line of a GTFS file, with the values accessed by column name
+type row struct {
+	file    string
+	line    int
+	columns map[string]int
+	record  []string
+}
+
+func (r row) get(column string) string {
+	if i, ok := r.columns[column]; ok && i < len(r.record) {
+		return strings.TrimSpace(r.record[i])
+	}
+
+	return ""
+}
+
+func (r row) int(column string) (int, error) {
+	value := r.get(column)
+	if value == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, r.errorf("invalid %s %q", column, value)
+	}
+
+	return i, nil
+}
+
+func (r row) float(column string) (float64, error) {
+	value := r.get(column)
+	if value == "" {
+		return 0, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, r.errorf("invalid %s %q", column, value)
+	}
+
+	return f, nil
+}
+
+// time returns the number of seconds after the start of the service day of the time in HH:MM:SS format
+func (r row) time(column string) (int, bool, error) {
+	value := r.get(column)
+	if value == "" {
+		return 0, false, nil
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, false, r.errorf("invalid %s %q", column, value)
+	}
+
+	seconds := 0
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, false, r.errorf("invalid %s %q", column, value)
+		}
+		seconds = seconds*60 + n
+	}
+
+	return seconds, true, nil
+}
+
+func (r row) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("%s line %d: %s", r.file, r.line, fmt.Sprintf(format, args...))
+}
+
+// readFile calls load for each row of the GTFS file
+func readFile(fsys fs.FS, name string, load func(row) error) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return errors.Wrapf(err, "cannot read %s header", name)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		// Files are often saved with a byte order mark
+		columns[strings.TrimPrefix(strings.TrimSpace(column), "\ufeff")] = i
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "cannot read %s", name)
+		}
+
+		if err := load(row{file: name, line: line, columns: columns, record: record}); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package gtfs
+
+// Agency represents an agency in agency.txt
+type Agency struct {
+	ID       string
+	Name     string
+	Timezone string
+}
+
+// Stop represents a stop in stops.txt
+type Stop struct {
+	ID        string
+	Code      string
+	Name      string
+	Latitude  float64
+	Longitude float64
+}
+
+// Route represents a route in routes.txt
+type Route struct {
+	ID        string
+	AgencyID  string
+	ShortName string
+	LongName  string
+	Type      int
+}
+
+// Trip represents a trip in trips.txt
+type Trip struct {
+	ID          string
+	RouteID     string
+	ServiceID   string
+	Headsign    string
+	DirectionID string
+}
+
+// StopTime represents the time a trip calls at a stop in stop_times.txt,
+// the times are the number of seconds after the start of the service day and can exceed 24 hours
+type StopTime struct {
+	TripID        string
+	StopID        string
+	StopSequence  int
+	ArrivalTime   int
+	DepartureTime int
+	PickupType    int
+}
+
+// Calendar represents the days of the week a service runs between two dates in calendar.txt
+type Calendar struct {
+	ServiceID string
+	// Weekdays is indexed by time.Weekday
+	Weekdays  [7]bool
+	StartDate string
+	EndDate   string
+}
+
+// Pickup types in stop_times.txt
+const (
+	RegularPickup = 0
+	NoPickup      = 1
+)
+
+// Exception types in calendar_dates.txt
+const (
+	ServiceAdded   = 1
+	ServiceRemoved = 2
+)
+
+// Name returns the name of the route shown to passengers
+func (r Route) Name() string {
+	if r.ShortName != "" {
+		return r.ShortName
+	}
+
+	return r.LongName
+}
+
+// Mode returns the mode of transport of the route, using the same names as SIRI
+func (r Route) Mode() string {
+	switch {
+	case r.Type == 0 || r.Type == 5 || r.Type >= 900 && r.Type < 1000:
+		return "tram"
+	case r.Type == 1 || r.Type == 12 || r.Type >= 400 && r.Type < 500:
+		return "metro"
+	case r.Type == 2 || r.Type >= 100 && r.Type < 200:
+		return "rail"
+	case r.Type == 3 || r.Type == 11 || r.Type >= 700 && r.Type < 900:
+		return "bus"
+	case r.Type >= 200 && r.Type < 300:
+		return "coach"
+	case r.Type == 4 || r.Type >= 1000 && r.Type < 1100 || r.Type >= 1200 && r.Type < 1300:
+		return "ferry"
+	case r.Type == 6 || r.Type == 7 || r.Type >= 1300 && r.Type < 1500:
+		return "cableway"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,84 @@
+package gtfsrt
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Client stores the details required to fetch a GTFS-Realtime feed over HTTP
+type Client struct {
+	URL string
+	// APIKey, if set, is sent as a query parameter as required by feeds such as Bus Open Data
+	APIKey string
+	Client *http.Client
+}
+
+// NewClient returns the client to fetch the GTFS-Realtime feed from the URL
+func NewClient(url string, apiKey string, httpClient *http.Client) API {
+	return &Client{
+		URL:    url,
+		APIKey: apiKey,
+		Client: httpClient,
+	}
+}
+
+// GetFeed fetches and decodes the feed
+func (c *Client) GetFeed() (*FeedMessage, error) {
+	req, err := http.NewRequest(http.MethodGet, c.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", contentType)
+	if c.APIKey != "" {
+		query := req.URL.Query()
+		query.Set("api_key", c.APIKey)
+		req.URL.RawQuery = query.Encode()
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if berr := resp.Body.Close(); berr != nil {
+			err = berr
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Error response from feed: %v", resp)
+		return nil, errors.Errorf("error status from feed: %d", resp.StatusCode)
+	}
+
+	return Decode(body)
+}
+
+// File stores the path of a GTFS-Realtime feed saved to a local file
+type File struct {
+	Path string
+}
+
+// NewFile returns the feed read from the local file, the file is read again each time the feed is requested
+func NewFile(path string) API {
+	return &File{Path: path}
+}
+
+// GetFeed reads and decodes the feed
+func (f *File) GetFeed() (*FeedMessage, error) {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Decode(data)
+}
@@ -0,0 +1,98 @@
+package gtfsrt_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/conradhodge/travel-api-client/gtfsrt"
+)
+
+func TestClientGetFeed(t *testing.T) {
+	data, err := os.ReadFile("testdata/tripupdates.pb")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tests := []struct {
+		name          string
+		apiKey        string
+		statusCode    int
+		response      []byte
+		expectedQuery string
+		expectedError error
+	}{
+		{
+			name:          "Feed returned",
+			apiKey:        "letmein",
+			statusCode:    http.StatusOK,
+			response:      data,
+			expectedQuery: "api_key=letmein",
+		},
+		{
+			name:       "Feed returned without API key",
+			statusCode: http.StatusOK,
+			response:   data,
+		},
+		{
+			name:          "401 response received from feed",
+			statusCode:    http.StatusUnauthorized,
+			response:      []byte("Invalid API key"),
+			expectedError: errors.New("error status from feed: 401"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/gtfsrtdatafeed" {
+					t.Errorf("Expected path: /gtfsrtdatafeed, got: %s", r.URL.Path)
+				}
+				if r.URL.RawQuery != test.expectedQuery {
+					t.Errorf("Expected query: %s, got: %s", test.expectedQuery, r.URL.RawQuery)
+				}
+
+				w.WriteHeader(test.statusCode)
+				_, _ = w.Write(test.response)
+			}))
+			defer server.Close()
+
+			client := gtfsrt.NewClient(server.URL+"/gtfsrtdatafeed", test.apiKey, server.Client())
+
+			feed, err := client.GetFeed()
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if len(feed.Entities) != 7 {
+				t.Fatalf("Expected 7 entities; got %d", len(feed.Entities))
+			}
+		})
+	}
+}
+
+func TestFileGetFeed(t *testing.T) {
+	feed, err := gtfsrt.NewFile("testdata/tripupdates.pb").GetFeed()
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+	if len(feed.Entities) != 7 {
+		t.Fatalf("Expected 7 entities; got %d", len(feed.Entities))
+	}
+
+	_, err = gtfsrt.NewFile("testdata/missing.pb").GetFeed()
+	expectedError := "open testdata/missing.pb: no such file or directory"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf("Expected error '%s'; got '%v'", expectedError, err)
+	}
+}
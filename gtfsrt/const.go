@@ -0,0 +1,4 @@
+package gtfsrt
+
+// Content type for the feed
+const contentType = "application/x-protobuf"
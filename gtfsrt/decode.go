@@ -0,0 +1,248 @@
+package gtfsrt
+
+import (
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// fieldDecoder decodes the value of the field at the start of b and returns the number of bytes consumed,
+// or zero if the field is not decoded and should be skipped
+type fieldDecoder func(num protowire.Number, typ protowire.Type, b []byte) (int, error)
+
+// Decode returns the feed message decoded from the GTFS-Realtime protocol buffer.
+// Field numbers are those of gtfs-realtime.proto, fields and entities other than trip updates are skipped.
+func Decode(data []byte) (*FeedMessage, error) {
+	feed := &FeedMessage{}
+
+	err := decodeMessage(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1: // header
+			return consumeMessage(num, typ, b, func(b []byte) error {
+				return decodeFeedHeader(b, &feed.Header)
+			})
+		case 2: // entity
+			return consumeMessage(num, typ, b, func(b []byte) error {
+				entity := FeedEntity{}
+				if err := decodeFeedEntity(b, &entity); err != nil {
+					return err
+				}
+				feed.Entities = append(feed.Entities, entity)
+				return nil
+			})
+		}
+		return 0, nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode GTFS-Realtime feed")
+	}
+
+	return feed, nil
+}
+
+func decodeFeedHeader(data []byte, header *FeedHeader) error {
+	return decodeMessage(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1: // gtfs_realtime_version
+			return consumeString(num, typ, b, &header.GTFSRealtimeVersion)
+		case 3: // timestamp
+			return consumeVarint(num, typ, b, func(v uint64) { header.Timestamp = v })
+		}
+		return 0, nil
+	})
+}
+
+func decodeFeedEntity(data []byte, entity *FeedEntity) error {
+	return decodeMessage(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1: // id
+			return consumeString(num, typ, b, &entity.ID)
+		case 2: // is_deleted
+			return consumeVarint(num, typ, b, func(v uint64) { entity.IsDeleted = protowire.DecodeBool(v) })
+		case 3: // trip_update
+			return consumeMessage(num, typ, b, func(b []byte) error {
+				entity.TripUpdate = &TripUpdate{}
+				return decodeTripUpdate(b, entity.TripUpdate)
+			})
+		}
+		return 0, nil
+	})
+}
+
+func decodeTripUpdate(data []byte, update *TripUpdate) error {
+	return decodeMessage(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1: // trip
+			return consumeMessage(num, typ, b, func(b []byte) error {
+				return decodeTripDescriptor(b, &update.Trip)
+			})
+		case 2: // stop_time_update
+			return consumeMessage(num, typ, b, func(b []byte) error {
+				stopTimeUpdate := StopTimeUpdate{}
+				if err := decodeStopTimeUpdate(b, &stopTimeUpdate); err != nil {
+					return err
+				}
+				update.StopTimeUpdates = append(update.StopTimeUpdates, stopTimeUpdate)
+				return nil
+			})
+		case 3: // vehicle
+			return consumeMessage(num, typ, b, func(b []byte) error {
+				return decodeVehicleDescriptor(b, &update.Vehicle)
+			})
+		case 4: // timestamp
+			return consumeVarint(num, typ, b, func(v uint64) { update.Timestamp = v })
+		case 5: // delay
+			return consumeVarint(num, typ, b, func(v uint64) { update.Delay = int32Ptr(v) })
+		}
+		return 0, nil
+	})
+}
+
+func decodeTripDescriptor(data []byte, trip *TripDescriptor) error {
+	return decodeMessage(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1: // trip_id
+			return consumeString(num, typ, b, &trip.TripID)
+		case 2: // start_time
+			return consumeString(num, typ, b, &trip.StartTime)
+		case 3: // start_date
+			return consumeString(num, typ, b, &trip.StartDate)
+		case 4: // schedule_relationship
+			return consumeVarint(num, typ, b, func(v uint64) {
+				trip.ScheduleRelationship = TripScheduleRelationship(int32(v))
+			})
+		case 5: // route_id
+			return consumeString(num, typ, b, &trip.RouteID)
+		case 6: // direction_id
+			return consumeVarint(num, typ, b, func(v uint64) {
+				directionID := uint32(v)
+				trip.DirectionID = &directionID
+			})
+		}
+		return 0, nil
+	})
+}
+
+func decodeVehicleDescriptor(data []byte, vehicle *VehicleDescriptor) error {
+	return decodeMessage(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1: // id
+			return consumeString(num, typ, b, &vehicle.ID)
+		case 2: // label
+			return consumeString(num, typ, b, &vehicle.Label)
+		case 3: // license_plate
+			return consumeString(num, typ, b, &vehicle.LicensePlate)
+		}
+		return 0, nil
+	})
+}
+
+func decodeStopTimeUpdate(data []byte, update *StopTimeUpdate) error {
+	return decodeMessage(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1: // stop_sequence
+			return consumeVarint(num, typ, b, func(v uint64) {
+				stopSequence := uint32(v)
+				update.StopSequence = &stopSequence
+			})
+		case 2: // arrival
+			return consumeMessage(num, typ, b, func(b []byte) error {
+				update.Arrival = &StopTimeEvent{}
+				return decodeStopTimeEvent(b, update.Arrival)
+			})
+		case 3: // departure
+			return consumeMessage(num, typ, b, func(b []byte) error {
+				update.Departure = &StopTimeEvent{}
+				return decodeStopTimeEvent(b, update.Departure)
+			})
+		case 4: // stop_id
+			return consumeString(num, typ, b, &update.StopID)
+		case 5: // schedule_relationship
+			return consumeVarint(num, typ, b, func(v uint64) {
+				update.ScheduleRelationship = StopScheduleRelationship(int32(v))
+			})
+		}
+		return 0, nil
+	})
+}
+
+func decodeStopTimeEvent(data []byte, event *StopTimeEvent) error {
+	return decodeMessage(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1: // delay
+			return consumeVarint(num, typ, b, func(v uint64) { event.Delay = int32Ptr(v) })
+		case 2: // time
+			return consumeVarint(num, typ, b, func(v uint64) {
+				t := int64(v)
+				event.Time = &t
+			})
+		case 3: // uncertainty
+			return consumeVarint(num, typ, b, func(v uint64) { event.Uncertainty = int32Ptr(v) })
+		}
+		return 0, nil
+	})
+}
+
+// decodeMessage calls the decoder for each field of the message
+func decodeMessage(data []byte, decode fieldDecoder) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		n, err := decode(num, typ, data)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+		}
+		data = data[n:]
+	}
+
+	return nil
+}
+
+func consumeMessage(num protowire.Number, typ protowire.Type, b []byte, decode func([]byte) error) (int, error) {
+	if typ != protowire.BytesType {
+		return 0, errors.Errorf("unexpected wire type %d for field %d", typ, num)
+	}
+
+	v, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+
+	return n, decode(v)
+}
+
+func consumeString(num protowire.Number, typ protowire.Type, b []byte, s *string) (int, error) {
+	return consumeMessage(num, typ, b, func(v []byte) error {
+		*s = string(v)
+		return nil
+	})
+}
+
+func consumeVarint(num protowire.Number, typ protowire.Type, b []byte, set func(uint64)) (int, error) {
+	if typ != protowire.VarintType {
+		return 0, errors.Errorf("unexpected wire type %d for field %d", typ, num)
+	}
+
+	v, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	set(v)
+
+	return n, nil
+}
+
+// int32Ptr returns a pointer to the int32 encoded in the varint, negative values are sign extended
+func int32Ptr(v uint64) *int32 {
+	i := int32(v)
+	return &i
+}
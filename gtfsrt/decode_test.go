@@ -0,0 +1,179 @@
+package gtfsrt_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/conradhodge/travel-api-client/gtfsrt"
+	"github.com/google/go-cmp/cmp"
+)
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func uint32Ptr(v uint32) *uint32 {
+	return &v
+}
+
+func TestDecode(t *testing.T) {
+	data, err := os.ReadFile("testdata/tripupdates.pb")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	feed, err := gtfsrt.Decode(data)
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	expectedFeed := &gtfsrt.FeedMessage{
+		Header: gtfsrt.FeedHeader{
+			GTFSRealtimeVersion: "2.0",
+			Timestamp:           1585566000,
+		},
+		Entities: []gtfsrt.FeedEntity{
+			{
+				ID: "1",
+				TripUpdate: &gtfsrt.TripUpdate{
+					Trip: gtfsrt.TripDescriptor{
+						TripID:      "VJ_42_1",
+						RouteID:     "42",
+						DirectionID: uint32Ptr(0),
+						StartDate:   "20200330",
+					},
+					Vehicle: gtfsrt.VehicleDescriptor{
+						ID:    "YJ15ABC",
+						Label: "1234",
+					},
+					StopTimeUpdates: []gtfsrt.StopTimeUpdate{
+						{
+							StopSequence: uint32Ptr(1),
+							StopID:       "020035811",
+							Departure:    &gtfsrt.StopTimeEvent{Delay: int32Ptr(180), Time: int64Ptr(1585566720)},
+						},
+						{
+							StopSequence: uint32Ptr(2),
+							StopID:       "020035812",
+							Arrival:      &gtfsrt.StopTimeEvent{Delay: int32Ptr(120), Time: int64Ptr(1585567200)},
+						},
+					},
+					Timestamp: 1585565970,
+					Delay:     int32Ptr(180),
+				},
+			},
+			{
+				ID: "2",
+				TripUpdate: &gtfsrt.TripUpdate{
+					Trip: gtfsrt.TripDescriptor{TripID: "VJ_42_2", RouteID: "42"},
+					StopTimeUpdates: []gtfsrt.StopTimeUpdate{
+						{StopID: "020035811", Departure: &gtfsrt.StopTimeEvent{Time: int64Ptr(1585566300)}},
+					},
+					Delay: int32Ptr(-60),
+				},
+			},
+			{
+				ID: "3",
+				TripUpdate: &gtfsrt.TripUpdate{
+					Trip: gtfsrt.TripDescriptor{
+						TripID:               "VJ_X5_1",
+						RouteID:              "X5",
+						ScheduleRelationship: gtfsrt.TripCanceled,
+					},
+					StopTimeUpdates: []gtfsrt.StopTimeUpdate{
+						{StopID: "020035811", Departure: &gtfsrt.StopTimeEvent{Time: int64Ptr(1585566060)}},
+					},
+				},
+			},
+			{
+				ID: "4",
+				TripUpdate: &gtfsrt.TripUpdate{
+					Trip: gtfsrt.TripDescriptor{TripID: "VJ_X5_2", RouteID: "X5"},
+					StopTimeUpdates: []gtfsrt.StopTimeUpdate{
+						{
+							StopID:               "020035811",
+							Departure:            &gtfsrt.StopTimeEvent{Time: int64Ptr(1585566120)},
+							ScheduleRelationship: gtfsrt.StopSkipped,
+						},
+					},
+				},
+			},
+			{
+				ID: "5",
+			},
+			{
+				ID: "6",
+				TripUpdate: &gtfsrt.TripUpdate{
+					Trip: gtfsrt.TripDescriptor{TripID: "VJ_99_1", RouteID: "99"},
+					StopTimeUpdates: []gtfsrt.StopTimeUpdate{
+						{StopSequence: uint32Ptr(4), Departure: &gtfsrt.StopTimeEvent{Time: int64Ptr(1585566180)}},
+						{StopID: "020035811", Departure: &gtfsrt.StopTimeEvent{Delay: int32Ptr(60)}},
+					},
+				},
+			},
+			{
+				ID:        "7",
+				IsDeleted: true,
+				TripUpdate: &gtfsrt.TripUpdate{
+					Trip: gtfsrt.TripDescriptor{TripID: "VJ_99_2", RouteID: "99"},
+					StopTimeUpdates: []gtfsrt.StopTimeUpdate{
+						{StopID: "020035811", Departure: &gtfsrt.StopTimeEvent{Time: int64Ptr(1585566030)}},
+					},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expectedFeed, feed); diff != "" {
+		t.Errorf("Decode() (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	truncated, err := os.ReadFile("testdata/truncated.pb")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tests := []struct {
+		name          string
+		data          []byte
+		expectedError error
+	}{
+		{
+			name:          "Truncated feed",
+			data:          truncated,
+			expectedError: errors.New("cannot decode GTFS-Realtime feed: unexpected EOF"),
+		},
+		{
+			name:          "Wrong wire type",
+			data:          []byte{0x08, 0x01},
+			expectedError: errors.New("cannot decode GTFS-Realtime feed: unexpected wire type 0 for field 1"),
+		},
+		{
+			name:          "Invalid tag",
+			data:          []byte{0xff},
+			expectedError: errors.New("cannot decode GTFS-Realtime feed: unexpected EOF"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			feed, err := gtfsrt.Decode(test.data)
+
+			if err == nil {
+				t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+			}
+			if err.Error() != test.expectedError.Error() {
+				t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+			}
+			if feed != nil {
+				t.Fatalf("Expected no feed; got %v", feed)
+			}
+		})
+	}
+}
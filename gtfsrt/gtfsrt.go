@@ -0,0 +1,6 @@
+package gtfsrt
+
+// API represents the interface to a GTFS-Realtime TripUpdates feed
+type API interface {
+	GetFeed() (*FeedMessage, error)
+}
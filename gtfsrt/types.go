@@ -0,0 +1,93 @@
+package gtfsrt
+
+// FeedMessage represents the GTFS-Realtime feed, only the trip updates are decoded
+type FeedMessage struct {
+	Header   FeedHeader
+	Entities []FeedEntity
+}
+
+// FeedHeader represents the metadata about the feed
+type FeedHeader struct {
+	GTFSRealtimeVersion string
+	// Timestamp is when the feed was created, in seconds since the Unix epoch
+	Timestamp uint64
+}
+
+// FeedEntity represents an entity in the feed, TripUpdate is not set for other types of entity
+type FeedEntity struct {
+	ID         string
+	IsDeleted  bool
+	TripUpdate *TripUpdate
+}
+
+// TripUpdate represents the real-time progress of a vehicle along a trip
+type TripUpdate struct {
+	Trip            TripDescriptor
+	Vehicle         VehicleDescriptor
+	StopTimeUpdates []StopTimeUpdate
+	// Timestamp is when the progress was measured, in seconds since the Unix epoch
+	Timestamp uint64
+	// Delay is the current delay of the trip in seconds, if set
+	Delay *int32
+}
+
+// TripDescriptor identifies the trip that a trip update is for
+type TripDescriptor struct {
+	TripID               string
+	RouteID              string
+	DirectionID          *uint32
+	StartTime            string
+	StartDate            string
+	ScheduleRelationship TripScheduleRelationship
+}
+
+// VehicleDescriptor identifies the vehicle serving the trip
+type VehicleDescriptor struct {
+	ID           string
+	Label        string
+	LicensePlate string
+}
+
+// StopTimeUpdate represents the real-time update for the arrival and departure at a stop of the trip
+type StopTimeUpdate struct {
+	StopSequence         *uint32
+	StopID               string
+	Arrival              *StopTimeEvent
+	Departure            *StopTimeEvent
+	ScheduleRelationship StopScheduleRelationship
+}
+
+// StopTimeEvent represents the timing of an arrival or departure
+type StopTimeEvent struct {
+	// Delay is the number of seconds after the scheduled time, if set
+	Delay *int32
+	// Time is the predicted time in seconds since the Unix epoch, if set
+	Time *int64
+	// Uncertainty of the prediction in seconds, if set
+	Uncertainty *int32
+}
+
+// TripScheduleRelationship represents the relationship between a trip and the static timetable
+type TripScheduleRelationship int32
+
+// Trip schedule relationships
+const (
+	TripScheduled   TripScheduleRelationship = 0
+	TripAdded       TripScheduleRelationship = 1
+	TripUnscheduled TripScheduleRelationship = 2
+	TripCanceled    TripScheduleRelationship = 3
+	TripReplacement TripScheduleRelationship = 5
+	TripDuplicated  TripScheduleRelationship = 6
+	TripDeleted     TripScheduleRelationship = 7
+)
+
+// StopScheduleRelationship represents the relationship between a stop time and the static timetable
+type StopScheduleRelationship int32
+
+// Stop time schedule relationships
+const (
+	StopScheduled   StopScheduleRelationship = 0
+	StopSkipped     StopScheduleRelationship = 1
+	StopNoData      StopScheduleRelationship = 2
+	StopUnscheduled StopScheduleRelationship = 3
+)
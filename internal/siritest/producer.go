@@ -0,0 +1,145 @@
+// Package siritest provides a fake Siri producer for testing clients of the Traveline API
+package siritest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var (
+	messageIdentifierPattern = regexp.MustCompile(`<MessageIdentifier>([^<]*)</MessageIdentifier>`)
+	requestMessageRefPattern = regexp.MustCompile(`(<RequestMessageRef>)[^<]*(</RequestMessageRef>)`)
+)
+
+// scriptedResponse is a response scripted for the fake producer
+type scriptedResponse struct {
+	statusCode int
+	body       string
+}
+
+// Producer is a local server standing in for a Siri producer. It responds to each request by the
+// element of the request, e.g. StopMonitoringRequest or CheckStatusRequest, using the responses scripted
+// for it, optionally checks the request is authenticated and records the requests it receives.
+// As a producer does, the RequestMessageRef of a delivery is the MessageIdentifier of the request.
+type Producer struct {
+	*httptest.Server
+	t *testing.T
+
+	mu       sync.Mutex
+	username string
+	password string
+	once     map[string][]scriptedResponse
+	always   map[string]scriptedResponse
+	requests []string
+}
+
+// NewProducer returns the fake producer, which has no responses until they are scripted
+func NewProducer(t *testing.T) *Producer {
+	p := &Producer{
+		t:      t,
+		once:   make(map[string][]scriptedResponse),
+		always: make(map[string]scriptedResponse),
+	}
+	p.Server = httptest.NewServer(http.HandlerFunc(p.serveHTTP))
+
+	return p
+}
+
+// WithAuth makes the producer check that requests are authenticated with the username and password
+func (p *Producer) WithAuth(username string, password string) *Producer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.username, p.password = username, password
+
+	return p
+}
+
+// Respond makes the producer respond to every request with the element with the response
+func (p *Producer) Respond(request string, statusCode int, body string) *Producer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.always[request] = scriptedResponse{statusCode: statusCode, body: body}
+
+	return p
+}
+
+// RespondOnce makes the producer respond to the next request with the element with the response,
+// before any response scripted with Respond
+func (p *Producer) RespondOnce(request string, statusCode int, body string) *Producer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.once[request] = append(p.once[request], scriptedResponse{statusCode: statusCode, body: body})
+
+	return p
+}
+
+// Received returns the element of each request the producer has received
+func (p *Producer) Received() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]string(nil), p.requests...)
+}
+
+func (p *Producer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if r.Method != http.MethodPost {
+		p.t.Errorf("Expected method: %s, got: %s", http.MethodPost, r.Method)
+	}
+	if p.username != "" {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != p.username || password != p.password {
+			p.t.Errorf("Expected basic auth for %s", p.username)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	request := requestElement(body)
+	p.requests = append(p.requests, request)
+
+	response, ok := p.always[request]
+	if queue := p.once[request]; len(queue) > 0 {
+		response, ok = queue[0], true
+		p.once[request] = queue[1:]
+	}
+	if !ok {
+		p.t.Errorf("Unexpected %s request: %s", request, body)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	responseBody := response.body
+	if messageIdentifier := messageIdentifierPattern.FindSubmatch(body); messageIdentifier != nil {
+		responseBody = requestMessageRefPattern.ReplaceAllString(responseBody, "${1}"+string(messageIdentifier[1])+"${2}")
+	}
+
+	w.WriteHeader(response.statusCode)
+	fmt.Fprint(w, responseBody)
+}
+
+// requestElement returns the element of the Siri request, the functional request of a Service Request
+func requestElement(body []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		if start, ok := token.(xml.StartElement); ok &&
+			strings.HasSuffix(start.Name.Local, "Request") && start.Name.Local != "ServiceRequest" {
+			return start.Name.Local
+		}
+	}
+}
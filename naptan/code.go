@@ -0,0 +1,96 @@
+package naptan
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CodeType represents the kind of code used to identify a stop
+type CodeType int
+
+const (
+	// UnknownCode is a code that is neither an ATCO nor a NaPTAN code
+	UnknownCode CodeType = iota
+	// ATCOCode is the code used by operators and the NextBuses API, e.g. 020035811
+	ATCOCode
+	// NaptanCode is the shorter code displayed at stops for SMS services, e.g. bstgwpa
+	NaptanCode
+)
+
+func (t CodeType) String() string {
+	switch t {
+	case ATCOCode:
+		return "ATCO"
+	case NaptanCode:
+		return "NaPTAN"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	atcoCodePattern   = regexp.MustCompile(`^[0-9]{3}[0-9A-Z]{1,9}$`)
+	naptanCodePattern = regexp.MustCompile(`^[a-z0-9]{7,8}$`)
+)
+
+// NormaliseCode returns the code in the form used in the NaPTAN dataset and the type of the code.
+// Whitespace is removed, ATCO codes are upper case and NaPTAN codes are lower case.
+// An all numeric code is treated as an ATCO code.
+func NormaliseCode(code string) (string, CodeType) {
+	code = strings.Join(strings.Fields(code), "")
+
+	if upper := strings.ToUpper(code); atcoCodePattern.MatchString(upper) {
+		return upper, ATCOCode
+	}
+	if lower := strings.ToLower(code); naptanCodePattern.MatchString(lower) {
+		return lower, NaptanCode
+	}
+
+	return code, UnknownCode
+}
+
+// ValidateCode returns the normalised code, or an InvalidStopCodeError explaining why the code
+// is neither an ATCO nor a NaPTAN code
+func ValidateCode(code string) (string, error) {
+	normalised, codeType := NormaliseCode(code)
+	if codeType != UnknownCode {
+		return normalised, nil
+	}
+
+	return "", &InvalidStopCodeError{
+		Code:   code,
+		Reason: invalidCodeReason(normalised),
+	}
+}
+
+// invalidCodeReason returns why the code, with whitespace removed, is not valid
+func invalidCodeReason(code string) string {
+	if code == "" {
+		return "code is empty"
+	}
+
+	for _, r := range code {
+		if !isDigit(r) && !isLetter(r) {
+			return fmt.Sprintf("invalid character %q", r)
+		}
+	}
+
+	if !isDigit(rune(code[0])) {
+		return "NaPTAN code must be 7 or 8 characters"
+	}
+
+	if len(code) < 3 || !isDigit(rune(code[1])) || !isDigit(rune(code[2])) {
+		return "ATCO code must start with a three digit area prefix"
+	}
+
+	return "ATCO code must be between 4 and 12 characters"
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
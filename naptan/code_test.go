@@ -0,0 +1,155 @@
+package naptan_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/conradhodge/travel-api-client/naptan"
+)
+
+func TestNormaliseCode(t *testing.T) {
+	tests := []struct {
+		code         string
+		expectedCode string
+		expectedType naptan.CodeType
+	}{
+		{
+			code:         "020035811",
+			expectedCode: "020035811",
+			expectedType: naptan.ATCOCode,
+		},
+		{
+			code:         " 0100brp90310 ",
+			expectedCode: "0100BRP90310",
+			expectedType: naptan.ATCOCode,
+		},
+		{
+			code:         "BSTGWPA",
+			expectedCode: "bstgwpa",
+			expectedType: naptan.NaptanCode,
+		},
+		{
+			code:         "bst gwpa",
+			expectedCode: "bstgwpa",
+			expectedType: naptan.NaptanCode,
+		},
+		{
+			code:         "bedadgmt",
+			expectedCode: "bedadgmt",
+			expectedType: naptan.NaptanCode,
+		},
+		{
+			code:         "bst",
+			expectedCode: "bst",
+			expectedType: naptan.UnknownCode,
+		},
+		{
+			code:         "0200BDA0010123",
+			expectedCode: "0200BDA0010123",
+			expectedType: naptan.UnknownCode,
+		},
+		{
+			code:         "020-035811",
+			expectedCode: "020-035811",
+			expectedType: naptan.UnknownCode,
+		},
+		{
+			code:         "",
+			expectedCode: "",
+			expectedType: naptan.UnknownCode,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			code, codeType := naptan.NormaliseCode(test.code)
+
+			if code != test.expectedCode {
+				t.Fatalf("Expected code '%s'; got '%s'", test.expectedCode, code)
+			}
+			if codeType != test.expectedType {
+				t.Fatalf("Expected code type '%s'; got '%s'", test.expectedType, codeType)
+			}
+		})
+	}
+}
+
+func TestValidateCode(t *testing.T) {
+	tests := []struct {
+		code          string
+		expectedCode  string
+		expectedError error
+	}{
+		{
+			code:         "020035811",
+			expectedCode: "020035811",
+		},
+		{
+			code:         "bst gwpa",
+			expectedCode: "bstgwpa",
+		},
+		{
+			code:          "",
+			expectedError: errors.New(`Invalid stop code "": code is empty`),
+		},
+		{
+			code:          "  ",
+			expectedError: errors.New(`Invalid stop code "  ": code is empty`),
+		},
+		{
+			code:          "020-035811",
+			expectedError: errors.New(`Invalid stop code "020-035811": invalid character '-'`),
+		},
+		{
+			code:          "02003581é",
+			expectedError: errors.New(`Invalid stop code "02003581é": invalid character 'é'`),
+		},
+		{
+			code:          "bstgw",
+			expectedError: errors.New(`Invalid stop code "bstgw": NaPTAN code must be 7 or 8 characters`),
+		},
+		{
+			code:          "bstgwpabc",
+			expectedError: errors.New(`Invalid stop code "bstgwpabc": NaPTAN code must be 7 or 8 characters`),
+		},
+		{
+			code:          "02A035811",
+			expectedError: errors.New(`Invalid stop code "02A035811": ATCO code must start with a three digit area prefix`),
+		},
+		{
+			code:          "02",
+			expectedError: errors.New(`Invalid stop code "02": ATCO code must start with a three digit area prefix`),
+		},
+		{
+			code:          "020",
+			expectedError: errors.New(`Invalid stop code "020": ATCO code must be between 4 and 12 characters`),
+		},
+		{
+			code:          "0200BDA0010123",
+			expectedError: errors.New(`Invalid stop code "0200BDA0010123": ATCO code must be between 4 and 12 characters`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			code, err := naptan.ValidateCode(test.code)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if code != test.expectedCode {
+				t.Fatalf("Expected code '%s'; got '%s'", test.expectedCode, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package naptan
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Columns of the NaPTAN Stops.csv file that are loaded
+const (
+	columnATCOCode               = "ATCOCode"
+	columnNaptanCode             = "NaptanCode"
+	columnCommonName             = "CommonName"
+	columnIndicator              = "Indicator"
+	columnStreet                 = "Street"
+	columnLandmark               = "Landmark"
+	columnLocalityCode           = "NptgLocalityCode"
+	columnLocalityName           = "LocalityName"
+	columnParentLocalityName     = "ParentLocalityName"
+	columnTown                   = "Town"
+	columnLongitude              = "Longitude"
+	columnLatitude               = "Latitude"
+	columnStopType               = "StopType"
+	columnAdministrativeAreaCode = "AdministrativeAreaCode"
+	columnStatus                 = "Status"
+)
+
+// LoadCSVFile returns the dataset from the NaPTAN Stops.csv file
+func LoadCSVFile(path string) (*Dataset, error) {
+	f, err := os.Open(path) // #nosec G304 -- the path is chosen by the caller
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadCSV(f)
+}
+
+// LoadCSV returns the dataset from the NaPTAN stops in CSV format
+func LoadCSV(r io.Reader) (*Dataset, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read NaPTAN CSV header")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// The published file starts with a byte order mark
+		columns[strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")] = i
+	}
+	for _, required := range []string{columnATCOCode, columnCommonName} {
+		if _, ok := columns[required]; !ok {
+			return nil, errors.Errorf("NaPTAN CSV is missing the %s column", required)
+		}
+	}
+
+	var stops []Stop
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read NaPTAN CSV")
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		stop := Stop{
+			ATCOCode:               value(columnATCOCode),
+			NaptanCode:             value(columnNaptanCode),
+			CommonName:             value(columnCommonName),
+			Indicator:              value(columnIndicator),
+			Street:                 value(columnStreet),
+			Landmark:               value(columnLandmark),
+			LocalityCode:           value(columnLocalityCode),
+			LocalityName:           value(columnLocalityName),
+			ParentLocalityName:     value(columnParentLocalityName),
+			Town:                   value(columnTown),
+			StopType:               value(columnStopType),
+			AdministrativeAreaCode: value(columnAdministrativeAreaCode),
+			Status:                 value(columnStatus),
+		}
+
+		if stop.Latitude, err = parseCoordinate(value(columnLatitude)); err != nil {
+			return nil, errors.Wrapf(err, "invalid latitude for stop %s", stop.ATCOCode)
+		}
+		if stop.Longitude, err = parseCoordinate(value(columnLongitude)); err != nil {
+			return nil, errors.Wrapf(err, "invalid longitude for stop %s", stop.ATCOCode)
+		}
+
+		stops = append(stops, stop)
+	}
+
+	return NewDataset(stops), nil
+}
+
+// parseCoordinate returns the latitude or longitude, or zero if not set
+func parseCoordinate(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
@@ -0,0 +1,129 @@
+package naptan
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Dataset stores the stops loaded from the NaPTAN dataset, indexed for lookup
+type Dataset struct {
+	stops      []Stop
+	byATCO     map[string]int
+	byNaptan   map[string]int
+	byLocality map[string][]int
+	words      [][]string
+	cells      map[cell][]int
+}
+
+// NewDataset returns a dataset of the stops, indexed by code, locality and location
+func NewDataset(stops []Stop) *Dataset {
+	d := &Dataset{
+		stops:      stops,
+		byATCO:     make(map[string]int, len(stops)),
+		byNaptan:   make(map[string]int, len(stops)),
+		byLocality: make(map[string][]int),
+		words:      make([][]string, len(stops)),
+	}
+
+	for i := range d.stops {
+		stop := &d.stops[i]
+		stop.ATCOCode, _ = NormaliseCode(stop.ATCOCode)
+		stop.NaptanCode, _ = NormaliseCode(stop.NaptanCode)
+
+		if stop.ATCOCode != "" {
+			d.byATCO[stop.ATCOCode] = i
+		}
+		if stop.NaptanCode != "" {
+			d.byNaptan[stop.NaptanCode] = i
+		}
+		for _, key := range []string{stop.LocalityCode, stop.LocalityName} {
+			if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+				d.byLocality[key] = append(d.byLocality[key], i)
+			}
+		}
+		d.words[i] = tokenise(strings.Join([]string{stop.CommonName, stop.Indicator, stop.Street, stop.LocalityName}, " "))
+	}
+	d.indexLocations()
+
+	return d
+}
+
+// Load returns the dataset from the NaPTAN CSV or XML file, depending on the file extension
+func Load(path string) (*Dataset, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return LoadCSVFile(path)
+	case ".xml":
+		return LoadXMLFile(path)
+	default:
+		return nil, errors.Errorf("unsupported NaPTAN file: %s", path)
+	}
+}
+
+// Len returns the number of stops in the dataset
+func (d *Dataset) Len() int {
+	return len(d.stops)
+}
+
+// Stops returns all the stops in the dataset
+func (d *Dataset) Stops() []Stop {
+	return d.stops
+}
+
+// Lookup returns the stop that the ATCO or NaPTAN code represents
+func (d *Dataset) Lookup(code string) (*Stop, bool) {
+	normalised, codeType := NormaliseCode(code)
+
+	// All numeric codes may be either type, so check both indexes
+	if codeType == ATCOCode {
+		if i, ok := d.byATCO[normalised]; ok {
+			return &d.stops[i], true
+		}
+	}
+	if i, ok := d.byNaptan[strings.ToLower(normalised)]; ok {
+		return &d.stops[i], true
+	}
+
+	return nil, false
+}
+
+// Locality returns the stops in the locality, which can be either the NPTG locality code or name
+func (d *Dataset) Locality(locality string) []*Stop {
+	indexes := d.byLocality[strings.ToLower(strings.TrimSpace(locality))]
+
+	stops := make([]*Stop, 0, len(indexes))
+	for _, i := range indexes {
+		stops = append(stops, &d.stops[i])
+	}
+
+	return stops
+}
+
+// Search returns up to limit stops whose name, street or locality match the query, best match first.
+// Abbreviations such as "Rd" and small typos are tolerated.
+func (d *Dataset) Search(query string, limit int) []Match {
+	queryWords := tokenise(query)
+
+	var matches []Match
+	for i := range d.stops {
+		if s := score(queryWords, d.words[i]); s > 0 {
+			matches = append(matches, Match{Stop: &d.stops[i], Score: s})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Stop.Name() < matches[j].Stop.Name()
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
@@ -0,0 +1,308 @@
+package naptan_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/conradhodge/travel-api-client/naptan"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		expectedLen   int
+		expectedError error
+	}{
+		{
+			name:        "CSV file",
+			path:        "testdata/Stops.csv",
+			expectedLen: 5,
+		},
+		{
+			name:        "XML file",
+			path:        "testdata/Stops.xml",
+			expectedLen: 2,
+		},
+		{
+			name:          "Unsupported file",
+			path:          "testdata/Stops.json",
+			expectedError: errors.New("unsupported NaPTAN file: testdata/Stops.json"),
+		},
+		{
+			name:          "Missing file",
+			path:          "testdata/Missing.csv",
+			expectedError: errors.New("open testdata/Missing.csv: no such file or directory"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dataset, err := naptan.Load(test.path)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			if dataset.Len() != test.expectedLen {
+				t.Fatalf("Expected %d stops; got %d", test.expectedLen, dataset.Len())
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	dataset, err := naptan.Load("testdata/Stops.csv")
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	tests := []struct {
+		code         string
+		expectedStop *naptan.Stop
+	}{
+		{
+			code: "020035811",
+			expectedStop: &naptan.Stop{
+				ATCOCode:               "020035811",
+				NaptanCode:             "bedadgmt",
+				CommonName:             "The Green",
+				Indicator:              "opp",
+				Street:                 "Leighton Road",
+				Landmark:               "Village Hall",
+				LocalityCode:           "E0044123",
+				LocalityName:           "Toddington",
+				Latitude:               51.94911,
+				Longitude:              -0.53385,
+				StopType:               "BCT",
+				AdministrativeAreaCode: "020",
+				Status:                 "active",
+			},
+		},
+		{
+			code: "BED ADG MT",
+			expectedStop: &naptan.Stop{
+				ATCOCode:               "020035811",
+				NaptanCode:             "bedadgmt",
+				CommonName:             "The Green",
+				Indicator:              "opp",
+				Street:                 "Leighton Road",
+				Landmark:               "Village Hall",
+				LocalityCode:           "E0044123",
+				LocalityName:           "Toddington",
+				Latitude:               51.94911,
+				Longitude:              -0.53385,
+				StopType:               "BCT",
+				AdministrativeAreaCode: "020",
+				Status:                 "active",
+			},
+		},
+		{
+			code: "0200bda00102",
+			expectedStop: &naptan.Stop{
+				ATCOCode:               "0200BDA00102",
+				NaptanCode:             "bedagjtw",
+				CommonName:             "Bedford Bus Station",
+				Indicator:              "Stand B",
+				Street:                 "Greyfriars",
+				LocalityCode:           "E0035501",
+				LocalityName:           "Bedford",
+				Town:                   "Bedford",
+				Latitude:               52.13761,
+				Longitude:              -0.47074,
+				StopType:               "BCS",
+				AdministrativeAreaCode: "020",
+				Status:                 "active",
+			},
+		},
+		{
+			code: "020000000",
+		},
+		{
+			code: "nonsense",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			stop, ok := dataset.Lookup(test.code)
+
+			if ok != (test.expectedStop != nil) {
+				t.Fatalf("Expected found %v; got %v", test.expectedStop != nil, ok)
+			}
+			if diff := cmp.Diff(test.expectedStop, stop); diff != "" {
+				t.Errorf("Lookup() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLocality(t *testing.T) {
+	dataset, err := naptan.Load("testdata/Stops.csv")
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	tests := []struct {
+		locality      string
+		expectedCodes []string
+	}{
+		{
+			locality:      "Toddington",
+			expectedCodes: []string{"020035811", "020035812"},
+		},
+		{
+			locality:      "e0035501",
+			expectedCodes: []string{"0200BDA00101", "0200BDA00102", "020035999"},
+		},
+		{
+			locality:      "Xanadu",
+			expectedCodes: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.locality, func(t *testing.T) {
+			codes := []string{}
+			for _, stop := range dataset.Locality(test.locality) {
+				codes = append(codes, stop.ATCOCode)
+			}
+
+			if diff := cmp.Diff(test.expectedCodes, codes); diff != "" {
+				t.Errorf("Locality() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	dataset, err := naptan.Load("testdata/Stops.csv")
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	tests := []struct {
+		query         string
+		limit         int
+		expectedNames []string
+	}{
+		{
+			query:         "Bedford Bus Station",
+			expectedNames: []string{"Bedford Bus Station (Stand A)", "Bedford Bus Station (Stand B)"},
+		},
+		{
+			query:         "bedford bus stn",
+			limit:         1,
+			expectedNames: []string{"Bedford Bus Station (Stand A)"},
+		},
+		{
+			query:         "Toddingtn Green",
+			expectedNames: []string{"The Green (adj)", "The Green (opp)"},
+		},
+		{
+			query:         "high st",
+			expectedNames: []string{"High St (Stop A)"},
+		},
+		{
+			query:         "Bedf",
+			expectedNames: []string{"Bedford Bus Station (Stand A)", "Bedford Bus Station (Stand B)", "High St (Stop A)"},
+		},
+		{
+			query:         "Xanadu",
+			expectedNames: []string{},
+		},
+		{
+			query:         "",
+			expectedNames: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			names := []string{}
+			for _, match := range dataset.Search(test.query, test.limit) {
+				names = append(names, match.Stop.Name())
+			}
+
+			if diff := cmp.Diff(test.expectedNames, names); diff != "" {
+				t.Errorf("Search() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadCSVMissingColumn(t *testing.T) {
+	_, err := naptan.LoadCSV(strings.NewReader("ATCOCode,Street\n020035811,Leighton Road\n"))
+
+	expectedError := "NaPTAN CSV is missing the CommonName column"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf("Expected error '%s'; got '%v'", expectedError, err)
+	}
+}
+
+func TestLoadCSVInvalidCoordinate(t *testing.T) {
+	_, err := naptan.LoadCSV(strings.NewReader("ATCOCode,CommonName,Latitude\n020035811,The Green,north\n"))
+
+	expectedError := `invalid latitude for stop 020035811: strconv.ParseFloat: parsing "north": invalid syntax`
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf("Expected error '%s'; got '%v'", expectedError, err)
+	}
+}
+
+func TestLoadXML(t *testing.T) {
+	dataset, err := naptan.LoadXMLFile("testdata/Stops.xml")
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	expectedStops := []naptan.Stop{
+		{
+			ATCOCode:               "0100BRP90310",
+			NaptanCode:             "bstgwpa",
+			CommonName:             "Temple Meads Station",
+			Indicator:              "T3",
+			Street:                 "Station Approach",
+			LocalityCode:           "E0035604",
+			Town:                   "Bristol",
+			Latitude:               51.44927,
+			Longitude:              -2.58569,
+			StopType:               "BCT",
+			AdministrativeAreaCode: "009",
+			Status:                 "active",
+		},
+		{
+			ATCOCode:               "0100BRP90311",
+			CommonName:             "Redcliffe Hill",
+			LocalityCode:           "E0035604",
+			Latitude:               51.4465,
+			Longitude:              -2.5912,
+			StopType:               "BCT",
+			AdministrativeAreaCode: "009",
+			Status:                 "inactive",
+		},
+	}
+
+	if diff := cmp.Diff(expectedStops, dataset.Stops()); diff != "" {
+		t.Errorf("LoadXMLFile() (-want +got):\n%s", diff)
+	}
+
+	if dataset.Stops()[1].Active() {
+		t.Fatal("Expected inactive stop")
+	}
+}
+
+func TestLoadXMLInvalid(t *testing.T) {
+	_, err := naptan.LoadXML(strings.NewReader("<NaPTAN><StopPoints><StopPoint></StopPoints></NaPTAN>"))
+
+	expectedError := "cannot read NaPTAN XML stop point: XML syntax error on line 1: element <StopPoint> closed by </StopPoints>"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf("Expected error '%s'; got '%v'", expectedError, err)
+	}
+}
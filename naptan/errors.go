@@ -0,0 +1,13 @@
+package naptan
+
+import "fmt"
+
+// InvalidStopCodeError indicates that the code does not represent a stop
+type InvalidStopCodeError struct {
+	Code   string
+	Reason string
+}
+
+func (e InvalidStopCodeError) Error() string {
+	return fmt.Sprintf("Invalid stop code \"%s\": %s", e.Code, e.Reason)
+}
@@ -0,0 +1,20 @@
+package naptan_test
+
+import (
+	"testing"
+
+	"github.com/conradhodge/travel-api-client/naptan"
+)
+
+func TestInvalidStopCodeError(t *testing.T) {
+	err := naptan.InvalidStopCodeError{
+		Code:   "02003581!",
+		Reason: "invalid character '!'",
+	}
+
+	expectedError := "Invalid stop code \"02003581!\": invalid character '!'"
+
+	if err.Error() != expectedError {
+		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
+	}
+}
@@ -0,0 +1,130 @@
+package naptan
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Scores given to a query word depending on how well it matches a word of the stop
+const (
+	exactScore  = 1.0
+	prefixScore = 0.8
+	fuzzyScore  = 0.6
+)
+
+// abbreviations are expanded so that "High St" matches "High Street"
+var abbreviations = map[string]string{
+	"ave":  "avenue",
+	"cl":   "close",
+	"ct":   "court",
+	"dr":   "drive",
+	"gdns": "gardens",
+	"gn":   "green",
+	"la":   "lane",
+	"ln":   "lane",
+	"pde":  "parade",
+	"pk":   "park",
+	"pl":   "place",
+	"rd":   "road",
+	"sq":   "square",
+	"st":   "street",
+	"stn":  "station",
+	"terr": "terrace",
+	"opp":  "opposite",
+}
+
+// Match represents a stop found by a name search
+type Match struct {
+	Stop  *Stop
+	Score float64
+}
+
+// tokenise splits the text in to lower case words with punctuation removed and abbreviations expanded
+func tokenise(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for i, word := range words {
+		if expanded, ok := abbreviations[word]; ok {
+			words[i] = expanded
+		}
+	}
+
+	return words
+}
+
+// score returns how well the query words match the words of a stop, between 0 and 1.
+// Every query word must match one of the words of the stop, otherwise the score is 0.
+func score(query []string, words []string) float64 {
+	if len(query) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, q := range query {
+		best := 0.0
+		for _, w := range words {
+			best = max(best, wordScore(q, w))
+			if best == exactScore {
+				break
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+
+	return total / float64(len(query))
+}
+
+func wordScore(query string, word string) float64 {
+	switch {
+	case query == word:
+		return exactScore
+	case len(query) >= 3 && strings.HasPrefix(word, query):
+		return prefixScore
+	case levenshtein(query, word) <= allowedEdits(query):
+		return fuzzyScore
+	default:
+		return 0
+	}
+}
+
+// allowedEdits returns the number of typos tolerated for a query word of that length
+func allowedEdits(word string) int {
+	switch n := len([]rune(word)); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// levenshtein returns the edit distance between the two strings
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
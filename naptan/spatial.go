@@ -0,0 +1,136 @@
+package naptan
+
+import (
+	"math"
+	"sort"
+)
+
+// earthRadius is the mean radius of the Earth in metres
+const earthRadius = 6371008.8
+
+// metresPerDegree is the distance of one degree of latitude in metres
+const metresPerDegree = earthRadius * math.Pi / 180
+
+// cellSize is the size of the cells of the spatial index in degrees, roughly 1km
+const cellSize = 0.01
+
+// NearbyStop represents a stop found near a location
+type NearbyStop struct {
+	Stop *Stop
+	// Distance from the location to the stop in metres
+	Distance float64
+	// Bearing from the location to the stop in degrees clockwise from north
+	Bearing float64
+}
+
+// cell is the position of a cell in the grid used to index the stops by location
+type cell struct {
+	lat int
+	lon int
+}
+
+func cellOf(lat float64, lon float64) cell {
+	return cell{
+		lat: int(math.Floor(lat / cellSize)),
+		lon: int(math.Floor(lon / cellSize)),
+	}
+}
+
+// indexLocations adds the active stops that have a location to the grid
+func (d *Dataset) indexLocations() {
+	d.cells = make(map[cell][]int)
+
+	for i, stop := range d.stops {
+		if !stop.Active() || (stop.Latitude == 0 && stop.Longitude == 0) {
+			continue
+		}
+		c := cellOf(stop.Latitude, stop.Longitude)
+		d.cells[c] = append(d.cells[c], i)
+	}
+}
+
+// FindStopsNear returns up to limit active stops within the radius in metres of the location,
+// closest first. A limit of zero returns all the stops within the radius.
+func (d *Dataset) FindStopsNear(lat float64, lon float64, radius float64, limit int) []NearbyStop {
+	if radius <= 0 {
+		return nil
+	}
+
+	// Only the cells that overlap the bounding box of the radius need to be searched, and a box larger
+	// than the globe covers no more of it than the globe does
+	latDelta := math.Min(radius/metresPerDegree, 180)
+	lonDelta := math.Min(radius/(metresPerDegree*math.Max(math.Cos(lat*math.Pi/180), 0.01)), 360)
+	minCell := cellOf(lat-latDelta, lon-lonDelta)
+	maxCell := cellOf(lat+latDelta, lon+lonDelta)
+
+	var nearby []NearbyStop
+	add := func(indices []int) {
+		for _, i := range indices {
+			stop := &d.stops[i]
+			distance := Distance(lat, lon, stop.Latitude, stop.Longitude)
+			if distance > radius {
+				continue
+			}
+			nearby = append(nearby, NearbyStop{
+				Stop:     stop,
+				Distance: distance,
+				Bearing:  Bearing(lat, lon, stop.Latitude, stop.Longitude),
+			})
+		}
+	}
+
+	// Looking up every cell of a large box costs more than checking the cells that have stops
+	cells := (maxCell.lat - minCell.lat + 1) * (maxCell.lon - minCell.lon + 1)
+	if cells > len(d.cells) {
+		for c, indices := range d.cells {
+			if c.lat >= minCell.lat && c.lat <= maxCell.lat && c.lon >= minCell.lon && c.lon <= maxCell.lon {
+				add(indices)
+			}
+		}
+	} else {
+		for cellLat := minCell.lat; cellLat <= maxCell.lat; cellLat++ {
+			for cellLon := minCell.lon; cellLon <= maxCell.lon; cellLon++ {
+				add(d.cells[cell{lat: cellLat, lon: cellLon}])
+			}
+		}
+	}
+
+	sort.Slice(nearby, func(i, j int) bool {
+		if nearby[i].Distance != nearby[j].Distance {
+			return nearby[i].Distance < nearby[j].Distance
+		}
+		return nearby[i].Stop.ATCOCode < nearby[j].Stop.ATCOCode
+	})
+
+	if limit > 0 && len(nearby) > limit {
+		nearby = nearby[:limit]
+	}
+
+	return nearby
+}
+
+// Distance returns the great-circle distance in metres between the two locations
+func Distance(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	latDiff := radians(lat2 - lat1)
+	lonDiff := radians(lon2 - lon1)
+
+	a := math.Sin(latDiff/2)*math.Sin(latDiff/2) +
+		math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Sin(lonDiff/2)*math.Sin(lonDiff/2)
+
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Bearing returns the initial bearing in degrees clockwise from north from the first location to the second
+func Bearing(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	lonDiff := radians(lon2 - lon1)
+
+	y := math.Sin(lonDiff) * math.Cos(radians(lat2))
+	x := math.Cos(radians(lat1))*math.Sin(radians(lat2)) -
+		math.Sin(radians(lat1))*math.Cos(radians(lat2))*math.Cos(lonDiff)
+
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,149 @@
+package naptan_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/conradhodge/travel-api-client/naptan"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindStopsNear(t *testing.T) {
+	dataset, err := naptan.Load("testdata/Stops.csv")
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	type nearby struct {
+		Code     string
+		Distance int
+		Bearing  int
+	}
+
+	tests := []struct {
+		name           string
+		lat            float64
+		lon            float64
+		radius         float64
+		limit          int
+		expectedNearby []nearby
+	}{
+		{
+			name:   "Closest stops first",
+			lat:    52.13700,
+			lon:    -0.47000,
+			radius: 500,
+			expectedNearby: []nearby{
+				{Code: "0200BDA00101", Distance: 84, Bearing: 319},
+				{Code: "0200BDA00102", Distance: 85, Bearing: 323},
+			},
+		},
+		{
+			name:   "Limited number of stops",
+			lat:    52.13700,
+			lon:    -0.47000,
+			radius: 500,
+			limit:  1,
+			expectedNearby: []nearby{
+				{Code: "0200BDA00101", Distance: 84, Bearing: 319},
+			},
+		},
+		{
+			name:   "Large radius",
+			lat:    52.13700,
+			lon:    -0.47000,
+			radius: 500000,
+			limit:  2,
+			expectedNearby: []nearby{
+				{Code: "0200BDA00101", Distance: 84, Bearing: 319},
+				{Code: "0200BDA00102", Distance: 85, Bearing: 323},
+			},
+		},
+		{
+			name:   "Radius larger than the Earth",
+			lat:    52.13700,
+			lon:    -0.47000,
+			radius: 1e12,
+			limit:  1,
+			expectedNearby: []nearby{
+				{Code: "0200BDA00101", Distance: 84, Bearing: 319},
+			},
+		},
+		{
+			name:   "Inactive stops are excluded",
+			lat:    52.13600,
+			lon:    -0.46790,
+			radius: 50,
+		},
+		{
+			name:   "Stops in neighbouring cells",
+			lat:    51.95000,
+			lon:    -0.53400,
+			radius: 150,
+			expectedNearby: []nearby{
+				{Code: "020035811", Distance: 99, Bearing: 174},
+				{Code: "020035812", Distance: 111, Bearing: 170},
+			},
+		},
+		{
+			name:   "No stops within radius",
+			lat:    51.44927,
+			lon:    -2.58569,
+			radius: 1000,
+		},
+		{
+			name:   "Zero radius",
+			lat:    52.13757,
+			lon:    -0.47081,
+			radius: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actual []nearby
+			for _, stop := range dataset.FindStopsNear(test.lat, test.lon, test.radius, test.limit) {
+				actual = append(actual, nearby{
+					Code:     stop.Stop.ATCOCode,
+					Distance: int(math.Round(stop.Distance)),
+					Bearing:  int(math.Round(stop.Bearing)),
+				})
+			}
+
+			if diff := cmp.Diff(test.expectedNearby, actual); diff != "" {
+				t.Errorf("FindStopsNear() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDistanceAndBearing(t *testing.T) {
+	tests := []struct {
+		lat1             float64
+		lon1             float64
+		lat2             float64
+		lon2             float64
+		expectedDistance float64
+		expectedBearing  float64
+	}{
+		{lat1: 51.5, lon1: 0, lat2: 52.5, lon2: 0, expectedDistance: 111195, expectedBearing: 0},
+		{lat1: 52.5, lon1: 0, lat2: 51.5, lon2: 0, expectedDistance: 111195, expectedBearing: 180},
+		{lat1: 0, lon1: 0, lat2: 0, lon2: 1, expectedDistance: 111195, expectedBearing: 90},
+		{lat1: 0, lon1: 1, lat2: 0, lon2: 0, expectedDistance: 111195, expectedBearing: 270},
+		{lat1: 51.5, lon1: -0.1, lat2: 51.5, lon2: -0.1, expectedDistance: 0, expectedBearing: 0},
+	}
+	for _, test := range tests {
+		name := fmt.Sprintf("%v,%v to %v,%v", test.lat1, test.lon1, test.lat2, test.lon2)
+		t.Run(name, func(t *testing.T) {
+			distance := naptan.Distance(test.lat1, test.lon1, test.lat2, test.lon2)
+			if math.Round(distance) != test.expectedDistance {
+				t.Fatalf("Expected distance %v; got %v", test.expectedDistance, distance)
+			}
+
+			bearing := naptan.Bearing(test.lat1, test.lon1, test.lat2, test.lon2)
+			if math.Round(bearing) != test.expectedBearing {
+				t.Fatalf("Expected bearing %v; got %v", test.expectedBearing, bearing)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package naptan
+
+import "strings"
+
+// Stop represents a stop point in the NaPTAN dataset
+type Stop struct {
+	ATCOCode               string
+	NaptanCode             string
+	CommonName             string
+	Indicator              string
+	Street                 string
+	Landmark               string
+	LocalityCode           string
+	LocalityName           string
+	ParentLocalityName     string
+	Town                   string
+	Latitude               float64
+	Longitude              float64
+	StopType               string
+	AdministrativeAreaCode string
+	Status                 string
+}
+
+// Name returns the common name of the stop with its indicator, e.g. "High Street (Stop A)"
+func (s Stop) Name() string {
+	if s.Indicator == "" {
+		return s.CommonName
+	}
+
+	return s.CommonName + " (" + s.Indicator + ")"
+}
+
+// Active returns whether the stop is in use, stops without a status are assumed to be active
+func (s Stop) Active() bool {
+	switch strings.ToLower(s.Status) {
+	case "", "act", "active":
+		return true
+	default:
+		return false
+	}
+}
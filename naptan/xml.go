@@ -0,0 +1,117 @@
+package naptan
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// stopPoint represents a StopPoint in the NaPTAN XML
+type stopPoint struct {
+	Status     string `xml:"Status,attr"`
+	AtcoCode   string `xml:"AtcoCode"`
+	NaptanCode string `xml:"NaptanCode"`
+	Descriptor struct {
+		CommonName string `xml:"CommonName"`
+		Landmark   string `xml:"Landmark"`
+		Street     string `xml:"Street"`
+		Indicator  string `xml:"Indicator"`
+	} `xml:"Descriptor"`
+	Place struct {
+		NptgLocalityRef string `xml:"NptgLocalityRef"`
+		Town            string `xml:"Town"`
+		Location        struct {
+			Longitude   string `xml:"Longitude"`
+			Latitude    string `xml:"Latitude"`
+			Translation struct {
+				Longitude string `xml:"Longitude"`
+				Latitude  string `xml:"Latitude"`
+			} `xml:"Translation"`
+		} `xml:"Location"`
+	} `xml:"Place"`
+	StopClassification struct {
+		StopType string `xml:"StopType"`
+	} `xml:"StopClassification"`
+	AdministrativeAreaRef string `xml:"AdministrativeAreaRef"`
+}
+
+// LoadXMLFile returns the dataset from the NaPTAN XML file
+func LoadXMLFile(path string) (*Dataset, error) {
+	f, err := os.Open(path) // #nosec G304 -- the path is chosen by the caller
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadXML(f)
+}
+
+// LoadXML returns the dataset from the NaPTAN stops in XML format.
+// The stop points are decoded one at a time as the full dataset is large.
+func LoadXML(r io.Reader) (*Dataset, error) {
+	decoder := xml.NewDecoder(r)
+
+	var stops []Stop
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read NaPTAN XML")
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "StopPoint" {
+			continue
+		}
+
+		point := stopPoint{}
+		if err := decoder.DecodeElement(&point, &start); err != nil {
+			return nil, errors.Wrap(err, "cannot read NaPTAN XML stop point")
+		}
+
+		stop, err := point.stop()
+		if err != nil {
+			return nil, err
+		}
+		stops = append(stops, stop)
+	}
+
+	return NewDataset(stops), nil
+}
+
+func (p stopPoint) stop() (Stop, error) {
+	stop := Stop{
+		ATCOCode:               strings.TrimSpace(p.AtcoCode),
+		NaptanCode:             strings.TrimSpace(p.NaptanCode),
+		CommonName:             strings.TrimSpace(p.Descriptor.CommonName),
+		Indicator:              strings.TrimSpace(p.Descriptor.Indicator),
+		Street:                 strings.TrimSpace(p.Descriptor.Street),
+		Landmark:               strings.TrimSpace(p.Descriptor.Landmark),
+		LocalityCode:           strings.TrimSpace(p.Place.NptgLocalityRef),
+		Town:                   strings.TrimSpace(p.Place.Town),
+		StopType:               strings.TrimSpace(p.StopClassification.StopType),
+		AdministrativeAreaCode: strings.TrimSpace(p.AdministrativeAreaRef),
+		Status:                 p.Status,
+	}
+
+	// The location is either WGS84 or a translation of a grid reference
+	latitude, longitude := p.Place.Location.Latitude, p.Place.Location.Longitude
+	if latitude == "" && longitude == "" {
+		latitude, longitude = p.Place.Location.Translation.Latitude, p.Place.Location.Translation.Longitude
+	}
+
+	var err error
+	if stop.Latitude, err = parseCoordinate(strings.TrimSpace(latitude)); err != nil {
+		return Stop{}, errors.Wrapf(err, "invalid latitude for stop %s", stop.ATCOCode)
+	}
+	if stop.Longitude, err = parseCoordinate(strings.TrimSpace(longitude)); err != nil {
+		return Stop{}, errors.Wrapf(err, "invalid longitude for stop %s", stop.ATCOCode)
+	}
+
+	return stop, nil
+}
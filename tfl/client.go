@@ -0,0 +1,96 @@
+package tfl
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// Client stores the details required to access the TfL Unified API
+type Client struct {
+	AppKey string
+	// URL of the API, the TfL Unified API is used if not set
+	URL    string
+	Client *http.Client
+}
+
+// NewClient returns the client to access the TfL Unified API
+func NewClient(appKey string, httpClient *http.Client) API {
+	return &Client{
+		AppKey: appKey,
+		URL:    baseURL,
+		Client: httpClient,
+	}
+}
+
+// GetArrivals returns the arrival predictions for the stop that the NaPTAN code represents
+func (c *Client) GetArrivals(naptanCode string) ([]Prediction, error) {
+	body, err := c.get("/StopPoint/" + url.PathEscape(naptanCode) + "/Arrivals")
+	if err != nil {
+		return nil, err
+	}
+
+	predictions := []Prediction{}
+	if err := json.Unmarshal(body, &predictions); err != nil {
+		return nil, err
+	}
+
+	for i, prediction := range predictions {
+		log.Printf(
+			"Index: %d, Mode: %s, Line: %s, Destination: %s, Expected Arrival: %s",
+			i,
+			prediction.ModeName,
+			prediction.LineName,
+			prediction.DestinationName,
+			prediction.ExpectedArrival,
+		)
+	}
+
+	return predictions, nil
+}
+
+// get sends a GET request for the path to the API and returns the response body
+func (c *Client) get(path string) ([]byte, error) {
+	apiURL := c.URL
+	if apiURL == "" {
+		apiURL = baseURL
+	}
+
+	req, err := http.NewRequest(http.MethodGet, apiURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", contentType)
+	if c.AppKey != "" {
+		query := req.URL.Query()
+		query.Set("app_key", c.AppKey)
+		req.URL.RawQuery = query.Encode()
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if berr := resp.Body.Close(); berr != nil {
+			err = berr
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Error response from API: %v", resp)
+		return nil, errors.Errorf("error status from API: %d", resp.StatusCode)
+	}
+
+	return body, nil
+}
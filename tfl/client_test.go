@@ -0,0 +1,146 @@
+package tfl_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/conradhodge/travel-api-client/tfl"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetArrivals(t *testing.T) {
+	tests := []struct {
+		name                string
+		appKey              string
+		response            string
+		statusCode          int
+		expectedQuery       string
+		expectedPredictions []tfl.Prediction
+		expectedError       error
+	}{
+		{
+			name:   "Predictions returned",
+			appKey: "letmein",
+			response: `[{
+				"id": "-1216417547",
+				"operationType": 1,
+				"vehicleId": "LX11AVW",
+				"naptanId": "490008660N",
+				"stationName": "Oxford Circus Station",
+				"lineId": "88",
+				"lineName": "88",
+				"platformName": "OE",
+				"direction": "outbound",
+				"bearing": "178",
+				"destinationNaptanId": "",
+				"destinationName": "Clapham Common",
+				"timestamp": "2020-03-30T11:30:00.1234567Z",
+				"timeToStation": 294,
+				"currentLocation": "",
+				"towards": "Piccadilly Circus",
+				"expectedArrival": "2020-03-30T11:34:54Z",
+				"timeToLive": "2020-03-30T11:35:24Z",
+				"modeName": "bus"
+			}]`,
+			statusCode:    http.StatusOK,
+			expectedQuery: "app_key=letmein",
+			expectedPredictions: []tfl.Prediction{
+				{
+					ID:              "-1216417547",
+					OperationType:   1,
+					VehicleID:       "LX11AVW",
+					NaptanID:        "490008660N",
+					StationName:     "Oxford Circus Station",
+					LineID:          "88",
+					LineName:        "88",
+					PlatformName:    "OE",
+					Direction:       "outbound",
+					Bearing:         "178",
+					DestinationName: "Clapham Common",
+					Timestamp:       "2020-03-30T11:30:00.1234567Z",
+					TimeToStation:   294,
+					Towards:         "Piccadilly Circus",
+					ExpectedArrival: "2020-03-30T11:34:54Z",
+					TimeToLive:      "2020-03-30T11:35:24Z",
+					ModeName:        "bus",
+				},
+			},
+		},
+		{
+			name:                "No predictions and no app key",
+			response:            `[]`,
+			statusCode:          http.StatusOK,
+			expectedPredictions: []tfl.Prediction{},
+		},
+		{
+			name:          "Invalid response",
+			response:      `{"`,
+			statusCode:    http.StatusOK,
+			expectedError: errors.New("unexpected end of JSON input"),
+		},
+		{
+			name:          "404 response received from API request",
+			response:      `{"message": "The following stop point is not recognised: 490008660N"}`,
+			statusCode:    http.StatusNotFound,
+			expectedError: errors.New("error status from API: 404"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				expectedPath := "/StopPoint/490008660N/Arrivals"
+				if r.URL.Path != expectedPath {
+					t.Errorf("Expected path: %s, got: %s", expectedPath, r.URL.Path)
+				}
+				if r.URL.RawQuery != test.expectedQuery {
+					t.Errorf("Expected query: %s, got: %s", test.expectedQuery, r.URL.RawQuery)
+				}
+
+				w.WriteHeader(test.statusCode)
+				fmt.Fprint(w, test.response)
+			}))
+			defer server.Close()
+
+			client := &tfl.Client{
+				AppKey: test.appKey,
+				URL:    server.URL,
+				Client: server.Client(),
+			}
+
+			predictions, err := client.GetArrivals("490008660N")
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if diff := cmp.Diff(test.expectedPredictions, predictions); diff != "" {
+				t.Errorf("GetArrivals() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetArrivalsDoFails(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	client := tfl.NewClient("letmein", server.Client())
+	client.(*tfl.Client).URL = server.URL
+
+	_, err := client.GetArrivals("490008660N")
+	if err == nil {
+		t.Fatal("Expected error; got no error")
+	}
+}
@@ -0,0 +1,5 @@
+package tfl
+
+// URL and content type for API
+const baseURL = "https://api.tfl.gov.uk"
+const contentType = "application/json"
@@ -0,0 +1,6 @@
+package tfl
+
+// API represents the interface to the TfL Unified API
+type API interface {
+	GetArrivals(naptanCode string) ([]Prediction, error)
+}
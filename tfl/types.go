@@ -0,0 +1,24 @@
+package tfl
+
+// Prediction represents an arrival prediction in the StopPoint Arrivals JSON response
+type Prediction struct {
+	ID                  string `json:"id"`
+	OperationType       int    `json:"operationType"`
+	VehicleID           string `json:"vehicleId"`
+	NaptanID            string `json:"naptanId"`
+	StationName         string `json:"stationName"`
+	LineID              string `json:"lineId"`
+	LineName            string `json:"lineName"`
+	PlatformName        string `json:"platformName"`
+	Direction           string `json:"direction"`
+	Bearing             string `json:"bearing"`
+	DestinationNaptanID string `json:"destinationNaptanId"`
+	DestinationName     string `json:"destinationName"`
+	Timestamp           string `json:"timestamp"`
+	TimeToStation       int    `json:"timeToStation"`
+	CurrentLocation     string `json:"currentLocation"`
+	Towards             string `json:"towards"`
+	ExpectedArrival     string `json:"expectedArrival"`
+	TimeToLive          string `json:"timeToLive"`
+	ModeName            string `json:"modeName"`
+}
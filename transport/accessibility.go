@@ -0,0 +1,76 @@
+package transport
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/conradhodge/travel-api-client/traveline"
+)
+
+// Occupancy is how full a vehicle is
+type Occupancy string
+
+// Occupancy levels, the empty occupancy is used when the provider doesn't publish it
+const (
+	SeatsAvailable    Occupancy = "seatsAvailable"
+	StandingAvailable Occupancy = "standingAvailable"
+	Full              Occupancy = "full"
+)
+
+// Vehicle features that affect accessibility
+const (
+	lowFloorFeature             = "lowFloor"
+	wheelchairAccessibleFeature = "wheelchairAccessible"
+)
+
+// Accessible returns whether the vehicle of the departure is low floor or wheelchair accessible
+func (d DepartureInfo) Accessible() bool {
+	return d.LowFloor || d.WheelchairAccessible
+}
+
+// convertVehicle adds the occupancy and accessibility of the vehicle on the journey to the departure.
+// The occupancy is worked out from the seats when it isn't published, and a vehicle with wheelchair
+// capacity is wheelchair accessible.
+func convertVehicle(departure *DepartureInfo, journey *traveline.MonitoredVehicleJourney) {
+	departure.Occupancy = Occupancy(journey.Occupancy)
+	departure.VehicleFeatures = journey.VehicleFeatureRef
+
+	for _, feature := range journey.VehicleFeatureRef {
+		switch {
+		case strings.EqualFold(feature, lowFloorFeature):
+			departure.LowFloor = true
+		case strings.EqualFold(feature, wheelchairAccessibleFeature):
+			departure.WheelchairAccessible = true
+		}
+	}
+
+	extensions := journey.Extensions.VehicleJourney
+
+	seatedOccupancy, occupancyErr := strconv.Atoi(extensions.SeatedOccupancy)
+	seatedCapacity, capacityErr := strconv.Atoi(extensions.SeatedCapacity)
+	if departure.Occupancy == "" && occupancyErr == nil && capacityErr == nil && seatedCapacity > 0 {
+		departure.Occupancy = SeatsAvailable
+		if seatedOccupancy >= seatedCapacity {
+			departure.Occupancy = StandingAvailable
+		}
+	}
+
+	wheelchairCapacity, err := strconv.Atoi(extensions.WheelchairCapacity)
+	if err != nil {
+		return
+	}
+	if wheelchairCapacity > 0 {
+		departure.WheelchairAccessible = true
+	}
+
+	// Spaces can't be worked out without the occupancy
+	wheelchairOccupancy, err := strconv.Atoi(extensions.WheelchairOccupancy)
+	if err != nil {
+		return
+	}
+	spaces := wheelchairCapacity - wheelchairOccupancy
+	if spaces < 0 {
+		spaces = 0
+	}
+	departure.WheelchairSpaces = &spaces
+}
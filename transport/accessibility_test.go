@@ -0,0 +1,126 @@
+package transport_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/internal/siritest"
+	"github.com/conradhodge/travel-api-client/transport"
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetNextTravelAccessibility(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	response, err := os.ReadFile("../traveline/testdata/stop_monitoring_vehicles.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	firstDepartureTime, _ := time.Parse(time.RFC3339, "2020-03-30T12:40:00+01:00")
+	aimedDepartureTime, _ := time.Parse(time.RFC3339, "2020-03-30T12:50:00+01:00")
+	spaces := 1
+
+	tests := []struct {
+		name           string
+		accessibleOnly bool
+		response       string
+		expectedError  error
+		expectedResult *transport.DepartureInfo
+	}{
+		{
+			name:     "Occupancy of the next departure",
+			response: string(response),
+			expectedResult: &transport.DepartureInfo{
+				VehicleMode:        "bus",
+				LineName:           "42",
+				DirectionName:      "City Centre",
+				AimedDepartureTime: &firstDepartureTime,
+				Source:             transport.LiveSource,
+				JourneyRef:         "1042",
+				Occupancy:          transport.Full,
+			},
+		},
+		{
+			name:           "Next accessible departure",
+			accessibleOnly: true,
+			response:       string(response),
+			expectedResult: &transport.DepartureInfo{
+				VehicleMode:          "bus",
+				LineName:             "X5",
+				DirectionName:        "Bedford",
+				AimedDepartureTime:   &aimedDepartureTime,
+				Source:               transport.LiveSource,
+				JourneyRef:           "2005",
+				Occupancy:            transport.SeatsAvailable,
+				VehicleFeatures:      []string{"lowFloor", "wifi"},
+				LowFloor:             true,
+				WheelchairAccessible: true,
+				WheelchairSpaces:     &spaces,
+			},
+		},
+		{
+			name: "Siri 2.0 occupancy of the next departure",
+			response: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><ServiceDelivery><StopMonitoringDelivery version="2.0">` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><PublishedLineName>42</PublishedLineName>` +
+				`<Occupancy>standingRoomOnly</Occupancy><MonitoredCall>` +
+				`<AimedDepartureTime>2020-03-30T12:40:00+01:00</AimedDepartureTime>` +
+				`</MonitoredCall></MonitoredVehicleJourney></MonitoredStopVisit>` +
+				`</StopMonitoringDelivery></ServiceDelivery></Siri>`,
+			expectedResult: &transport.DepartureInfo{
+				LineName:           "42",
+				AimedDepartureTime: &firstDepartureTime,
+				Source:             transport.LiveSource,
+				Occupancy:          transport.StandingAvailable,
+			},
+		},
+		{
+			name:           "No accessible departures",
+			accessibleOnly: true,
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><StopMonitoringDelivery>` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><VehicleFeatureRef>wifi</VehicleFeatureRef><MonitoredCall>` +
+				`<AimedDepartureTime>2020-03-30T12:40:00+01:00</AimedDepartureTime>` +
+				`</MonitoredCall></MonitoredVehicleJourney></MonitoredStopVisit>` +
+				`</StopMonitoringDelivery></ServiceDelivery></Siri>`,
+			expectedError: errors.New("No next departure times found"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := siritest.NewProducer(t).
+				WithAuth("TravelineAPI999", "letmein").
+				Respond("StopMonitoringRequest", http.StatusOK, test.response)
+			defer server.Close()
+
+			api := &traveline.Client{
+				Username: "TravelineAPI999",
+				Password: "letmein",
+				URL:      server.URL,
+				Client:   server.Client(),
+			}
+			req := transport.NewTraveline(api)
+			req.AccessibleOnly = test.accessibleOnly
+
+			result, err := req.GetNextDepartureTime("020035811", when)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+				t.Errorf("GetNextDepartureTime() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
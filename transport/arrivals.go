@@ -0,0 +1,118 @@
+package transport
+
+import (
+	"sort"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/uuid"
+)
+
+// ArrivalInfo represents the details of an arrival at a stop,
+// the aimed arrival time is not set by providers that only publish predictions
+type ArrivalInfo struct {
+	VehicleMode         string
+	LineName            string
+	DirectionName       string
+	AimedArrivalTime    *time.Time
+	ExpectedArrivalTime *time.Time
+	// Terminates is set when the journey ends at the stop, so passengers cannot board it
+	Terminates bool
+	// Source is LiveSource or ScheduledSource, so that scheduled times can be shown differently
+	Source string
+	// JourneyRef identifies the journey to the provider, if it publishes one
+	JourneyRef string
+	// Status is the progress of the journey at the stop, e.g. Cancelled, if the provider publishes it
+	Status Status
+}
+
+// GetNextArrivals returns the arrivals at the stop that the NaPTAN code represents at or after the time,
+// earliest first. The departure time is used for journeys that only have a departure from the stop.
+func (c *Traveline) GetNextArrivals(naptanCode string, when time.Time) ([]ArrivalInfo, error) {
+	monitoringRef, err := c.monitoringRef(naptanCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkStop(monitoringRef, when); err != nil {
+		return nil, err
+	}
+
+	requestRef := uuid.New().String()
+	request, err := c.API.BuildServiceRequest(requestRef, monitoringRef, when, c.RequestOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.API.Send(request)
+	if err != nil {
+		return nil, err
+	}
+
+	visits, err := c.API.ParseStopMonitoringDelivery(requestRef, response)
+	if err != nil {
+		return nil, err
+	}
+
+	arrivals := []ArrivalInfo{}
+	for _, visit := range visits {
+		arrival, err := convertArrival(visit.MonitoredVehicleJourney, monitoringRef)
+		if err != nil {
+			return nil, err
+		}
+		if arrival == nil || arrivalTime(*arrival).Before(when) {
+			continue
+		}
+		arrivals = append(arrivals, *arrival)
+	}
+
+	if len(arrivals) == 0 {
+		return nil, &NoArrivalsFoundError{NaptanCode: naptanCode}
+	}
+
+	sort.SliceStable(arrivals, func(i, j int) bool {
+		return arrivalTime(arrivals[i]).Before(arrivalTime(arrivals[j]))
+	})
+
+	return arrivals, nil
+}
+
+// convertArrival returns the arrival of the journey at the stop, or nil if it has no times
+func convertArrival(journey traveline.MonitoredVehicleJourney, monitoringRef string) (*ArrivalInfo, error) {
+	call := journey.MonitoredCall
+	aimed, expected := call.AimedArrivalTime, call.ExpectedArrivalTime
+	if aimed == "" && expected == "" {
+		aimed, expected = call.AimedDepartureTime, call.ExpectedDepartureTime
+	}
+	if aimed == "" && expected == "" {
+		return nil, nil
+	}
+
+	arrival := ArrivalInfo{
+		VehicleMode:   journey.VehicleMode,
+		LineName:      journey.PublishedLineName,
+		DirectionName: journey.DirectionName,
+		Source:        LiveSource,
+		JourneyRef:    journey.FramedVehicleJourneyRef.DatedVehicleJourneyRef,
+		Status:        convertStatus(call.ArrivalStatus, journey.Monitored),
+		Terminates:    terminatesAt(&journey, monitoringRef),
+	}
+
+	err := convertCallTimes(
+		callTime{value: aimed, target: &arrival.AimedArrivalTime},
+		callTime{value: expected, target: &arrival.ExpectedArrivalTime},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &arrival, nil
+}
+
+// arrivalTime returns the expected arrival time, or the aimed arrival time if there is no prediction
+func arrivalTime(arrival ArrivalInfo) time.Time {
+	if arrival.ExpectedArrivalTime != nil {
+		return *arrival.ExpectedArrivalTime
+	}
+
+	return *arrival.AimedArrivalTime
+}
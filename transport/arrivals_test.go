@@ -0,0 +1,145 @@
+package transport_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/internal/siritest"
+	"github.com/conradhodge/travel-api-client/transport"
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetNextArrivals(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	response, err := os.ReadFile("../traveline/testdata/stop_monitoring_arrivals.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	parseTime := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &parsed
+	}
+
+	tests := []struct {
+		name           string
+		naptanCode     string
+		statusCode     int
+		response       string
+		expectedError  error
+		expectedResult []transport.ArrivalInfo
+	}{
+		{
+			name:       "Arrivals in order",
+			naptanCode: "020035899",
+			statusCode: http.StatusOK,
+			response:   string(response),
+			expectedResult: []transport.ArrivalInfo{
+				{
+					VehicleMode:      "bus",
+					LineName:         "X5",
+					DirectionName:    "Bedford",
+					AimedArrivalTime: parseTime("2020-03-30T12:38:00+01:00"),
+					Source:           transport.LiveSource,
+					JourneyRef:       "2005",
+				},
+				{
+					VehicleMode:         "bus",
+					LineName:            "42",
+					DirectionName:       "City Centre",
+					AimedArrivalTime:    parseTime("2020-03-30T12:45:00+01:00"),
+					ExpectedArrivalTime: parseTime("2020-03-30T12:47:00+01:00"),
+					Terminates:          true,
+					Source:              transport.LiveSource,
+					JourneyRef:          "1042",
+				},
+			},
+		},
+		{
+			name:       "Arrival without a departure time at a stop on the way",
+			naptanCode: "020035899",
+			statusCode: http.StatusOK,
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><StopMonitoringDelivery>` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><PublishedLineName>42</PublishedLineName>` +
+				`<DestinationRef>020030001</DestinationRef><MonitoredCall>` +
+				`<AimedArrivalTime>2020-03-30T12:45:00+01:00</AimedArrivalTime>` +
+				`</MonitoredCall></MonitoredVehicleJourney></MonitoredStopVisit></StopMonitoringDelivery></ServiceDelivery></Siri>`,
+			expectedResult: []transport.ArrivalInfo{
+				{
+					LineName:         "42",
+					AimedArrivalTime: parseTime("2020-03-30T12:45:00+01:00"),
+					Source:           transport.LiveSource,
+				},
+			},
+		},
+		{
+			name:       "No arrivals after time",
+			naptanCode: "020035899",
+			statusCode: http.StatusOK,
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><StopMonitoringDelivery>` +
+				`</StopMonitoringDelivery></ServiceDelivery></Siri>`,
+			expectedError: errors.New(`No arrivals found for stop "020035899"`),
+		},
+		{
+			name:       "Invalid arrival time",
+			naptanCode: "020035899",
+			statusCode: http.StatusOK,
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><StopMonitoringDelivery>` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><MonitoredCall><AimedArrivalTime>bongo</AimedArrivalTime>` +
+				`</MonitoredCall></MonitoredVehicleJourney></MonitoredStopVisit></StopMonitoringDelivery></ServiceDelivery></Siri>`,
+			expectedError: &transport.InvalidTimeFoundError{
+				Time:   "bongo",
+				Reason: `parsing time "bongo" as "2006-01-02T15:04:05Z07:00": cannot parse "bongo" as "2006"`,
+			},
+		},
+		{
+			name:          "Error from API",
+			naptanCode:    "020035899",
+			statusCode:    http.StatusInternalServerError,
+			expectedError: errors.New("error status from API: 500"),
+		},
+		{
+			name:          "Invalid stop code",
+			naptanCode:    "Oxford Circus",
+			expectedError: errors.New(`Invalid stop code "Oxford Circus": NaPTAN code must be 7 or 8 characters`),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := siritest.NewProducer(t).
+				WithAuth("TravelineAPI999", "letmein").
+				Respond("StopMonitoringRequest", test.statusCode, test.response)
+			defer server.Close()
+
+			api := &traveline.Client{
+				Username: "TravelineAPI999",
+				Password: "letmein",
+				URL:      server.URL,
+				Client:   server.Client(),
+			}
+			req := transport.NewTraveline(api)
+
+			result, err := req.GetNextArrivals(test.naptanCode, when)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+				t.Errorf("GetNextArrivals() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package transport
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/uuid"
+)
+
+// Disruption represents a situation that disrupts the network, such as a diversion or stop closure
+type Disruption struct {
+	SituationNumber string
+	// Severity is the SIRI severity, e.g. slight, normal or severe
+	Severity        string
+	Progress        string
+	Reason          string
+	Summary         string
+	Description     string
+	ValidityPeriods []ValidityPeriod
+	// AffectedLines are the names of the lines affected, or their refs if the names are not published,
+	// all lines at the stops are affected if empty
+	AffectedLines []string
+	// AffectedLineRefs are the refs of the lines affected, as used by the producer in discovery
+	AffectedLineRefs []string
+	// AffectedStops are the ATCO codes of the stops affected
+	AffectedStops []string
+}
+
+// ValidityPeriod represents when a disruption applies, the start or end time is nil if open ended
+type ValidityPeriod struct {
+	StartTime *time.Time
+	EndTime   *time.Time
+}
+
+// ValidAt returns whether the disruption applies at the time, a disruption without validity periods always applies
+func (d Disruption) ValidAt(t time.Time) bool {
+	if len(d.ValidityPeriods) == 0 {
+		return true
+	}
+
+	for _, period := range d.ValidityPeriods {
+		if (period.StartTime == nil || !t.Before(*period.StartTime)) && (period.EndTime == nil || t.Before(*period.EndTime)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Affects returns whether the disruption applies to the line of the departure at the time it departs
+func (d Disruption) Affects(departure *DepartureInfo) bool {
+	if !d.ValidAt(departureTime(departure)) {
+		return false
+	}
+	if len(d.AffectedLines) == 0 {
+		return true
+	}
+
+	return anyEqualFold(d.AffectedLines, []string{departure.LineName})
+}
+
+// ended returns whether every validity period of the disruption has ended by the time
+func (d Disruption) ended(t time.Time) bool {
+	if len(d.ValidityPeriods) == 0 {
+		return false
+	}
+
+	for _, period := range d.ValidityPeriods {
+		if period.EndTime == nil || t.Before(*period.EndTime) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetDisruptions returns the current and planned disruptions at the stop that the NaPTAN code represents.
+// If discovery is used, disruptions to the whole of a line that serves the stop are included too.
+func (c *Traveline) GetDisruptions(naptanCode string, when time.Time) ([]Disruption, error) {
+	monitoringRef, err := c.monitoringRef(naptanCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.getDisruptions(monitoringRef, c.linesAt(monitoringRef, when), nil, when)
+}
+
+// linesAt returns the refs of the lines that serve the stop with the ATCO code, as the producer reports them
+// in discovery, or none if discovery isn't used or fails
+func (c *Traveline) linesAt(monitoringRef string, when time.Time) []string {
+	if c.Discovery == nil {
+		return nil
+	}
+
+	stopPoints, err := c.Discovery.StopPoints(when)
+	if err != nil {
+		log.Printf("Cannot get lines for stop %s: %s", monitoringRef, err)
+		return nil
+	}
+
+	return stopPoints[strings.ToUpper(monitoringRef)].LineRef
+}
+
+// getDisruptions returns the disruptions that have not ended and affect the stop with the ATCO code,
+// or the whole of any of the lines with the refs or names. A situation that cannot be converted is skipped
+// so that it doesn't hide the others.
+func (c *Traveline) getDisruptions(
+	monitoringRef string,
+	lineRefs []string,
+	lineNames []string,
+	when time.Time,
+) ([]Disruption, error) {
+	requestRef := uuid.New().String()
+	request, err := c.API.BuildSituationExchangeRequest(requestRef, when)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.API.Send(request)
+	if err != nil {
+		return nil, err
+	}
+
+	situations, err := c.API.ParseSituationExchangeDelivery(requestRef, response)
+	if err != nil {
+		return nil, err
+	}
+
+	disruptions := []Disruption{}
+	for _, situation := range situations {
+		if strings.EqualFold(situation.Progress, "closed") {
+			continue
+		}
+
+		disruption, err := convertSituation(situation)
+		if err != nil {
+			log.Printf("Ignoring situation %s: %s", situation.SituationNumber, err)
+			continue
+		}
+		if disruption.ended(when) || !disruption.affectsStopOrLine(monitoringRef, lineRefs, lineNames) {
+			continue
+		}
+
+		disruptions = append(disruptions, disruption)
+	}
+
+	return disruptions, nil
+}
+
+// attachDisruptions adds the disruptions at the stop that affect the departure, disruptions are
+// supplementary so the departure is still returned if they cannot be found
+func (c *Traveline) attachDisruptions(departure *DepartureInfo, monitoringRef string, when time.Time) {
+	disruptions, err := c.getDisruptions(monitoringRef, c.linesAt(monitoringRef, when), []string{departure.LineName}, when)
+	if err != nil {
+		log.Printf("Cannot get disruptions for stop %s: %s", monitoringRef, err)
+		return
+	}
+
+	for _, disruption := range disruptions {
+		if disruption.Affects(departure) {
+			departure.Disruptions = append(departure.Disruptions, disruption)
+		}
+	}
+}
+
+func (d Disruption) affectsStop(atcoCode string) bool {
+	for _, stop := range d.AffectedStops {
+		if strings.EqualFold(stop, atcoCode) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// affectsStopOrLine returns whether the disruption is at the stop, or is to the whole of one of the lines,
+// as a disruption that lists stops on a line only affects those stops
+func (d Disruption) affectsStopOrLine(atcoCode string, lineRefs []string, lineNames []string) bool {
+	if d.affectsStop(atcoCode) {
+		return true
+	}
+	if len(d.AffectedStops) > 0 {
+		return false
+	}
+
+	return anyEqualFold(d.AffectedLineRefs, lineRefs) || anyEqualFold(d.AffectedLines, lineNames)
+}
+
+// anyEqualFold returns whether any of the values is in the other values, ignoring case
+func anyEqualFold(values []string, others []string) bool {
+	for _, value := range values {
+		for _, other := range others {
+			if strings.EqualFold(value, other) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func convertSituation(situation traveline.PtSituationElement) (Disruption, error) {
+	disruption := Disruption{
+		SituationNumber: situation.SituationNumber,
+		Severity:        situation.Severity,
+		Progress:        situation.Progress,
+		Reason:          situation.MiscellaneousReason,
+		Summary:         strings.TrimSpace(situation.Summary),
+		Description:     strings.TrimSpace(situation.Description),
+	}
+
+	for _, period := range situation.ValidityPeriod {
+		validityPeriod := ValidityPeriod{}
+		if period.StartTime != "" {
+			startTime, err := convertDepartureTime(period.StartTime)
+			if err != nil {
+				return Disruption{}, err
+			}
+			validityPeriod.StartTime = &startTime
+		}
+		if period.EndTime != "" {
+			endTime, err := convertDepartureTime(period.EndTime)
+			if err != nil {
+				return Disruption{}, err
+			}
+			validityPeriod.EndTime = &endTime
+		}
+		disruption.ValidityPeriods = append(disruption.ValidityPeriods, validityPeriod)
+	}
+
+	for _, line := range situation.Affects.Lines {
+		name := line.PublishedLineName
+		if name == "" {
+			name = line.LineRef
+		}
+		disruption.AffectedLines = append(disruption.AffectedLines, name)
+		if line.LineRef != "" {
+			disruption.AffectedLineRefs = append(disruption.AffectedLineRefs, line.LineRef)
+		}
+	}
+
+	for _, stop := range situation.Affects.StopPoints {
+		disruption.AffectedStops = append(disruption.AffectedStops, stop.StopPointRef)
+	}
+
+	return disruption, nil
+}
@@ -0,0 +1,338 @@
+package transport_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/internal/siritest"
+	"github.com/conradhodge/travel-api-client/transport"
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetDisruptions(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	response, err := os.ReadFile("../traveline/testdata/situation_exchange.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	parse := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &parsed
+	}
+
+	tests := []struct {
+		name           string
+		naptanCode     string
+		statusCode     int
+		response       string
+		expectedError  error
+		expectedResult []transport.Disruption
+	}{
+		{
+			name:       "Current and planned disruptions at stop",
+			naptanCode: "020035811",
+			statusCode: http.StatusOK,
+			response:   string(response),
+			expectedResult: []transport.Disruption{
+				{
+					SituationNumber: "SIT-1",
+					Severity:        "severe",
+					Progress:        "open",
+					Reason:          "roadworks",
+					Summary:         "Route 42 diverted",
+					Description:     "Route 42 is diverted away from The Green, please use the stop on Station Road.",
+					ValidityPeriods: []transport.ValidityPeriod{
+						{StartTime: parse("2020-03-30T07:00:00+01:00"), EndTime: parse("2020-04-03T19:00:00+01:00")},
+					},
+					AffectedLines:    []string{"42"},
+					AffectedLineRefs: []string{"GLBE:42"},
+					AffectedStops:    []string{"020035811"},
+				},
+				{
+					SituationNumber: "SIT-2",
+					Severity:        "slight",
+					Progress:        "open",
+					Summary:         "Stop closed for resurfacing",
+					ValidityPeriods: []transport.ValidityPeriod{
+						{StartTime: parse("2020-04-06T00:00:00+01:00")},
+					},
+					AffectedStops: []string{"020035811"},
+				},
+			},
+		},
+		{
+			name:           "No disruptions at stop",
+			naptanCode:     "020035812",
+			statusCode:     http.StatusOK,
+			response:       string(response),
+			expectedResult: []transport.Disruption{},
+		},
+		{
+			name:       "Situation with invalid validity period is skipped",
+			naptanCode: "020035811",
+			statusCode: http.StatusOK,
+			response: `<Siri><ServiceDelivery><SituationExchangeDelivery><Situations>` +
+				`<PtSituationElement><SituationNumber>SIT-1</SituationNumber><ValidityPeriod><StartTime>soon</StartTime></ValidityPeriod>` +
+				`<Affects><StopPoints><AffectedStopPoint><StopPointRef>020035811</StopPointRef></AffectedStopPoint></StopPoints></Affects>` +
+				`</PtSituationElement>` +
+				`<PtSituationElement><SituationNumber>SIT-2</SituationNumber>` +
+				`<Affects><StopPoints><AffectedStopPoint><StopPointRef>020035811</StopPointRef></AffectedStopPoint></StopPoints></Affects>` +
+				`</PtSituationElement></Situations></SituationExchangeDelivery></ServiceDelivery></Siri>`,
+			expectedResult: []transport.Disruption{
+				{SituationNumber: "SIT-2", AffectedStops: []string{"020035811"}},
+			},
+		},
+		{
+			name:          "Error status",
+			naptanCode:    "020035811",
+			statusCode:    http.StatusUnauthorized,
+			response:      "Invalid user credentials",
+			expectedError: errors.New("error status from API: 401"),
+		},
+		{
+			name:          "Invalid stop code",
+			naptanCode:    "The-Green",
+			statusCode:    http.StatusOK,
+			response:      string(response),
+			expectedError: errors.New(`Invalid stop code "The-Green": invalid character '-'`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := siritest.NewProducer(t).
+				WithAuth("TravelineAPI999", "letmein").
+				Respond("SituationExchangeRequest", test.statusCode, test.response)
+			defer server.Close()
+
+			req := transport.NewTraveline(&traveline.Client{
+				Username: "TravelineAPI999",
+				Password: "letmein",
+				URL:      server.URL,
+				Client:   server.Client(),
+			})
+
+			result, err := req.GetDisruptions(test.naptanCode, when)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+				t.Errorf("GetDisruptions() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetDisruptionsOnLinesAtStop(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	stopPoints, err := os.ReadFile("../traveline/testdata/stop_points.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	// The refs of lines in discovery are compared with the refs of the lines in the situations, as their
+	// names can differ, and a disruption that lists stops on a line only affects those stops
+	situationExchange := `<Siri><ServiceDelivery><SituationExchangeDelivery><Situations>` +
+		`<PtSituationElement><SituationNumber>SIT-LINE</SituationNumber><Progress>open</Progress><Affects>` +
+		`<Networks><AffectedNetwork><AffectedLine><LineRef>42</LineRef><PublishedLineName>42 Express</PublishedLineName>` +
+		`</AffectedLine></AffectedNetwork></Networks></Affects></PtSituationElement>` +
+		`<PtSituationElement><SituationNumber>SIT-OTHER-STOP</SituationNumber><Progress>open</Progress><Affects>` +
+		`<Networks><AffectedNetwork><AffectedLine><LineRef>42</LineRef></AffectedLine></AffectedNetwork></Networks>` +
+		`<StopPoints><AffectedStopPoint><StopPointRef>020035811</StopPointRef></AffectedStopPoint></StopPoints>` +
+		`</Affects></PtSituationElement>` +
+		`<PtSituationElement><SituationNumber>SIT-OTHER-LINE</SituationNumber><Progress>open</Progress><Affects>` +
+		`<Networks><AffectedNetwork><AffectedLine><LineRef>X5</LineRef></AffectedLine></AffectedNetwork></Networks>` +
+		`</Affects></PtSituationElement>` +
+		`</Situations></SituationExchangeDelivery></ServiceDelivery></Siri>`
+
+	server := siritest.NewProducer(t).
+		WithAuth("TravelineAPI999", "letmein").
+		Respond("StopPointsRequest", http.StatusOK, string(stopPoints)).
+		Respond("SituationExchangeRequest", http.StatusOK, situationExchange)
+	defer server.Close()
+
+	api := &traveline.Client{
+		Username: "TravelineAPI999",
+		Password: "letmein",
+		URL:      server.URL,
+		Client:   server.Client(),
+	}
+	req := transport.NewTraveline(api)
+	req.Discovery = traveline.NewDiscovery(api, time.Hour)
+
+	result, err := req.GetDisruptions("0180BAC30249", when)
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	var disruptions []string
+	for _, disruption := range result {
+		disruptions = append(disruptions, disruption.SituationNumber)
+	}
+	if diff := cmp.Diff([]string{"SIT-LINE"}, disruptions); diff != "" {
+		t.Errorf("Disruptions (-want +got):\n%s", diff)
+	}
+}
+
+func TestDisruptionAffects(t *testing.T) {
+	parse := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &parsed
+	}
+
+	disruption := transport.Disruption{
+		ValidityPeriods: []transport.ValidityPeriod{
+			{StartTime: parse("2020-03-30T07:00:00+01:00"), EndTime: parse("2020-03-30T19:00:00+01:00")},
+		},
+		AffectedLines: []string{"42"},
+	}
+
+	tests := []struct {
+		name       string
+		disruption transport.Disruption
+		departure  *transport.DepartureInfo
+		expected   bool
+	}{
+		{
+			name:       "Line and time affected",
+			disruption: disruption,
+			departure:  &transport.DepartureInfo{LineName: "42", AimedDepartureTime: parse("2020-03-30T12:00:00+01:00")},
+			expected:   true,
+		},
+		{
+			name:       "Expected time used",
+			disruption: disruption,
+			departure: &transport.DepartureInfo{
+				LineName:              "42",
+				AimedDepartureTime:    parse("2020-03-30T18:55:00+01:00"),
+				ExpectedDepartureTime: parse("2020-03-30T19:05:00+01:00"),
+			},
+			expected: false,
+		},
+		{
+			name:       "Other line",
+			disruption: disruption,
+			departure:  &transport.DepartureInfo{LineName: "X5", AimedDepartureTime: parse("2020-03-30T12:00:00+01:00")},
+			expected:   false,
+		},
+		{
+			name:       "Before validity period",
+			disruption: disruption,
+			departure:  &transport.DepartureInfo{LineName: "42", AimedDepartureTime: parse("2020-03-30T06:59:00+01:00")},
+			expected:   false,
+		},
+		{
+			name:       "All lines and times",
+			disruption: transport.Disruption{},
+			departure:  &transport.DepartureInfo{LineName: "X5", AimedDepartureTime: parse("2020-03-30T12:00:00+01:00")},
+			expected:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := test.disruption.Affects(test.departure); result != test.expected {
+				t.Fatalf("Expected %t; got %t", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestGetNextDepartureTimeWithDisruptions(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	stopMonitoring, err := os.ReadFile("../traveline/testdata/stop_monitoring.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	situationExchange, err := os.ReadFile("../traveline/testdata/situation_exchange.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tests := []struct {
+		name                string
+		situationExchange   string
+		expectedDisruptions []string
+	}{
+		{
+			name:                "Disruptions affecting departure",
+			situationExchange:   string(situationExchange),
+			expectedDisruptions: []string{"SIT-1"},
+		},
+		{
+			name: "Disruptions to the whole of the line",
+			situationExchange: `<Siri><ServiceDelivery><SituationExchangeDelivery><Situations>` +
+				`<PtSituationElement><SituationNumber>SIT-42</SituationNumber><Progress>open</Progress><Affects>` +
+				`<Networks><AffectedNetwork><AffectedLine><PublishedLineName>42</PublishedLineName></AffectedLine>` +
+				`</AffectedNetwork></Networks></Affects></PtSituationElement>` +
+				`<PtSituationElement><SituationNumber>SIT-X5</SituationNumber><Progress>open</Progress><Affects>` +
+				`<Networks><AffectedNetwork><AffectedLine><PublishedLineName>X5</PublishedLineName></AffectedLine>` +
+				`</AffectedNetwork></Networks></Affects></PtSituationElement>` +
+				`</Situations></SituationExchangeDelivery></ServiceDelivery></Siri>`,
+			expectedDisruptions: []string{"SIT-42"},
+		},
+		{
+			name: "Disruptions to the line at other stops",
+			situationExchange: `<Siri><ServiceDelivery><SituationExchangeDelivery><Situations>` +
+				`<PtSituationElement><SituationNumber>SIT-42</SituationNumber><Progress>open</Progress><Affects>` +
+				`<Networks><AffectedNetwork><AffectedLine><PublishedLineName>42</PublishedLineName></AffectedLine>` +
+				`</AffectedNetwork></Networks>` +
+				`<StopPoints><AffectedStopPoint><StopPointRef>020035899</StopPointRef></AffectedStopPoint></StopPoints>` +
+				`</Affects></PtSituationElement>` +
+				`</Situations></SituationExchangeDelivery></ServiceDelivery></Siri>`,
+		},
+		{
+			name:              "Departure returned when disruptions cannot be parsed",
+			situationExchange: "<Siri>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := siritest.NewProducer(t).
+				WithAuth("TravelineAPI999", "letmein").
+				Respond("StopMonitoringRequest", http.StatusOK, string(stopMonitoring)).
+				Respond("SituationExchangeRequest", http.StatusOK, test.situationExchange)
+			defer server.Close()
+
+			req := transport.NewTraveline(&traveline.Client{
+				Username: "TravelineAPI999",
+				Password: "letmein",
+				URL:      server.URL,
+				Client:   server.Client(),
+			})
+			req.IncludeDisruptions = true
+
+			result, err := req.GetNextDepartureTime("020035811", when)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if result.LineName != "42" {
+				t.Fatalf("Expected line '42'; got '%s'", result.LineName)
+			}
+
+			var disruptions []string
+			for _, disruption := range result.Disruptions {
+				disruptions = append(disruptions, disruption.SituationNumber)
+			}
+			if diff := cmp.Diff(test.expectedDisruptions, disruptions); diff != "" {
+				t.Errorf("Disruptions (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
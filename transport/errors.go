@@ -1,6 +1,9 @@
 package transport
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // InvalidTimeFoundError indicates that an invalid departure time was found
 type InvalidTimeFoundError struct {
@@ -11,3 +14,39 @@ type InvalidTimeFoundError struct {
 func (e InvalidTimeFoundError) Error() string {
 	return fmt.Sprintf("Invalid departure time \"%s\" found: %s", e.Time, e.Reason)
 }
+
+// NoDeparturesFoundError indicates that no departures can be found for the stop
+type NoDeparturesFoundError struct {
+	NaptanCode string
+}
+
+func (e NoDeparturesFoundError) Error() string {
+	return fmt.Sprintf("No departures found for stop \"%s\"", e.NaptanCode)
+}
+
+// NoArrivalsFoundError indicates that no arrivals can be found for the stop
+type NoArrivalsFoundError struct {
+	NaptanCode string
+}
+
+func (e NoArrivalsFoundError) Error() string {
+	return fmt.Sprintf("No arrivals found for stop \"%s\"", e.NaptanCode)
+}
+
+// TimeoutError indicates that the provider did not respond in time
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("No response from provider after %s", e.Timeout)
+}
+
+// JourneyNotFoundError indicates that the journey is not in the estimated timetable
+type JourneyNotFoundError struct {
+	JourneyRef string
+}
+
+func (e JourneyNotFoundError) Error() string {
+	return fmt.Sprintf("Journey \"%s\" not found", e.JourneyRef)
+}
@@ -2,6 +2,7 @@ package transport_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/conradhodge/travel-api-client/transport"
 )
@@ -18,3 +19,51 @@ func TestInvalidTimeFoundError(t *testing.T) {
 		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
 	}
 }
+
+func TestNoDeparturesFoundError(t *testing.T) {
+	err := transport.NoDeparturesFoundError{
+		NaptanCode: "490008660N",
+	}
+
+	expectedError := "No departures found for stop \"490008660N\""
+
+	if err.Error() != expectedError {
+		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
+	}
+}
+
+func TestNoArrivalsFoundError(t *testing.T) {
+	err := transport.NoArrivalsFoundError{
+		NaptanCode: "490008660N",
+	}
+
+	expectedError := "No arrivals found for stop \"490008660N\""
+
+	if err.Error() != expectedError {
+		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	err := transport.TimeoutError{
+		Timeout: 5 * time.Second,
+	}
+
+	expectedError := "No response from provider after 5s"
+
+	if err.Error() != expectedError {
+		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
+	}
+}
+
+func TestJourneyNotFoundError(t *testing.T) {
+	err := transport.JourneyNotFoundError{
+		JourneyRef: "1234",
+	}
+
+	expectedError := "Journey \"1234\" not found"
+
+	if err.Error() != expectedError {
+		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
+	}
+}
@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"log"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/naptan"
+	"github.com/pkg/errors"
+)
+
+// Fallback is used to make transport requests to a primary real-time provider, falling back to a secondary
+// provider, such as a local timetable, when the primary has no departures, times out or fails
+type Fallback struct {
+	Primary   API
+	Secondary API
+	// Timeout is how long to wait for the primary provider, there is no timeout if zero
+	Timeout time.Duration
+}
+
+// NewFallback returns the implementation of the transport API that falls back from the primary provider
+func NewFallback(primary API, secondary API, timeout time.Duration) *Fallback {
+	return &Fallback{
+		Primary:   primary,
+		Secondary: secondary,
+		Timeout:   timeout,
+	}
+}
+
+// GetNextDepartureTime returns the next departure time at the stop that the NaPTAN code represents.
+// The source of the departure is live if it is from the primary provider and scheduled if it is from
+// the secondary, unless the provider sets it. Invalid stop codes are returned without falling back.
+func (c *Fallback) GetNextDepartureTime(naptanCode string, when time.Time) (*DepartureInfo, error) {
+	departureInfo, err := c.getPrimary(naptanCode, when)
+	if err == nil {
+		if departureInfo.Source == "" {
+			departureInfo.Source = LiveSource
+		}
+		return departureInfo, nil
+	}
+
+	var invalidStopCodeError *naptan.InvalidStopCodeError
+	if errors.As(err, &invalidStopCodeError) {
+		return nil, err
+	}
+
+	log.Printf("Falling back to secondary provider for stop %s: %s", naptanCode, err)
+
+	departureInfo, err = c.Secondary.GetNextDepartureTime(naptanCode, when)
+	if err != nil {
+		return nil, err
+	}
+	if departureInfo.Source == "" {
+		departureInfo.Source = ScheduledSource
+	}
+
+	return departureInfo, nil
+}
+
+// getPrimary returns the next departure from the primary provider, or TimeoutError if it takes too long
+func (c *Fallback) getPrimary(naptanCode string, when time.Time) (*DepartureInfo, error) {
+	if c.Timeout <= 0 {
+		return c.Primary.GetNextDepartureTime(naptanCode, when)
+	}
+
+	type result struct {
+		departureInfo *DepartureInfo
+		err           error
+	}
+
+	// The channel is buffered so that the request can finish after the timeout
+	results := make(chan result, 1)
+	go func() {
+		departureInfo, err := c.Primary.GetNextDepartureTime(naptanCode, when)
+		results <- result{departureInfo: departureInfo, err: err}
+	}()
+
+	timer := time.NewTimer(c.Timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-results:
+		return r.departureInfo, r.err
+	case <-timer.C:
+		return nil, &TimeoutError{Timeout: c.Timeout}
+	}
+}
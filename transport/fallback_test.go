@@ -0,0 +1,168 @@
+package transport_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/mock/mock_transport"
+	"github.com/conradhodge/travel-api-client/naptan"
+	"github.com/conradhodge/travel-api-client/transport"
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFallbackGetNextDepartureTime(t *testing.T) {
+	now := time.Now()
+	aimedDepartureTime, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:00+01:00")
+	expectedDepartureTime, _ := time.Parse(time.RFC3339, "2020-03-30T12:36:00+01:00")
+
+	live := &transport.DepartureInfo{
+		LineName:              "42",
+		AimedDepartureTime:    &aimedDepartureTime,
+		ExpectedDepartureTime: &expectedDepartureTime,
+	}
+	scheduled := &transport.DepartureInfo{
+		LineName:           "42",
+		AimedDepartureTime: &aimedDepartureTime,
+	}
+
+	tests := []struct {
+		name            string
+		primaryResult   *transport.DepartureInfo
+		primaryError    error
+		blockPrimary    bool
+		callSecondary   bool
+		secondaryResult *transport.DepartureInfo
+		secondaryError  error
+		expectedResult  *transport.DepartureInfo
+		expectedError   error
+	}{
+		{
+			name:          "Live departure",
+			primaryResult: live,
+			expectedResult: &transport.DepartureInfo{
+				LineName:              "42",
+				AimedDepartureTime:    &aimedDepartureTime,
+				ExpectedDepartureTime: &expectedDepartureTime,
+				Source:                transport.LiveSource,
+			},
+		},
+		{
+			name:            "No times found",
+			primaryError:    traveline.NoTimesFoundError{},
+			callSecondary:   true,
+			secondaryResult: scheduled,
+			expectedResult: &transport.DepartureInfo{
+				LineName:           "42",
+				AimedDepartureTime: &aimedDepartureTime,
+				Source:             transport.ScheduledSource,
+			},
+		},
+		{
+			name:            "Upstream error",
+			primaryError:    errors.New("error status from API: 503"),
+			callSecondary:   true,
+			secondaryResult: scheduled,
+			expectedResult: &transport.DepartureInfo{
+				LineName:           "42",
+				AimedDepartureTime: &aimedDepartureTime,
+				Source:             transport.ScheduledSource,
+			},
+		},
+		{
+			name:            "Timeout",
+			primaryResult:   live,
+			blockPrimary:    true,
+			callSecondary:   true,
+			secondaryResult: scheduled,
+			expectedResult: &transport.DepartureInfo{
+				LineName:           "42",
+				AimedDepartureTime: &aimedDepartureTime,
+				Source:             transport.ScheduledSource,
+			},
+		},
+		{
+			name:           "Secondary error",
+			primaryError:   traveline.NoTimesFoundError{},
+			callSecondary:  true,
+			secondaryError: &transport.NoDeparturesFoundError{NaptanCode: "020035811"},
+			expectedError:  errors.New(`No departures found for stop "020035811"`),
+		},
+		{
+			name:          "Invalid stop code",
+			primaryError:  &naptan.InvalidStopCodeError{Code: "020", Reason: "ATCO code must be between 4 and 12 characters"},
+			expectedError: errors.New(`Invalid stop code "020": ATCO code must be between 4 and 12 characters`),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Copy the results as the source is set on them
+			var primaryResult, secondaryResult *transport.DepartureInfo
+			if test.primaryResult != nil {
+				result := *test.primaryResult
+				primaryResult = &result
+			}
+			if test.secondaryResult != nil {
+				result := *test.secondaryResult
+				secondaryResult = &result
+			}
+
+			// A blocked primary waits until the test has its result, and the test waits for the primary
+			// to return so that it doesn't outlive the subtest
+			release := make(chan struct{})
+			returned := make(chan struct{})
+			defer func() {
+				close(release)
+				<-returned
+			}()
+
+			mockPrimary := mock_transport.NewMockAPI(ctrl)
+			mockPrimary.
+				EXPECT().
+				GetNextDepartureTime(gomock.Eq("020035811"), gomock.Eq(now)).
+				DoAndReturn(func(string, time.Time) (*transport.DepartureInfo, error) {
+					defer close(returned)
+					if test.blockPrimary {
+						<-release
+					}
+					return primaryResult, test.primaryError
+				})
+
+			mockSecondary := mock_transport.NewMockAPI(ctrl)
+			if test.callSecondary {
+				mockSecondary.
+					EXPECT().
+					GetNextDepartureTime(gomock.Eq("020035811"), gomock.Eq(now)).
+					Return(secondaryResult, test.secondaryError)
+			}
+
+			req := transport.NewFallback(mockPrimary, mockSecondary, 50*time.Millisecond)
+
+			result, err := req.GetNextDepartureTime("020035811", now)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+				t.Errorf("GetNextDepartureTime() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/conradhodge/travel-api-client/gtfs"
+	"github.com/conradhodge/travel-api-client/naptan"
+)
+
+// GTFS is used to make transport requests using a GTFS static timetable,
+// the stop IDs or codes in the timetable must be ATCO or NaPTAN codes as they are in UK feeds
+type GTFS struct {
+	Feed *gtfs.Feed
+}
+
+// NewGTFS returns the implementation of the transport API using a GTFS static timetable
+func NewGTFS(feed *gtfs.Feed) *GTFS {
+	return &GTFS{Feed: feed}
+}
+
+// GetNextDepartureTime returns the next scheduled departure time at the stop that the NaPTAN code represents.
+// Only the aimed departure time is set, as the timetable has no real-time information.
+func (c *GTFS) GetNextDepartureTime(naptanCode string, when time.Time) (*DepartureInfo, error) {
+	code, err := naptan.ValidateCode(naptanCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := c.Feed.FindStop(code); !ok {
+		return nil, &naptan.InvalidStopCodeError{
+			Code:   naptanCode,
+			Reason: "stop not found",
+		}
+	}
+
+	departures := c.Feed.Departures(code, when, 1)
+	if len(departures) == 0 {
+		return nil, &NoDeparturesFoundError{NaptanCode: naptanCode}
+	}
+
+	departure := departures[0]
+	nextDepartureInfo := DepartureInfo{
+		DirectionName:      departure.Trip.Headsign,
+		AimedDepartureTime: &departure.Time,
+		Source:             ScheduledSource,
+		JourneyRef:         departure.Trip.ID,
+	}
+	if departure.Route != nil {
+		nextDepartureInfo.VehicleMode = departure.Route.Mode()
+		nextDepartureInfo.LineName = departure.Route.Name()
+	}
+
+	return &nextDepartureInfo, nil
+}
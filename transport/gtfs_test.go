@@ -0,0 +1,100 @@
+package transport_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/gtfs"
+	"github.com/conradhodge/travel-api-client/transport"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGTFSGetNextDepartureTime(t *testing.T) {
+	feed, err := gtfs.Load("../gtfs/testdata/feed")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	parse := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &parsed
+	}
+
+	tests := []struct {
+		name           string
+		naptanCode     string
+		when           time.Time
+		expectedError  error
+		expectedResult *transport.DepartureInfo
+	}{
+		{
+			name:       "Next scheduled departure",
+			naptanCode: "020035811",
+			when:       *parse("2020-03-30T12:00:00+01:00"),
+			expectedResult: &transport.DepartureInfo{
+				VehicleMode:        "bus",
+				LineName:           "42",
+				DirectionName:      "Toddington, The Green",
+				AimedDepartureTime: parse("2020-03-30T12:09:00+01:00"),
+				Source:             transport.ScheduledSource,
+				JourneyRef:         "T42_2",
+			},
+		},
+		{
+			name:       "Route long name used without short name",
+			naptanCode: "bedadgmt",
+			when:       *parse("2020-03-30T12:10:00+01:00"),
+			expectedResult: &transport.DepartureInfo{
+				VehicleMode:        "coach",
+				LineName:           "Bedford Express",
+				DirectionName:      "Bedford",
+				AimedDepartureTime: parse("2020-03-30T12:16:00+01:00"),
+				Source:             transport.ScheduledSource,
+				JourneyRef:         "TX5_1",
+			},
+		},
+		{
+			name:          "No departures",
+			naptanCode:    "020035812",
+			when:          *parse("2020-03-30T12:00:00+01:00"),
+			expectedError: errors.New(`No departures found for stop "020035812"`),
+		},
+		{
+			name:          "Stop not in timetable",
+			naptanCode:    "020000000",
+			when:          *parse("2020-03-30T12:00:00+01:00"),
+			expectedError: errors.New(`Invalid stop code "020000000": stop not found`),
+		},
+		{
+			name:          "Invalid stop code",
+			naptanCode:    "The-Green",
+			when:          *parse("2020-03-30T12:00:00+01:00"),
+			expectedError: errors.New(`Invalid stop code "The-Green": invalid character '-'`),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := transport.NewGTFS(feed)
+
+			result, err := req.GetNextDepartureTime(test.naptanCode, test.when)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+				t.Errorf("GetNextDepartureTime() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
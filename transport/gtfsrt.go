@@ -0,0 +1,151 @@
+package transport
+
+import (
+	"strings"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/gtfs"
+	"github.com/conradhodge/travel-api-client/gtfsrt"
+	"github.com/conradhodge/travel-api-client/naptan"
+)
+
+// GTFSRealtime is used to make transport requests using a GTFS-Realtime TripUpdates feed,
+// the stop IDs in the feed must be ATCO codes as they are in UK feeds
+type GTFSRealtime struct {
+	API gtfsrt.API
+	// Schedule, if set, is the static timetable the feed's trips are from. It gives the aimed times,
+	// the predicted times of updates that only have a delay, and the names of the routes.
+	Schedule *gtfs.Feed
+}
+
+// NewGTFSRealtime returns the implementation of the transport API using a GTFS-Realtime feed
+func NewGTFSRealtime(api gtfsrt.API) *GTFSRealtime {
+	return &GTFSRealtime{API: api}
+}
+
+// GetNextDepartureTime returns the next departure time at the stop that the NaPTAN code represents.
+// Cancelled trips and skipped stops are ignored, as are stop time updates without a predicted time
+// unless the schedule is set to apply their delay to. Delays are not propagated to later stops
+// without an update, and without the schedule the line name is the route ID in the feed.
+func (c *GTFSRealtime) GetNextDepartureTime(naptanCode string, when time.Time) (*DepartureInfo, error) {
+	code, err := naptan.ValidateCode(naptanCode)
+	if err != nil {
+		return nil, err
+	}
+
+	feed, err := c.API.GetFeed()
+	if err != nil {
+		return nil, err
+	}
+
+	var next *DepartureInfo
+	for _, entity := range feed.Entities {
+		if entity.IsDeleted || entity.TripUpdate == nil {
+			continue
+		}
+
+		for _, departure := range c.tripDepartures(entity.TripUpdate, code, when) {
+			if departure.ExpectedDepartureTime.Before(when) {
+				continue
+			}
+			if next == nil || departure.ExpectedDepartureTime.Before(*next.ExpectedDepartureTime) {
+				departure := departure
+				next = &departure
+			}
+		}
+	}
+
+	if next == nil {
+		return nil, &NoDeparturesFoundError{NaptanCode: naptanCode}
+	}
+
+	return next, nil
+}
+
+// tripDepartures returns the departures of the trip from the stop
+func (c *GTFSRealtime) tripDepartures(update *gtfsrt.TripUpdate, stopID string, when time.Time) []DepartureInfo {
+	switch update.Trip.ScheduleRelationship {
+	case gtfsrt.TripCanceled, gtfsrt.TripDeleted:
+		return nil
+	}
+
+	var departures []DepartureInfo
+	for _, stopTimeUpdate := range update.StopTimeUpdates {
+		if !strings.EqualFold(stopTimeUpdate.StopID, stopID) {
+			continue
+		}
+		switch stopTimeUpdate.ScheduleRelationship {
+		case gtfsrt.StopSkipped, gtfsrt.StopNoData:
+			continue
+		}
+
+		// The arrival is used when the departure is not predicted, e.g. at the last stop
+		event := stopTimeUpdate.Departure
+		if event == nil {
+			event = stopTimeUpdate.Arrival
+		}
+		if event == nil {
+			continue
+		}
+
+		// The delay of the trip applies if the stop does not have its own
+		delay := event.Delay
+		if delay == nil {
+			delay = update.Delay
+		}
+
+		departure := DepartureInfo{
+			LineName:   update.Trip.RouteID,
+			Source:     LiveSource,
+			JourneyRef: update.Trip.TripID,
+		}
+		if update.Timestamp > 0 {
+			recordedAt := time.Unix(int64(update.Timestamp), 0)
+			departure.RecordedAt = &recordedAt
+		}
+
+		scheduled, ok := c.scheduledDeparture(update.Trip, stopID, when)
+		if ok {
+			departure.AimedDepartureTime = &scheduled.Time
+			departure.DirectionName = scheduled.Trip.Headsign
+			if scheduled.Route != nil {
+				departure.LineName = scheduled.Route.Name()
+				departure.VehicleMode = scheduled.Route.Mode()
+			}
+		}
+
+		switch {
+		case event.Time != nil:
+			expectedDepartureTime := time.Unix(*event.Time, 0)
+			departure.ExpectedDepartureTime = &expectedDepartureTime
+			if !ok && delay != nil {
+				aimedDepartureTime := expectedDepartureTime.Add(-time.Duration(*delay) * time.Second)
+				departure.AimedDepartureTime = &aimedDepartureTime
+			}
+		case ok && delay != nil:
+			expectedDepartureTime := scheduled.Time.Add(time.Duration(*delay) * time.Second)
+			departure.ExpectedDepartureTime = &expectedDepartureTime
+		default:
+			continue
+		}
+
+		departures = append(departures, departure)
+	}
+
+	return departures
+}
+
+// scheduledDeparture returns the departure of the trip from the stop in the schedule, on the start date
+// of the trip or the date of the time if the trip doesn't have one
+func (c *GTFSRealtime) scheduledDeparture(trip gtfsrt.TripDescriptor, stopID string, when time.Time) (gtfs.Departure, bool) {
+	if c.Schedule == nil {
+		return gtfs.Departure{}, false
+	}
+
+	serviceDate := trip.StartDate
+	if serviceDate == "" {
+		serviceDate = when.In(c.Schedule.Location).Format("20060102")
+	}
+
+	return c.Schedule.ScheduledDeparture(trip.TripID, stopID, serviceDate)
+}
@@ -0,0 +1,263 @@
+package transport_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/gtfs"
+	"github.com/conradhodge/travel-api-client/gtfsrt"
+	"github.com/conradhodge/travel-api-client/transport"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGTFSRealtimeGetNextDepartureTime(t *testing.T) {
+	parse := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &parsed
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		naptanCode     string
+		when           time.Time
+		expectedError  error
+		expectedResult *transport.DepartureInfo
+	}{
+		{
+			name:       "Aimed time from trip delay",
+			path:       "../gtfsrt/testdata/tripupdates.pb",
+			naptanCode: "020035811",
+			when:       *parse("2020-03-30T12:00:00+01:00"),
+			expectedResult: &transport.DepartureInfo{
+				LineName:              "42",
+				AimedDepartureTime:    parse("2020-03-30T12:06:00+01:00"),
+				ExpectedDepartureTime: parse("2020-03-30T12:05:00+01:00"),
+				Source:                transport.LiveSource,
+				JourneyRef:            "VJ_42_2",
+			},
+		},
+		{
+			name:       "Aimed time from stop delay",
+			path:       "../gtfsrt/testdata/tripupdates.pb",
+			naptanCode: "020035811",
+			when:       *parse("2020-03-30T12:10:00+01:00"),
+			expectedResult: &transport.DepartureInfo{
+				LineName:              "42",
+				AimedDepartureTime:    parse("2020-03-30T12:09:00+01:00"),
+				ExpectedDepartureTime: parse("2020-03-30T12:12:00+01:00"),
+				Source:                transport.LiveSource,
+				JourneyRef:            "VJ_42_1",
+				RecordedAt:            parse("2020-03-30T10:59:30Z"),
+			},
+		},
+		{
+			name:       "Arrival used at last stop",
+			path:       "../gtfsrt/testdata/tripupdates.pb",
+			naptanCode: "020035812",
+			when:       *parse("2020-03-30T12:00:00+01:00"),
+			expectedResult: &transport.DepartureInfo{
+				LineName:              "42",
+				AimedDepartureTime:    parse("2020-03-30T12:18:00+01:00"),
+				ExpectedDepartureTime: parse("2020-03-30T12:20:00+01:00"),
+				Source:                transport.LiveSource,
+				JourneyRef:            "VJ_42_1",
+				RecordedAt:            parse("2020-03-30T10:59:30Z"),
+			},
+		},
+		{
+			name:          "No departures after time",
+			path:          "../gtfsrt/testdata/tripupdates.pb",
+			naptanCode:    "020035811",
+			when:          *parse("2020-03-30T12:30:00+01:00"),
+			expectedError: errors.New(`No departures found for stop "020035811"`),
+		},
+		{
+			name:          "Invalid feed",
+			path:          "../gtfsrt/testdata/truncated.pb",
+			naptanCode:    "020035811",
+			when:          *parse("2020-03-30T12:00:00+01:00"),
+			expectedError: errors.New("cannot decode GTFS-Realtime feed: unexpected EOF"),
+		},
+		{
+			name:          "Invalid stop code",
+			path:          "../gtfsrt/testdata/tripupdates.pb",
+			naptanCode:    "",
+			when:          *parse("2020-03-30T12:00:00+01:00"),
+			expectedError: errors.New(`Invalid stop code "": code is empty`),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := transport.NewGTFSRealtime(gtfsrt.NewFile(test.path))
+
+			result, err := req.GetNextDepartureTime(test.naptanCode, test.when)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+				t.Errorf("GetNextDepartureTime() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// decodedFeed is a GTFS-Realtime feed that is already decoded
+type decodedFeed struct {
+	feed *gtfsrt.FeedMessage
+}
+
+func (f decodedFeed) GetFeed() (*gtfsrt.FeedMessage, error) {
+	return f.feed, nil
+}
+
+func TestGTFSRealtimeGetNextDepartureTimeWithSchedule(t *testing.T) {
+	schedule, err := gtfs.Load("../gtfs/testdata/feed")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	parse := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &parsed
+	}
+	seconds := func(value int32) *int32 {
+		return &value
+	}
+	when := *parse("2020-03-30T12:00:00+01:00")
+	predicted := parse("2020-03-30T12:15:00+01:00").Unix()
+
+	tests := []struct {
+		name           string
+		schedule       *gtfs.Feed
+		update         gtfsrt.TripUpdate
+		expectedResult *transport.DepartureInfo
+	}{
+		{
+			name:     "Stop delay applied to the schedule",
+			schedule: schedule,
+			update: gtfsrt.TripUpdate{
+				Trip: gtfsrt.TripDescriptor{TripID: "T42_2", RouteID: "R42", StartDate: "20200330"},
+				StopTimeUpdates: []gtfsrt.StopTimeUpdate{
+					{StopID: "020035811", Departure: &gtfsrt.StopTimeEvent{Delay: seconds(180)}},
+				},
+			},
+			expectedResult: &transport.DepartureInfo{
+				VehicleMode:           "bus",
+				LineName:              "42",
+				DirectionName:         "Toddington, The Green",
+				AimedDepartureTime:    parse("2020-03-30T12:09:00+01:00"),
+				ExpectedDepartureTime: parse("2020-03-30T12:12:00+01:00"),
+				Source:                transport.LiveSource,
+				JourneyRef:            "T42_2",
+			},
+		},
+		{
+			name:     "Trip delay applied to the schedule on the date of the time",
+			schedule: schedule,
+			update: gtfsrt.TripUpdate{
+				Trip:            gtfsrt.TripDescriptor{TripID: "T42_2", RouteID: "R42"},
+				StopTimeUpdates: []gtfsrt.StopTimeUpdate{{StopID: "020035811", Departure: &gtfsrt.StopTimeEvent{}}},
+				Delay:           seconds(-60),
+			},
+			expectedResult: &transport.DepartureInfo{
+				VehicleMode:           "bus",
+				LineName:              "42",
+				DirectionName:         "Toddington, The Green",
+				AimedDepartureTime:    parse("2020-03-30T12:09:00+01:00"),
+				ExpectedDepartureTime: parse("2020-03-30T12:08:00+01:00"),
+				Source:                transport.LiveSource,
+				JourneyRef:            "T42_2",
+			},
+		},
+		{
+			name:     "Aimed time from the schedule",
+			schedule: schedule,
+			update: gtfsrt.TripUpdate{
+				Trip: gtfsrt.TripDescriptor{TripID: "T42_2", RouteID: "R42", StartDate: "20200330"},
+				StopTimeUpdates: []gtfsrt.StopTimeUpdate{
+					{StopID: "020035811", Departure: &gtfsrt.StopTimeEvent{Time: &predicted}},
+				},
+			},
+			expectedResult: &transport.DepartureInfo{
+				VehicleMode:           "bus",
+				LineName:              "42",
+				DirectionName:         "Toddington, The Green",
+				AimedDepartureTime:    parse("2020-03-30T12:09:00+01:00"),
+				ExpectedDepartureTime: parse("2020-03-30T12:15:00+01:00"),
+				Source:                transport.LiveSource,
+				JourneyRef:            "T42_2",
+			},
+		},
+		{
+			name: "Delay without the schedule is ignored",
+			update: gtfsrt.TripUpdate{
+				Trip: gtfsrt.TripDescriptor{TripID: "T42_2", RouteID: "R42", StartDate: "20200330"},
+				StopTimeUpdates: []gtfsrt.StopTimeUpdate{
+					{StopID: "020035811", Departure: &gtfsrt.StopTimeEvent{Delay: seconds(180)}},
+				},
+			},
+		},
+		{
+			name:     "Delay of a trip not in the schedule is ignored",
+			schedule: schedule,
+			update: gtfsrt.TripUpdate{
+				Trip: gtfsrt.TripDescriptor{TripID: "T99", RouteID: "R99", StartDate: "20200330"},
+				StopTimeUpdates: []gtfsrt.StopTimeUpdate{
+					{StopID: "020035811", Departure: &gtfsrt.StopTimeEvent{Delay: seconds(180)}},
+				},
+			},
+		},
+		{
+			name: "Route ID without the schedule",
+			update: gtfsrt.TripUpdate{
+				Trip: gtfsrt.TripDescriptor{TripID: "T42_2", RouteID: "R42", StartDate: "20200330"},
+				StopTimeUpdates: []gtfsrt.StopTimeUpdate{
+					{StopID: "020035811", Departure: &gtfsrt.StopTimeEvent{Time: &predicted}},
+				},
+			},
+			expectedResult: &transport.DepartureInfo{
+				LineName:              "R42",
+				ExpectedDepartureTime: parse("2020-03-30T12:15:00+01:00"),
+				Source:                transport.LiveSource,
+				JourneyRef:            "T42_2",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := transport.NewGTFSRealtime(decodedFeed{feed: &gtfsrt.FeedMessage{
+				Entities: []gtfsrt.FeedEntity{{ID: "1", TripUpdate: &test.update}},
+			}})
+			req.Schedule = test.schedule
+
+			result, err := req.GetNextDepartureTime("020035811", when)
+
+			if test.expectedResult == nil {
+				expectedError := `No departures found for stop "020035811"`
+				if err == nil || err.Error() != expectedError {
+					t.Fatalf("Expected error '%s'; got '%v'", expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+				t.Errorf("GetNextDepartureTime() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package transport
+
+import (
+	"sort"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/uuid"
+)
+
+// JourneyPrediction represents the predicted times of the stops that a journey is still to call at,
+// and the actual times of those it has already called at
+type JourneyPrediction struct {
+	JourneyRef   string
+	LineName     string
+	DirectionRef string
+	OperatorRef  string
+	VehicleRef   string
+	// Calls are the stops of the journey in the order they are called at
+	Calls []PredictedCall
+}
+
+// PredictedCall represents the aimed and expected times at a stop of a journey,
+// times are nil if not published, e.g. there is no departure from the last stop
+type PredictedCall struct {
+	StopPointRef          string
+	StopPointName         string
+	Order                 int
+	AimedArrivalTime      *time.Time
+	ExpectedArrivalTime   *time.Time
+	AimedDepartureTime    *time.Time
+	ExpectedDepartureTime *time.Time
+	// ActualArrivalTime and ActualDepartureTime are set for stops that have already been called at
+	ActualArrivalTime   *time.Time
+	ActualDepartureTime *time.Time
+}
+
+// callTime is a time of a call to convert and where to store it
+type callTime struct {
+	value  string
+	target **time.Time
+}
+
+// convertCallTimes converts the times of a call that are published
+func convertCallTimes(times ...callTime) error {
+	for _, t := range times {
+		if t.value == "" {
+			continue
+		}
+		converted, err := convertDepartureTime(t.value)
+		if err != nil {
+			return err
+		}
+		*t.target = &converted
+	}
+
+	return nil
+}
+
+// GetJourneyPredictions returns the predicted times for every remaining stop of the journey on the line
+// and the actual times for the stops already called at,
+// the line ref is used to limit the journeys requested and can be empty to request all lines
+func (c *Traveline) GetJourneyPredictions(journeyRef string, lineRef string, when time.Time) (*JourneyPrediction, error) {
+	if err := c.checkLine(lineRef, when); err != nil {
+		return nil, err
+	}
+
+	requestRef := uuid.New().String()
+	request, err := c.API.BuildEstimatedTimetableRequest(requestRef, lineRef, when)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.API.Send(request)
+	if err != nil {
+		return nil, err
+	}
+
+	journeys, err := c.API.ParseEstimatedTimetableDelivery(requestRef, response)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, journey := range journeys {
+		if journey.JourneyRef() == journeyRef {
+			return convertEstimatedJourney(journey)
+		}
+	}
+
+	return nil, &JourneyNotFoundError{JourneyRef: journeyRef}
+}
+
+func convertEstimatedJourney(journey traveline.EstimatedVehicleJourney) (*JourneyPrediction, error) {
+	prediction := JourneyPrediction{
+		JourneyRef:   journey.JourneyRef(),
+		LineName:     journey.PublishedLineName,
+		DirectionRef: journey.DirectionRef,
+		OperatorRef:  journey.OperatorRef,
+		VehicleRef:   journey.VehicleRef,
+		Calls:        []PredictedCall{},
+	}
+	if prediction.LineName == "" {
+		prediction.LineName = journey.LineRef
+	}
+
+	for _, recordedCall := range journey.RecordedCalls {
+		call := PredictedCall{
+			StopPointRef:  recordedCall.StopPointRef,
+			StopPointName: recordedCall.StopPointName,
+			Order:         recordedCall.Order,
+		}
+
+		err := convertCallTimes(
+			callTime{value: recordedCall.AimedArrivalTime, target: &call.AimedArrivalTime},
+			callTime{value: recordedCall.ActualArrivalTime, target: &call.ActualArrivalTime},
+			callTime{value: recordedCall.AimedDepartureTime, target: &call.AimedDepartureTime},
+			callTime{value: recordedCall.ActualDepartureTime, target: &call.ActualDepartureTime},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		prediction.Calls = append(prediction.Calls, call)
+	}
+
+	for _, estimatedCall := range journey.EstimatedCalls {
+		call := PredictedCall{
+			StopPointRef:  estimatedCall.StopPointRef,
+			StopPointName: estimatedCall.StopPointName,
+			Order:         estimatedCall.Order,
+		}
+
+		err := convertCallTimes(
+			callTime{value: estimatedCall.AimedArrivalTime, target: &call.AimedArrivalTime},
+			callTime{value: estimatedCall.ExpectedArrivalTime, target: &call.ExpectedArrivalTime},
+			callTime{value: estimatedCall.AimedDepartureTime, target: &call.AimedDepartureTime},
+			callTime{value: estimatedCall.ExpectedDepartureTime, target: &call.ExpectedDepartureTime},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		prediction.Calls = append(prediction.Calls, call)
+	}
+
+	// Producers don't always list the calls in order
+	sort.SliceStable(prediction.Calls, func(i, j int) bool {
+		return prediction.Calls[i].Order < prediction.Calls[j].Order
+	})
+
+	return &prediction, nil
+}
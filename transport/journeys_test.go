@@ -0,0 +1,179 @@
+package transport_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/internal/siritest"
+	"github.com/conradhodge/travel-api-client/transport"
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetJourneyPredictions(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	response, err := os.ReadFile("../traveline/testdata/estimated_timetable.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	parse := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &parsed
+	}
+
+	tests := []struct {
+		name           string
+		journeyRef     string
+		statusCode     int
+		response       string
+		expectedError  error
+		expectedResult *transport.JourneyPrediction
+	}{
+		{
+			name:       "Remaining stops of journey",
+			journeyRef: "1234",
+			statusCode: http.StatusOK,
+			response:   string(response),
+			expectedResult: &transport.JourneyPrediction{
+				JourneyRef:   "1234",
+				LineName:     "42",
+				DirectionRef: "outbound",
+				OperatorRef:  "GLBE",
+				VehicleRef:   "GLBE-1001",
+				Calls: []transport.PredictedCall{
+					{
+						StopPointRef:        "0200BDA00101",
+						StopPointName:       "Bedford Bus Station",
+						Order:               1,
+						AimedDepartureTime:  parse("2020-03-30T12:00:00+01:00"),
+						ActualDepartureTime: parse("2020-03-30T12:02:00+01:00"),
+					},
+					{
+						StopPointRef:          "020035811",
+						StopPointName:         "The Green",
+						Order:                 2,
+						AimedArrivalTime:      parse("2020-03-30T12:40:00+01:00"),
+						ExpectedArrivalTime:   parse("2020-03-30T12:42:00+01:00"),
+						AimedDepartureTime:    parse("2020-03-30T12:40:00+01:00"),
+						ExpectedDepartureTime: parse("2020-03-30T12:43:00+01:00"),
+					},
+					{
+						StopPointRef:        "020035999",
+						StopPointName:       "High St",
+						Order:               3,
+						AimedArrivalTime:    parse("2020-03-30T12:55:00+01:00"),
+						ExpectedArrivalTime: parse("2020-03-30T12:57:00+01:00"),
+					},
+				},
+			},
+		},
+		{
+			name:       "Journey identified by dated vehicle journey code",
+			journeyRef: "5678",
+			statusCode: http.StatusOK,
+			response:   string(response),
+			expectedResult: &transport.JourneyPrediction{
+				JourneyRef:   "5678",
+				LineName:     "GLBE:X5",
+				DirectionRef: "inbound",
+				OperatorRef:  "GLBE",
+				Calls: []transport.PredictedCall{
+					{
+						StopPointRef:     "0200BDA00101",
+						Order:            5,
+						AimedArrivalTime: parse("2020-03-30T13:10:00+01:00"),
+					},
+				},
+			},
+		},
+		{
+			name:       "Calls in the order they are called at",
+			journeyRef: "1234",
+			statusCode: http.StatusOK,
+			response: `<Siri><ServiceDelivery><EstimatedTimetableDelivery><EstimatedJourneyVersionFrame>` +
+				`<EstimatedVehicleJourney><DatedVehicleJourneyCode>1234</DatedVehicleJourneyCode>` +
+				`<RecordedCalls><RecordedCall><StopPointRef>0200BDA00101</StopPointRef><Order>2</Order></RecordedCall>` +
+				`<RecordedCall><StopPointRef>020035999</StopPointRef><Order>1</Order></RecordedCall></RecordedCalls>` +
+				`<EstimatedCalls><EstimatedCall><StopPointRef>020035812</StopPointRef><VisitNumber>4</VisitNumber></EstimatedCall>` +
+				`<EstimatedCall><StopPointRef>020035811</StopPointRef><VisitNumber>3</VisitNumber></EstimatedCall></EstimatedCalls>` +
+				`</EstimatedVehicleJourney>` +
+				`</EstimatedJourneyVersionFrame></EstimatedTimetableDelivery></ServiceDelivery></Siri>`,
+			expectedResult: &transport.JourneyPrediction{
+				JourneyRef: "1234",
+				Calls: []transport.PredictedCall{
+					{StopPointRef: "020035999", Order: 1},
+					{StopPointRef: "0200BDA00101", Order: 2},
+					{StopPointRef: "020035811", Order: 3},
+					{StopPointRef: "020035812", Order: 4},
+				},
+			},
+		},
+		{
+			name:          "Journey not found",
+			journeyRef:    "9999",
+			statusCode:    http.StatusOK,
+			response:      string(response),
+			expectedError: errors.New(`Journey "9999" not found`),
+		},
+		{
+			name:       "Invalid expected time",
+			journeyRef: "1234",
+			statusCode: http.StatusOK,
+			response: `<Siri><ServiceDelivery><EstimatedTimetableDelivery><EstimatedJourneyVersionFrame>` +
+				`<EstimatedVehicleJourney><DatedVehicleJourneyCode>1234</DatedVehicleJourneyCode><EstimatedCalls>` +
+				`<EstimatedCall><ExpectedArrivalTime>soon</ExpectedArrivalTime></EstimatedCall>` +
+				`</EstimatedCalls></EstimatedVehicleJourney>` +
+				`</EstimatedJourneyVersionFrame></EstimatedTimetableDelivery></ServiceDelivery></Siri>`,
+			expectedError: &transport.InvalidTimeFoundError{
+				Time:   "soon",
+				Reason: `parsing time "soon" as "2006-01-02T15:04:05Z07:00": cannot parse "soon" as "2006"`,
+			},
+		},
+		{
+			name:          "Error status",
+			journeyRef:    "1234",
+			statusCode:    http.StatusUnauthorized,
+			response:      "Invalid user credentials",
+			expectedError: errors.New("error status from API: 401"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := siritest.NewProducer(t).
+				WithAuth("TravelineAPI999", "letmein").
+				Respond("EstimatedTimetableRequest", test.statusCode, test.response)
+			defer server.Close()
+
+			req := transport.NewTraveline(&traveline.Client{
+				Username: "TravelineAPI999",
+				Password: "letmein",
+				URL:      server.URL,
+				Client:   server.Client(),
+			})
+
+			result, err := req.GetJourneyPredictions(test.journeyRef, "", when)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+				t.Errorf("GetJourneyPredictions() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package transport
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider is a transport API with a name that is recorded against the departures it contributes to
+type Provider struct {
+	Name string
+	API  API
+}
+
+// Merge is used to make transport requests to several providers that cover the same stops,
+// merging the departures that are for the same journey
+type Merge struct {
+	Providers []Provider
+}
+
+// NewMerge returns the implementation of the transport API that merges the departures from the providers
+func NewMerge(providers ...Provider) *Merge {
+	return &Merge{Providers: providers}
+}
+
+// GetNextDepartureTime returns the next departure time at the stop that the NaPTAN code represents.
+// The providers are requested concurrently and the departures for the same journey are merged,
+// preferring the freshest real-time prediction. If every provider fails, the error from the first is returned.
+func (c *Merge) GetNextDepartureTime(naptanCode string, when time.Time) (*DepartureInfo, error) {
+	departures := make([]*DepartureInfo, len(c.Providers))
+	errs := make([]error, len(c.Providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range c.Providers {
+		wg.Add(1)
+		go func(i int, provider Provider) {
+			defer wg.Done()
+			departures[i], errs[i] = provider.API.GetNextDepartureTime(naptanCode, when)
+		}(i, provider)
+	}
+	wg.Wait()
+
+	// Departures for the same journey are grouped, in the order of the providers
+	var groups [][]int
+	for i, departure := range departures {
+		// A departure without a time can't be compared with the others
+		if errs[i] != nil || departure == nil || departureTime(departure).IsZero() {
+			continue
+		}
+
+		matched := false
+		for g, group := range groups {
+			if sameJourney(departures[group[0]], departure) {
+				groups[g] = append(group, i)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			groups = append(groups, []int{i})
+		}
+	}
+
+	if len(groups) == 0 {
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, &NoDeparturesFoundError{NaptanCode: naptanCode}
+	}
+
+	var next *DepartureInfo
+	for _, group := range groups {
+		merged := c.merge(departures, group)
+		if next == nil || departureTime(merged).Before(departureTime(next)) {
+			next = merged
+		}
+	}
+
+	return next, nil
+}
+
+// merge returns the best departure of the group, with the details the others have that it doesn't
+func (c *Merge) merge(departures []*DepartureInfo, group []int) *DepartureInfo {
+	best := departures[group[0]]
+	for _, i := range group[1:] {
+		if fresher(departures[i], best) {
+			best = departures[i]
+		}
+	}
+
+	merged := *best
+	merged.Sources = nil
+	for _, i := range group {
+		departure := departures[i]
+		merged.Sources = append(merged.Sources, c.Providers[i].Name)
+
+		if merged.AimedDepartureTime == nil {
+			merged.AimedDepartureTime = departure.AimedDepartureTime
+		}
+		if merged.VehicleMode == "" {
+			merged.VehicleMode = departure.VehicleMode
+		}
+		if merged.DirectionName == "" {
+			merged.DirectionName = departure.DirectionName
+		}
+		if merged.JourneyRef == "" {
+			merged.JourneyRef = departure.JourneyRef
+		}
+		if merged.Status == "" {
+			merged.Status = departure.Status
+		}
+		if merged.Occupancy == "" {
+			merged.Occupancy = departure.Occupancy
+		}
+		if merged.OnwardCalls == nil {
+			merged.PreviousCalls = departure.PreviousCalls
+			merged.OnwardCalls = departure.OnwardCalls
+		}
+		if merged.VehicleFeatures == nil {
+			merged.VehicleFeatures = departure.VehicleFeatures
+		}
+		if merged.WheelchairSpaces == nil {
+			merged.WheelchairSpaces = departure.WheelchairSpaces
+		}
+		// A provider that doesn't publish the vehicle's accessibility leaves these false
+		merged.LowFloor = merged.LowFloor || departure.LowFloor
+		merged.WheelchairAccessible = merged.WheelchairAccessible || departure.WheelchairAccessible
+	}
+	merged.Disruptions = mergeDisruptions(departures, group)
+
+	return &merged
+}
+
+// mergeDisruptions returns the disruptions from each departure of the group, those that more than one
+// provider publishes are only included once
+func mergeDisruptions(departures []*DepartureInfo, group []int) []Disruption {
+	var disruptions []Disruption
+	seen := make(map[string]bool)
+	for _, i := range group {
+		for _, disruption := range departures[i].Disruptions {
+			if disruption.SituationNumber != "" && seen[disruption.SituationNumber] {
+				continue
+			}
+			seen[disruption.SituationNumber] = true
+			disruptions = append(disruptions, disruption)
+		}
+	}
+
+	return disruptions
+}
+
+// sameJourney returns whether the departures are for the same journey, either by journey ref
+// or by line, direction and aimed departure time
+func sameJourney(a *DepartureInfo, b *DepartureInfo) bool {
+	if a.JourneyRef != "" && a.JourneyRef == b.JourneyRef {
+		return true
+	}
+
+	return strings.EqualFold(a.LineName, b.LineName) &&
+		strings.EqualFold(a.DirectionName, b.DirectionName) &&
+		a.AimedDepartureTime != nil &&
+		b.AimedDepartureTime != nil &&
+		a.AimedDepartureTime.Equal(*b.AimedDepartureTime)
+}
+
+// fresher returns whether departure a is a better prediction than departure b, a real-time prediction is
+// better than a scheduled time and a more recent prediction is better than an older one
+func fresher(a *DepartureInfo, b *DepartureInfo) bool {
+	if (a.ExpectedDepartureTime != nil) != (b.ExpectedDepartureTime != nil) {
+		return a.ExpectedDepartureTime != nil
+	}
+	if a.RecordedAt == nil {
+		return false
+	}
+
+	return b.RecordedAt == nil || a.RecordedAt.After(*b.RecordedAt)
+}
+
+// departureTime returns the expected departure time, or the aimed departure time if there is no prediction,
+// or the zero time if the departure has neither
+func departureTime(departure *DepartureInfo) time.Time {
+	if departure.ExpectedDepartureTime != nil {
+		return *departure.ExpectedDepartureTime
+	}
+	if departure.AimedDepartureTime != nil {
+		return *departure.AimedDepartureTime
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,240 @@
+package transport_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/mock/mock_transport"
+	"github.com/conradhodge/travel-api-client/transport"
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMergeGetNextDepartureTime(t *testing.T) {
+	now := time.Now()
+
+	parse := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &parsed
+	}
+
+	wheelchairSpaces := 1
+
+	type response struct {
+		result *transport.DepartureInfo
+		err    error
+	}
+
+	tests := []struct {
+		name           string
+		responses      []response
+		expectedError  error
+		expectedResult *transport.DepartureInfo
+	}{
+		{
+			name: "Same journey by line, direction and aimed time",
+			responses: []response{
+				{result: &transport.DepartureInfo{
+					VehicleMode:           "bus",
+					LineName:              "42",
+					DirectionName:         "Toddington",
+					AimedDepartureTime:    parse("2020-03-30T12:00:00+01:00"),
+					ExpectedDepartureTime: parse("2020-03-30T12:03:00+01:00"),
+					Source:                transport.LiveSource,
+					JourneyRef:            "1234",
+				}},
+				{result: &transport.DepartureInfo{
+					LineName:              "42",
+					DirectionName:         "toddington",
+					AimedDepartureTime:    parse("2020-03-30T12:00:00+01:00"),
+					ExpectedDepartureTime: parse("2020-03-30T12:05:00+01:00"),
+					Source:                transport.LiveSource,
+					JourneyRef:            "VJ_42_1",
+					RecordedAt:            parse("2020-03-30T11:59:30+01:00"),
+				}},
+				{result: &transport.DepartureInfo{
+					VehicleMode:        "bus",
+					LineName:           "42",
+					DirectionName:      "Toddington",
+					AimedDepartureTime: parse("2020-03-30T12:00:00+01:00"),
+					Source:             transport.ScheduledSource,
+				}},
+			},
+			expectedResult: &transport.DepartureInfo{
+				VehicleMode:           "bus",
+				LineName:              "42",
+				DirectionName:         "toddington",
+				AimedDepartureTime:    parse("2020-03-30T12:00:00+01:00"),
+				ExpectedDepartureTime: parse("2020-03-30T12:05:00+01:00"),
+				Source:                transport.LiveSource,
+				JourneyRef:            "VJ_42_1",
+				RecordedAt:            parse("2020-03-30T11:59:30+01:00"),
+				Sources:               []string{"traveline", "gtfsrt", "timetable"},
+			},
+		},
+		{
+			name: "Same journey by journey ref",
+			responses: []response{
+				{result: &transport.DepartureInfo{
+					LineName:           "42",
+					AimedDepartureTime: parse("2020-03-30T12:00:00+01:00"),
+					Source:             transport.ScheduledSource,
+					JourneyRef:         "VJ_42_1",
+				}},
+				{result: &transport.DepartureInfo{
+					LineName:              "42",
+					DirectionName:         "Toddington",
+					ExpectedDepartureTime: parse("2020-03-30T12:05:00+01:00"),
+					Source:                transport.LiveSource,
+					JourneyRef:            "VJ_42_1",
+				}},
+				{err: errors.New("error status from API: 503")},
+			},
+			expectedResult: &transport.DepartureInfo{
+				LineName:              "42",
+				DirectionName:         "Toddington",
+				AimedDepartureTime:    parse("2020-03-30T12:00:00+01:00"),
+				ExpectedDepartureTime: parse("2020-03-30T12:05:00+01:00"),
+				Source:                transport.LiveSource,
+				JourneyRef:            "VJ_42_1",
+				Sources:               []string{"traveline", "gtfsrt"},
+			},
+		},
+		{
+			name: "Disruptions and accessibility from every provider",
+			responses: []response{
+				{result: &transport.DepartureInfo{
+					LineName:              "42",
+					ExpectedDepartureTime: parse("2020-03-30T12:05:00+01:00"),
+					Source:                transport.LiveSource,
+					JourneyRef:            "VJ_42_1",
+					Disruptions:           []transport.Disruption{{SituationNumber: "SIT-1"}},
+				}},
+				{result: &transport.DepartureInfo{
+					LineName:             "42",
+					AimedDepartureTime:   parse("2020-03-30T12:00:00+01:00"),
+					Source:               transport.ScheduledSource,
+					JourneyRef:           "VJ_42_1",
+					Disruptions:          []transport.Disruption{{SituationNumber: "SIT-1"}, {SituationNumber: "SIT-2"}},
+					VehicleFeatures:      []string{"lowFloor", "wheelchairAccessible"},
+					LowFloor:             true,
+					WheelchairAccessible: true,
+					WheelchairSpaces:     &wheelchairSpaces,
+				}},
+			},
+			expectedResult: &transport.DepartureInfo{
+				LineName:              "42",
+				AimedDepartureTime:    parse("2020-03-30T12:00:00+01:00"),
+				ExpectedDepartureTime: parse("2020-03-30T12:05:00+01:00"),
+				Source:                transport.LiveSource,
+				JourneyRef:            "VJ_42_1",
+				Sources:               []string{"traveline", "gtfsrt"},
+				Disruptions:           []transport.Disruption{{SituationNumber: "SIT-1"}, {SituationNumber: "SIT-2"}},
+				VehicleFeatures:       []string{"lowFloor", "wheelchairAccessible"},
+				LowFloor:              true,
+				WheelchairAccessible:  true,
+				WheelchairSpaces:      &wheelchairSpaces,
+			},
+		},
+		{
+			name: "Earliest of different journeys",
+			responses: []response{
+				{result: &transport.DepartureInfo{
+					LineName:              "42",
+					AimedDepartureTime:    parse("2020-03-30T12:00:00+01:00"),
+					ExpectedDepartureTime: parse("2020-03-30T12:10:00+01:00"),
+					Source:                transport.LiveSource,
+				}},
+				{err: traveline.NoTimesFoundError{}},
+				{result: &transport.DepartureInfo{
+					LineName:           "X5",
+					AimedDepartureTime: parse("2020-03-30T12:06:00+01:00"),
+					Source:             transport.ScheduledSource,
+				}},
+			},
+			expectedResult: &transport.DepartureInfo{
+				LineName:           "X5",
+				AimedDepartureTime: parse("2020-03-30T12:06:00+01:00"),
+				Source:             transport.ScheduledSource,
+				Sources:            []string{"timetable"},
+			},
+		},
+		{
+			name: "Departure without a time is skipped",
+			responses: []response{
+				{result: &transport.DepartureInfo{
+					LineName: "42",
+					Source:   transport.LiveSource,
+				}},
+				{result: &transport.DepartureInfo{
+					LineName:           "X5",
+					AimedDepartureTime: parse("2020-03-30T12:06:00+01:00"),
+					Source:             transport.ScheduledSource,
+				}},
+			},
+			expectedResult: &transport.DepartureInfo{
+				LineName:           "X5",
+				AimedDepartureTime: parse("2020-03-30T12:06:00+01:00"),
+				Source:             transport.ScheduledSource,
+				Sources:            []string{"gtfsrt"},
+			},
+		},
+		{
+			name: "Only departures without a time",
+			responses: []response{
+				{result: &transport.DepartureInfo{LineName: "42"}},
+			},
+			expectedError: errors.New(`No departures found for stop "020035811"`),
+		},
+		{
+			name: "All providers fail",
+			responses: []response{
+				{err: traveline.NoTimesFoundError{}},
+				{err: errors.New("error status from feed: 503")},
+				{err: &transport.NoDeparturesFoundError{NaptanCode: "020035811"}},
+			},
+			expectedError: errors.New("No next departure times found"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			names := []string{"traveline", "gtfsrt", "timetable"}
+			var providers []transport.Provider
+			for i, response := range test.responses {
+				mockAPI := mock_transport.NewMockAPI(ctrl)
+				mockAPI.
+					EXPECT().
+					GetNextDepartureTime(gomock.Eq("020035811"), gomock.Eq(now)).
+					Return(response.result, response.err)
+				providers = append(providers, transport.Provider{Name: names[i], API: mockAPI})
+			}
+
+			req := transport.NewMerge(providers...)
+
+			result, err := req.GetNextDepartureTime("020035811", now)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+				t.Errorf("GetNextDepartureTime() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
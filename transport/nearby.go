@@ -0,0 +1,64 @@
+package transport
+
+import (
+	"sync"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/naptan"
+)
+
+// MaxNearbyDepartures is the most stops that departures are requested for, whatever the limit
+const MaxNearbyDepartures = 20
+
+// nearbyConcurrency is the most departure requests that are made at once
+const nearbyConcurrency = 4
+
+// NearbyDeparture represents the next departure from a stop near a location
+type NearbyDeparture struct {
+	Stop      naptan.NearbyStop
+	Departure *DepartureInfo
+	// Err is set if the departure could not be found for the stop
+	Err error
+}
+
+// GetNextDeparturesNear returns the next departure from each of the closest stops within the radius
+// in metres of the location. The departures are requested concurrently and returned closest stop first,
+// a failure for one stop is recorded against that stop rather than failing the others.
+// The limit bounds the number of requests made, a limit that is not positive or is above
+// MaxNearbyDepartures is capped to it, and at most a few requests are made at once.
+func GetNextDeparturesNear(
+	api API,
+	stops *naptan.Dataset,
+	lat float64,
+	lon float64,
+	radius float64,
+	limit int,
+	when time.Time,
+) []NearbyDeparture {
+	if limit <= 0 || limit > MaxNearbyDepartures {
+		limit = MaxNearbyDepartures
+	}
+	nearbyStops := stops.FindStopsNear(lat, lon, radius, limit)
+	departures := make([]NearbyDeparture, len(nearbyStops))
+
+	var wg sync.WaitGroup
+	requests := make(chan struct{}, nearbyConcurrency)
+	for i, stop := range nearbyStops {
+		wg.Add(1)
+		go func(i int, stop naptan.NearbyStop) {
+			defer wg.Done()
+			requests <- struct{}{}
+			defer func() { <-requests }()
+
+			departure, err := api.GetNextDepartureTime(stop.Stop.ATCOCode, when)
+			departures[i] = NearbyDeparture{
+				Stop:      stop,
+				Departure: departure,
+				Err:       err,
+			}
+		}(i, stop)
+	}
+	wg.Wait()
+
+	return departures
+}
@@ -0,0 +1,115 @@
+package transport_test
+
+// The following comment is used by 'go generate ./...' command. DO NOT DELETE!!!
+//go:generate mockgen -destination ../mock/mock_transport/mock_transport.go github.com/conradhodge/travel-api-client/transport API
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/mock/mock_transport"
+	"github.com/conradhodge/travel-api-client/naptan"
+	"github.com/conradhodge/travel-api-client/transport"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestGetNextDeparturesNear(t *testing.T) {
+	now := time.Now()
+	departureTime, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56.911+01:00")
+
+	stops := naptan.NewDataset([]naptan.Stop{
+		{ATCOCode: "020035811", CommonName: "The Green", Latitude: 51.94911, Longitude: -0.53385},
+		{ATCOCode: "020035812", CommonName: "The Green", Latitude: 51.94902, Longitude: -0.53371},
+		{ATCOCode: "020035813", CommonName: "Market Square", Latitude: 51.95100, Longitude: -0.53200},
+		{ATCOCode: "0200BDA00101", CommonName: "Bedford Bus Station", Latitude: 52.13757, Longitude: -0.47081},
+	})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPI := mock_transport.NewMockAPI(ctrl)
+	mockAPI.
+		EXPECT().
+		GetNextDepartureTime(gomock.Eq("020035811"), gomock.Eq(now)).
+		Return(&transport.DepartureInfo{LineName: "42", AimedDepartureTime: &departureTime}, nil)
+	mockAPI.
+		EXPECT().
+		GetNextDepartureTime(gomock.Eq("020035812"), gomock.Eq(now)).
+		Return(nil, errors.New("no times"))
+
+	departures := transport.GetNextDeparturesNear(mockAPI, stops, 51.94950, -0.53400, 1000, 2, now)
+
+	expectedDepartures := []transport.NearbyDeparture{
+		{
+			Stop:      naptan.NearbyStop{Stop: &stops.Stops()[0]},
+			Departure: &transport.DepartureInfo{LineName: "42", AimedDepartureTime: &departureTime},
+		},
+		{
+			Stop: naptan.NearbyStop{Stop: &stops.Stops()[1]},
+			Err:  errors.New("no times"),
+		},
+	}
+
+	opts := cmp.Options{
+		cmpopts.IgnoreFields(naptan.NearbyStop{}, "Distance", "Bearing"),
+		cmp.Comparer(func(x, y error) bool {
+			return x == nil && y == nil || x != nil && y != nil && x.Error() == y.Error()
+		}),
+	}
+	if diff := cmp.Diff(expectedDepartures, departures, opts); diff != "" {
+		t.Errorf("GetNextDeparturesNear() (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetNextDeparturesNearIsBounded(t *testing.T) {
+	now := time.Now()
+
+	var nearby []naptan.Stop
+	for i := 0; i < transport.MaxNearbyDepartures+5; i++ {
+		nearby = append(nearby, naptan.Stop{
+			ATCOCode:  fmt.Sprintf("0200358%02d", i),
+			Latitude:  51.94911 + float64(i)*0.00001,
+			Longitude: -0.53385,
+		})
+	}
+	stops := naptan.NewDataset(nearby)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var inFlight, maxInFlight int32
+	mockAPI := mock_transport.NewMockAPI(ctrl)
+	mockAPI.
+		EXPECT().
+		GetNextDepartureTime(gomock.Any(), gomock.Eq(now)).
+		DoAndReturn(func(string, time.Time) (*transport.DepartureInfo, error) {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				seen := atomic.LoadInt32(&maxInFlight)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return &transport.DepartureInfo{}, nil
+		}).
+		Times(2 * transport.MaxNearbyDepartures)
+
+	// A limit that is not positive is capped rather than requesting every stop within the radius
+	for _, limit := range []int{0, transport.MaxNearbyDepartures + 5} {
+		departures := transport.GetNextDeparturesNear(mockAPI, stops, 51.94911, -0.53385, 1000, limit, now)
+		if len(departures) != transport.MaxNearbyDepartures {
+			t.Errorf("Expected %d departures for limit %d; got %d", transport.MaxNearbyDepartures, limit, len(departures))
+		}
+	}
+
+	if maxInFlight > 4 {
+		t.Errorf("Expected at most 4 requests at once; got %d", maxInFlight)
+	}
+}
@@ -0,0 +1,68 @@
+package transport
+
+import (
+	"sort"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/naptan"
+	"github.com/conradhodge/travel-api-client/tfl"
+)
+
+// TfL is used to make transport requests using the TfL Unified API
+type TfL struct {
+	API tfl.API
+}
+
+// NewTfL returns the implementation of the transport API using the TfL Unified API
+func NewTfL(api tfl.API) *TfL {
+	return &TfL{API: api}
+}
+
+// GetNextDepartureTime returns the next departure time at the stop that the NaPTAN code represents.
+// TfL only publishes predictions, so the aimed departure time is not set.
+func (c *TfL) GetNextDepartureTime(naptanCode string, when time.Time) (*DepartureInfo, error) {
+	code, err := naptan.ValidateCode(naptanCode)
+	if err != nil {
+		return nil, err
+	}
+
+	predictions, err := c.API.GetArrivals(code)
+	if err != nil {
+		return nil, err
+	}
+
+	departures := make([]DepartureInfo, 0, len(predictions))
+	for _, prediction := range predictions {
+		expectedDepartureTime, err := convertDepartureTime(prediction.ExpectedArrival)
+		if err != nil {
+			return nil, err
+		}
+		if expectedDepartureTime.Before(when) {
+			continue
+		}
+
+		departure := DepartureInfo{
+			VehicleMode:           prediction.ModeName,
+			LineName:              prediction.LineName,
+			DirectionName:         prediction.DestinationName,
+			ExpectedDepartureTime: &expectedDepartureTime,
+			Source:                LiveSource,
+		}
+		if recordedAt, err := time.Parse(time.RFC3339, prediction.Timestamp); err == nil {
+			departure.RecordedAt = &recordedAt
+		}
+
+		departures = append(departures, departure)
+	}
+
+	if len(departures) == 0 {
+		return nil, &NoDeparturesFoundError{NaptanCode: naptanCode}
+	}
+
+	// Predictions are not returned in order
+	sort.SliceStable(departures, func(i, j int) bool {
+		return departures[i].ExpectedDepartureTime.Before(*departures[j].ExpectedDepartureTime)
+	})
+
+	return &departures[0], nil
+}
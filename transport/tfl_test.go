@@ -0,0 +1,118 @@
+package transport_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/tfl"
+	"github.com/conradhodge/travel-api-client/transport"
+	"github.com/google/go-cmp/cmp"
+)
+
+// newFakeTfL returns a local server that responds to arrivals requests with the response
+func newFakeTfL(t *testing.T, statusCode int, response string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expectedPath := "/StopPoint/490008660N/Arrivals"
+		if r.URL.Path != expectedPath {
+			t.Errorf("Expected path: %s, got: %s", expectedPath, r.URL.Path)
+		}
+
+		w.WriteHeader(statusCode)
+		fmt.Fprint(w, response)
+	}))
+}
+
+func TestTfLGetNextDepartureTime(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T11:30:00Z")
+	nextDepartureTime, _ := time.Parse(time.RFC3339, "2020-03-30T11:32:10Z")
+	recordedAt, _ := time.Parse(time.RFC3339, "2020-03-30T11:29:45.1234567Z")
+
+	tests := []struct {
+		name           string
+		naptanCode     string
+		statusCode     int
+		response       string
+		expectedError  error
+		expectedResult *transport.DepartureInfo
+	}{
+		{
+			name:       "Earliest prediction is returned",
+			naptanCode: "490008660N",
+			statusCode: http.StatusOK,
+			response: `[
+				{"lineName": "88", "destinationName": "Clapham Common", "expectedArrival": "2020-03-30T11:34:54Z", "modeName": "bus"},
+				{"lineName": "12", "destinationName": "Dulwich Library", "timestamp": "2020-03-30T11:29:45.1234567Z", "expectedArrival": "2020-03-30T11:32:10Z", "modeName": "bus"},
+				{"lineName": "73", "destinationName": "Stoke Newington", "expectedArrival": "2020-03-30T11:29:00Z", "modeName": "bus"}
+			]`,
+			expectedResult: &transport.DepartureInfo{
+				VehicleMode:           "bus",
+				LineName:              "12",
+				DirectionName:         "Dulwich Library",
+				ExpectedDepartureTime: &nextDepartureTime,
+				Source:                transport.LiveSource,
+				RecordedAt:            &recordedAt,
+			},
+		},
+		{
+			name:          "No predictions after time",
+			naptanCode:    "490008660N",
+			statusCode:    http.StatusOK,
+			response:      `[{"lineName": "73", "expectedArrival": "2020-03-30T11:29:00Z", "modeName": "bus"}]`,
+			expectedError: errors.New(`No departures found for stop "490008660N"`),
+		},
+		{
+			name:       "Invalid expected arrival time",
+			naptanCode: "490008660N",
+			statusCode: http.StatusOK,
+			response:   `[{"lineName": "73", "expectedArrival": "bongo", "modeName": "bus"}]`,
+			expectedError: &transport.InvalidTimeFoundError{
+				Time:   "bongo",
+				Reason: `parsing time "bongo" as "2006-01-02T15:04:05Z07:00": cannot parse "bongo" as "2006"`,
+			},
+		},
+		{
+			name:          "Error from API",
+			naptanCode:    "490008660N",
+			statusCode:    http.StatusInternalServerError,
+			response:      `{"message": "Internal Server Error"}`,
+			expectedError: errors.New("error status from API: 500"),
+		},
+		{
+			name:          "Invalid stop code",
+			naptanCode:    "Oxford Circus",
+			expectedError: errors.New(`Invalid stop code "Oxford Circus": NaPTAN code must be 7 or 8 characters`),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newFakeTfL(t, test.statusCode, test.response)
+			defer server.Close()
+
+			api := &tfl.Client{URL: server.URL, Client: server.Client()}
+			req := transport.NewTfL(api)
+
+			result, err := req.GetNextDepartureTime(test.naptanCode, when)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+				t.Errorf("GetNextDepartureTime() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -4,13 +4,62 @@ import (
 	"time"
 )
 
-// DepartureInfo represents the details for the next departure from a stop
+// Sources of departure information
+const (
+	// LiveSource is real-time information from the vehicles
+	LiveSource = "live"
+	// ScheduledSource is from the timetable
+	ScheduledSource = "scheduled"
+)
+
+// Status is the progress of a journey at a stop, as published by the provider
+type Status string
+
+// Statuses of a journey at a stop, the empty status is used when the provider doesn't publish one
+const (
+	OnTime      Status = "onTime"
+	Early       Status = "early"
+	Delayed     Status = "delayed"
+	Cancelled   Status = "cancelled"
+	Arrived     Status = "arrived"
+	Departed    Status = "departed"
+	Missed      Status = "missed"
+	NoReport    Status = "noReport"
+	NotExpected Status = "notExpected"
+)
+
+// DepartureInfo represents the details for the next departure from a stop,
+// the aimed departure time is not set by providers that only publish predictions
 type DepartureInfo struct {
 	VehicleMode           string
 	LineName              string
 	DirectionName         string
 	AimedDepartureTime    *time.Time
 	ExpectedDepartureTime *time.Time
+	// Source is LiveSource or ScheduledSource, so that scheduled times can be shown differently
+	Source string
+	// JourneyRef identifies the journey to the provider, if it publishes one
+	JourneyRef string
+	// RecordedAt is when a real-time prediction was made, if the provider publishes it
+	RecordedAt *time.Time
+	// Sources are the names of the providers that contributed to a merged departure
+	Sources []string
+	// Disruptions are the situations at the stop that affect the departure, if the provider publishes them
+	Disruptions []Disruption
+	// PreviousCalls are the stops the journey called at before the stop, if the provider publishes them
+	PreviousCalls []PredictedCall
+	// OnwardCalls are the stops the journey calls at after the stop, if the provider publishes them
+	OnwardCalls []PredictedCall
+	// Status is the progress of the journey at the stop, e.g. Cancelled, if the provider publishes it
+	Status Status
+	// Occupancy is how full the vehicle is, if the provider publishes it
+	Occupancy Occupancy
+	// VehicleFeatures are the features of the vehicle as the provider publishes them, e.g. lowFloor
+	VehicleFeatures      []string
+	LowFloor             bool
+	WheelchairAccessible bool
+	// WheelchairSpaces is the number of free wheelchair spaces, if the provider publishes it
+	WheelchairSpaces *int
 }
 
 // API represents an API to get travel times for public transport
@@ -0,0 +1,72 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/conradhodge/travel-api-client/naptan"
+	"github.com/conradhodge/travel-api-client/transxchange"
+)
+
+// TransXChange is used to make transport requests using TransXChange timetables
+type TransXChange struct {
+	Timetable *transxchange.Timetable
+	// Stops, if set, is used to resolve NaPTAN codes to the ATCO codes used in the timetable
+	Stops *naptan.Dataset
+}
+
+// NewTransXChange returns the implementation of the transport API using TransXChange timetables
+func NewTransXChange(timetable *transxchange.Timetable) *TransXChange {
+	return &TransXChange{Timetable: timetable}
+}
+
+// GetNextDepartureTime returns the next scheduled departure time at the stop that the NaPTAN code represents.
+// Only the aimed departure time is set, as the timetable has no real-time information.
+func (c *TransXChange) GetNextDepartureTime(naptanCode string, when time.Time) (*DepartureInfo, error) {
+	code, err := c.stopPointRef(naptanCode)
+	if err != nil {
+		return nil, err
+	}
+
+	departures := c.Timetable.Departures(code, when, 1)
+	if len(departures) == 0 {
+		return nil, &NoDeparturesFoundError{NaptanCode: naptanCode}
+	}
+
+	departure := departures[0]
+	nextDepartureInfo := DepartureInfo{
+		VehicleMode:        departure.Service.Mode,
+		LineName:           departure.LineName,
+		DirectionName:      departure.JourneyPattern.DestinationDisplay,
+		AimedDepartureTime: &departure.Time,
+		Source:             ScheduledSource,
+		JourneyRef:         departure.Journey.Code,
+	}
+	if nextDepartureInfo.DirectionName == "" {
+		nextDepartureInfo.DirectionName = departure.Service.Destination
+	}
+
+	return &nextDepartureInfo, nil
+}
+
+// stopPointRef returns the ATCO code of the stop in the timetable
+func (c *TransXChange) stopPointRef(naptanCode string) (string, error) {
+	code, err := naptan.ValidateCode(naptanCode)
+	if err != nil {
+		return "", err
+	}
+
+	if c.Stops != nil {
+		if stop, ok := c.Stops.Lookup(code); ok {
+			code = stop.ATCOCode
+		}
+	}
+
+	if !c.Timetable.HasStop(code) {
+		return "", &naptan.InvalidStopCodeError{
+			Code:   naptanCode,
+			Reason: "stop not found",
+		}
+	}
+
+	return code, nil
+}
@@ -0,0 +1,106 @@
+package transport_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/naptan"
+	"github.com/conradhodge/travel-api-client/transport"
+	"github.com/conradhodge/travel-api-client/transxchange"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTransXChangeGetNextDepartureTime(t *testing.T) {
+	timetable, err := transxchange.Load("../transxchange/testdata/service.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	stops := naptan.NewDataset([]naptan.Stop{
+		{ATCOCode: "0100BRP90310", NaptanCode: "bstgwpa", CommonName: "Temple Meads Station"},
+	})
+
+	parse := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &parsed
+	}
+
+	tests := []struct {
+		name           string
+		naptanCode     string
+		when           time.Time
+		expectedError  error
+		expectedResult *transport.DepartureInfo
+	}{
+		{
+			name:       "Next scheduled departure",
+			naptanCode: "0100BRP90311",
+			when:       *parse("2020-03-30T12:00:00+01:00"),
+			expectedResult: &transport.DepartureInfo{
+				VehicleMode:        "bus",
+				LineName:           "72",
+				DirectionName:      "Cribbs Causeway",
+				AimedDepartureTime: parse("2020-03-31T00:03:00+01:00"),
+				Source:             transport.ScheduledSource,
+				JourneyRef:         "VJ2",
+			},
+		},
+		{
+			name:       "NaPTAN code resolved to ATCO code",
+			naptanCode: "bstgwpa",
+			when:       *parse("2020-03-30T07:00:00+01:00"),
+			expectedResult: &transport.DepartureInfo{
+				VehicleMode:        "bus",
+				LineName:           "72",
+				DirectionName:      "Cribbs Causeway",
+				AimedDepartureTime: parse("2020-03-30T07:30:00+01:00"),
+				Source:             transport.ScheduledSource,
+				JourneyRef:         "VJ1",
+			},
+		},
+		{
+			name:          "No departures",
+			naptanCode:    "0100BRP90312",
+			when:          *parse("2020-03-30T12:00:00+01:00"),
+			expectedError: errors.New(`No departures found for stop "0100BRP90312"`),
+		},
+		{
+			name:          "Stop not in timetable",
+			naptanCode:    "020035811",
+			when:          *parse("2020-03-30T12:00:00+01:00"),
+			expectedError: errors.New(`Invalid stop code "020035811": stop not found`),
+		},
+		{
+			name:          "Invalid stop code",
+			naptanCode:    "Temple-Meads",
+			when:          *parse("2020-03-30T12:00:00+01:00"),
+			expectedError: errors.New(`Invalid stop code "Temple-Meads": invalid character '-'`),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := transport.NewTransXChange(timetable)
+			req.Stops = stops
+
+			result, err := req.GetNextDepartureTime(test.naptanCode, test.when)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+				t.Errorf("GetNextDepartureTime() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
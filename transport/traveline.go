@@ -1,25 +1,50 @@
 package transport
 
 import (
+	"log"
+	"strings"
 	"time"
 
+	"github.com/conradhodge/travel-api-client/naptan"
 	"github.com/conradhodge/travel-api-client/traveline"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
 // Traveline is used to make transport requests using the Traveline API
 type Traveline struct {
-	API traveline.API
+	API traveline.RealTimeAPI
+	// RequestOptions are sent with each request, e.g. to widen the window of departures at quiet stops
+	RequestOptions traveline.RequestOptions
+	// Stops, if set, is used to check that a stop exists before requesting its departures
+	Stops *naptan.Dataset
+	// IncludeDisruptions requests the situations at the stop or on the line to add those that affect the departure
+	IncludeDisruptions bool
+	// Discovery, if set, is used to check that the producer covers a stop or line before requesting it
+	Discovery *traveline.Discovery
+	// ExcludeCancelled skips cancelled journeys so the next departure is one that will run
+	ExcludeCancelled bool
+	// AccessibleOnly skips journeys with vehicles that are not known to be low floor or wheelchair accessible
+	AccessibleOnly bool
 }
 
 // NewTraveline returns the implementation of the transport API using the Traveline API
-func NewTraveline(api traveline.API) *Traveline {
+func NewTraveline(api traveline.RealTimeAPI) *Traveline {
 	return &Traveline{API: api}
 }
 
 // GetNextDepartureTime returns the next departure time at the stop that the NaPTAN code represents
 func (c *Traveline) GetNextDepartureTime(naptanCode string, when time.Time) (*DepartureInfo, error) {
-	request, err := c.API.BuildServiceRequest(uuid.New().String(), naptanCode, when)
+	monitoringRef, err := c.monitoringRef(naptanCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkStop(monitoringRef, when); err != nil {
+		return nil, err
+	}
+
+	requestRef := uuid.New().String()
+	request, err := c.API.BuildServiceRequest(requestRef, monitoringRef, when, c.RequestOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -29,7 +54,7 @@ func (c *Traveline) GetNextDepartureTime(naptanCode string, when time.Time) (*De
 		return nil, err
 	}
 
-	monitoredVehicleJourney, err := c.API.ParseServiceDelivery(response)
+	monitoredVehicleJourney, err := c.nextJourney(requestRef, response, monitoringRef)
 	if err != nil {
 		return nil, err
 	}
@@ -38,25 +63,210 @@ func (c *Traveline) GetNextDepartureTime(naptanCode string, when time.Time) (*De
 		LineName:      monitoredVehicleJourney.PublishedLineName,
 		VehicleMode:   monitoredVehicleJourney.VehicleMode,
 		DirectionName: monitoredVehicleJourney.DirectionName,
+		Source:        LiveSource,
+		JourneyRef:    monitoredVehicleJourney.FramedVehicleJourneyRef.DatedVehicleJourneyRef,
+		Status:        convertStatus(departureStatus(monitoredVehicleJourney.MonitoredCall), monitoredVehicleJourney.Monitored),
 	}
 
-	// Convert aimed departure time to time.Time
-	aimedDepartureTime, err := convertDepartureTime(monitoredVehicleJourney.MonitoredCall.AimedDepartureTime)
+	convertVehicle(&nextDepartureInfo, monitoredVehicleJourney)
+
+	// Times of journeys that are not being tracked are from the timetable
+	if monitoredVehicleJourney.Monitored == "false" {
+		nextDepartureInfo.Source = ScheduledSource
+	}
+
+	// Producers that only publish predictions have no aimed departure time, and producers may only
+	// publish the arrival at a stop the journey passes through, when it departs as soon as it arrives
+	call := monitoredVehicleJourney.MonitoredCall
+	aimed, expected := call.AimedDepartureTime, call.ExpectedDepartureTime
+	if !call.HasDeparture() {
+		aimed, expected = call.AimedArrivalTime, call.ExpectedArrivalTime
+	}
+	err = convertCallTimes(
+		callTime{value: aimed, target: &nextDepartureInfo.AimedDepartureTime},
+		callTime{value: expected, target: &nextDepartureInfo.ExpectedDepartureTime},
+	)
 	if err != nil {
 		return nil, err
 	}
-	nextDepartureInfo.AimedDepartureTime = &aimedDepartureTime
 
-	// Convert expected departure time to time.Time
-	if len(monitoredVehicleJourney.MonitoredCall.ExpectedDepartureTime) > 0 {
-		expectedDepartureTime, err := convertDepartureTime(monitoredVehicleJourney.MonitoredCall.ExpectedDepartureTime)
+	if err := c.convertCalls(&nextDepartureInfo, monitoredVehicleJourney); err != nil {
+		return nil, err
+	}
+
+	if c.IncludeDisruptions {
+		c.attachDisruptions(&nextDepartureInfo, monitoringRef, when)
+	}
+
+	return &nextDepartureInfo, nil
+}
+
+// nextJourney returns the next journey in the response to the request that departs from the stop, skipping those
+// that are excluded
+func (c *Traveline) nextJourney(requestRef string, response string, monitoringRef string) (*traveline.MonitoredVehicleJourney, error) {
+	if !c.ExcludeCancelled && !c.AccessibleOnly {
+		journey, err := c.API.ParseServiceDelivery(response)
+		if err != nil || !terminatesAt(journey, monitoringRef) {
+			return journey, err
+		}
+	}
+
+	visits, err := c.API.ParseStopMonitoringDelivery(requestRef, response)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, visit := range visits {
+		journey := visit.MonitoredVehicleJourney
+		if terminatesAt(&journey, monitoringRef) || c.excluded(&journey) {
+			continue
+		}
+		return &journey, nil
+	}
+
+	return nil, &traveline.NoTimesFoundError{}
+}
+
+// terminatesAt returns whether the journey ends at the stop, so doesn't depart from it.
+// Producers often omit the departure time, so only the destination shows the journey ends here.
+func terminatesAt(journey *traveline.MonitoredVehicleJourney, monitoringRef string) bool {
+	return strings.EqualFold(journey.DestinationRef, monitoringRef)
+}
+
+// excluded returns whether the journey is skipped by the options
+func (c *Traveline) excluded(journey *traveline.MonitoredVehicleJourney) bool {
+	if c.ExcludeCancelled && convertStatus(departureStatus(journey.MonitoredCall), journey.Monitored) == Cancelled {
+		return true
+	}
+	if c.AccessibleOnly {
+		var departure DepartureInfo
+		convertVehicle(&departure, journey)
+		return !departure.Accessible()
+	}
+
+	return false
+}
+
+// departureStatus returns the departure status of the call, falling back to the arrival status
+// for producers that only publish the status of the arrival
+func departureStatus(call traveline.MonitoredCall) string {
+	if call.DepartureStatus != "" {
+		return call.DepartureStatus
+	}
+
+	return call.ArrivalStatus
+}
+
+// convertStatus returns the status of the journey, a journey without a status that is not monitored
+// has no real-time report
+func convertStatus(status string, monitored string) Status {
+	if status != "" {
+		return Status(status)
+	}
+	if monitored == "false" {
+		return NoReport
+	}
+
+	return ""
+}
+
+// convertCalls adds the previous and onward calls of the journey, which are only returned
+// when the calls detail level is requested
+func (c *Traveline) convertCalls(departure *DepartureInfo, journey *traveline.MonitoredVehicleJourney) error {
+	for _, previousCall := range journey.PreviousCalls {
+		call := PredictedCall{
+			StopPointRef:  previousCall.StopPointRef,
+			StopPointName: previousCall.StopPointName,
+			Order:         previousCall.VisitNumber,
+		}
+		err := convertCallTimes(
+			callTime{value: previousCall.AimedArrivalTime, target: &call.AimedArrivalTime},
+			callTime{value: previousCall.ActualArrivalTime, target: &call.ActualArrivalTime},
+			callTime{value: previousCall.AimedDepartureTime, target: &call.AimedDepartureTime},
+			callTime{value: previousCall.ActualDepartureTime, target: &call.ActualDepartureTime},
+		)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		nextDepartureInfo.ExpectedDepartureTime = &expectedDepartureTime
+		departure.PreviousCalls = append(departure.PreviousCalls, call)
 	}
 
-	return &nextDepartureInfo, nil
+	for _, onwardCall := range journey.OnwardCalls {
+		call := PredictedCall{
+			StopPointRef:  onwardCall.StopPointRef,
+			StopPointName: onwardCall.StopPointName,
+			Order:         onwardCall.VisitNumber,
+		}
+		err := convertCallTimes(
+			callTime{value: onwardCall.AimedArrivalTime, target: &call.AimedArrivalTime},
+			callTime{value: onwardCall.ExpectedArrivalTime, target: &call.ExpectedArrivalTime},
+			callTime{value: onwardCall.AimedDepartureTime, target: &call.AimedDepartureTime},
+			callTime{value: onwardCall.ExpectedDepartureTime, target: &call.ExpectedDepartureTime},
+		)
+		if err != nil {
+			return err
+		}
+		departure.OnwardCalls = append(departure.OnwardCalls, call)
+	}
+
+	return nil
+}
+
+// monitoringRef returns the code to request departures for, checking that the code is valid
+// so that a request is not wasted on a typo
+func (c *Traveline) monitoringRef(naptanCode string) (string, error) {
+	code, err := naptan.ValidateCode(naptanCode)
+	if err != nil {
+		return "", err
+	}
+
+	if c.Stops == nil {
+		return code, nil
+	}
+
+	stop, ok := c.Stops.Lookup(code)
+	if !ok {
+		return "", &naptan.InvalidStopCodeError{
+			Code:   naptanCode,
+			Reason: "stop not found",
+		}
+	}
+
+	// NaPTAN codes are resolved to the ATCO code used by the API
+	return stop.ATCOCode, nil
+}
+
+// checkStop returns an error if discovery finds that the producer does not cover the stop
+func (c *Traveline) checkStop(monitoringRef string, when time.Time) error {
+	if c.Discovery == nil {
+		return nil
+	}
+
+	return coverageError(c.Discovery.ValidateStop(monitoringRef, when))
+}
+
+// checkLine returns an error if discovery finds that the producer does not cover the line
+func (c *Traveline) checkLine(lineRef string, when time.Time) error {
+	if c.Discovery == nil || lineRef == "" {
+		return nil
+	}
+
+	return coverageError(c.Discovery.ValidateLine(lineRef, when))
+}
+
+// coverageError returns the error if the stop or line is not covered, discovery is only a check
+// so the request is still made if the producer's coverage cannot be found
+func coverageError(err error) error {
+	var stopErr *traveline.StopNotCoveredError
+	var lineErr *traveline.LineNotCoveredError
+	if errors.As(err, &stopErr) || errors.As(err, &lineErr) {
+		return err
+	}
+	if err != nil {
+		log.Printf("Cannot discover the coverage of the producer: %s", err)
+	}
+
+	return nil
 }
 
 func convertDepartureTime(departureTime string) (time.Time, error) {
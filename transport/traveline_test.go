@@ -5,11 +5,18 @@ package transport_test
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/conradhodge/travel-api-client/internal/siritest"
 	"github.com/conradhodge/travel-api-client/matcher"
 	"github.com/conradhodge/travel-api-client/mock/mock_traveline"
+	"github.com/conradhodge/travel-api-client/naptan"
 	"github.com/conradhodge/travel-api-client/transport"
 	"github.com/conradhodge/travel-api-client/traveline"
 	"github.com/golang/mock/gomock"
@@ -25,6 +32,7 @@ func TestGetNextTravel(t *testing.T) {
 		name           string
 		naptanCode     string
 		when           time.Time
+		options        traveline.RequestOptions
 		parseResult    *traveline.MonitoredVehicleJourney
 		buildError     error
 		parseError     error
@@ -40,10 +48,7 @@ func TestGetNextTravel(t *testing.T) {
 				VehicleMode:       "magic carpet",
 				PublishedLineName: "flying",
 				DirectionName:     "Xanadu",
-				MonitoredCall: struct {
-					AimedDepartureTime    string "xml:\"AimedDepartureTime\""
-					ExpectedDepartureTime string "xml:\"ExpectedDepartureTime\""
-				}{
+				MonitoredCall: traveline.MonitoredCall{
 					AimedDepartureTime:    "2020-03-30T12:34:56.911+01:00",
 					ExpectedDepartureTime: "2020-03-30T12:37:56.911+01:00",
 				},
@@ -54,6 +59,7 @@ func TestGetNextTravel(t *testing.T) {
 				DirectionName:         "Xanadu",
 				AimedDepartureTime:    &nextDepartureTime,
 				ExpectedDepartureTime: &differsNextDepartureTime,
+				Source:                transport.LiveSource,
 			},
 		},
 		{
@@ -64,10 +70,7 @@ func TestGetNextTravel(t *testing.T) {
 				VehicleMode:       "magic carpet",
 				PublishedLineName: "flying",
 				DirectionName:     "Xanadu",
-				MonitoredCall: struct {
-					AimedDepartureTime    string "xml:\"AimedDepartureTime\""
-					ExpectedDepartureTime string "xml:\"ExpectedDepartureTime\""
-				}{
+				MonitoredCall: traveline.MonitoredCall{
 					AimedDepartureTime:    "2020-03-30T12:34:56.911+01:00",
 					ExpectedDepartureTime: "2020-03-30T12:34:56.911+01:00",
 				},
@@ -78,6 +81,7 @@ func TestGetNextTravel(t *testing.T) {
 				DirectionName:         "Xanadu",
 				AimedDepartureTime:    &nextDepartureTime,
 				ExpectedDepartureTime: &nextDepartureTime,
+				Source:                transport.LiveSource,
 			},
 		},
 		{
@@ -88,10 +92,7 @@ func TestGetNextTravel(t *testing.T) {
 				VehicleMode:       "magic carpet",
 				PublishedLineName: "flying",
 				DirectionName:     "Xanadu",
-				MonitoredCall: struct {
-					AimedDepartureTime    string "xml:\"AimedDepartureTime\""
-					ExpectedDepartureTime string "xml:\"ExpectedDepartureTime\""
-				}{
+				MonitoredCall: traveline.MonitoredCall{
 					AimedDepartureTime: "2020-03-30T12:34:56.911+01:00",
 				},
 			},
@@ -100,6 +101,31 @@ func TestGetNextTravel(t *testing.T) {
 				LineName:           "flying",
 				DirectionName:      "Xanadu",
 				AimedDepartureTime: &nextDepartureTime,
+				Source:             transport.LiveSource,
+			},
+		},
+		{
+			name:       "Request options are sent",
+			naptanCode: "123456789",
+			when:       now,
+			options: traveline.RequestOptions{
+				PreviewInterval:          90 * time.Minute,
+				MinimumStopVisitsPerLine: 1,
+			},
+			parseResult: &traveline.MonitoredVehicleJourney{
+				VehicleMode:       "magic carpet",
+				PublishedLineName: "flying",
+				DirectionName:     "Xanadu",
+				MonitoredCall: traveline.MonitoredCall{
+					AimedDepartureTime: "2020-03-30T12:34:56.911+01:00",
+				},
+			},
+			expectedResult: &transport.DepartureInfo{
+				VehicleMode:        "magic carpet",
+				LineName:           "flying",
+				DirectionName:      "Xanadu",
+				AimedDepartureTime: &nextDepartureTime,
+				Source:             transport.LiveSource,
 			},
 		},
 		{
@@ -110,10 +136,7 @@ func TestGetNextTravel(t *testing.T) {
 				VehicleMode:       "magic carpet",
 				PublishedLineName: "flying",
 				DirectionName:     "Xanadu",
-				MonitoredCall: struct {
-					AimedDepartureTime    string "xml:\"AimedDepartureTime\""
-					ExpectedDepartureTime string "xml:\"ExpectedDepartureTime\""
-				}{
+				MonitoredCall: traveline.MonitoredCall{
 					AimedDepartureTime: "bongo",
 				},
 			},
@@ -130,10 +153,7 @@ func TestGetNextTravel(t *testing.T) {
 				VehicleMode:       "magic carpet",
 				PublishedLineName: "flying",
 				DirectionName:     "Xanadu",
-				MonitoredCall: struct {
-					AimedDepartureTime    string "xml:\"AimedDepartureTime\""
-					ExpectedDepartureTime string "xml:\"ExpectedDepartureTime\""
-				}{
+				MonitoredCall: traveline.MonitoredCall{
 					AimedDepartureTime:    "2020-03-30T12:34:56.911+01:00",
 					ExpectedDepartureTime: "bango",
 				},
@@ -177,7 +197,7 @@ func TestGetNextTravel(t *testing.T) {
 
 			mockAPI.
 				EXPECT().
-				BuildServiceRequest(matcher.IsGUID(), gomock.Eq(test.naptanCode), gomock.Eq(test.when)).
+				BuildServiceRequest(matcher.IsGUID(), gomock.Eq(test.naptanCode), gomock.Eq(test.when), gomock.Eq(test.options)).
 				Return("<request/>", test.buildError).
 				AnyTimes()
 			mockAPI.
@@ -192,6 +212,7 @@ func TestGetNextTravel(t *testing.T) {
 				AnyTimes()
 
 			req := transport.NewTraveline(mockAPI)
+			req.RequestOptions = test.options
 
 			result, err := req.GetNextDepartureTime(test.naptanCode, test.when)
 
@@ -214,3 +235,466 @@ func TestGetNextTravel(t *testing.T) {
 		})
 	}
 }
+
+func TestGetNextTravelValidatesStopCode(t *testing.T) {
+	now := time.Now()
+	nextDepartureTime, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56.911+01:00")
+
+	stops := naptan.NewDataset([]naptan.Stop{
+		{ATCOCode: "020035811", NaptanCode: "bedadgmt", CommonName: "The Green"},
+	})
+
+	tests := []struct {
+		name                  string
+		naptanCode            string
+		stops                 *naptan.Dataset
+		expectedMonitoringRef string
+		expectedError         error
+	}{
+		{
+			name:                  "Valid ATCO code",
+			naptanCode:            " 0200bda00101 ",
+			expectedMonitoringRef: "0200BDA00101",
+		},
+		{
+			name:          "Invalid code",
+			naptanCode:    "02003581!",
+			expectedError: errors.New(`Invalid stop code "02003581!": invalid character '!'`),
+		},
+		{
+			name:                  "ATCO code found in stops",
+			naptanCode:            "020035811",
+			stops:                 stops,
+			expectedMonitoringRef: "020035811",
+		},
+		{
+			name:                  "NaPTAN code resolved to ATCO code",
+			naptanCode:            "bedadgmt",
+			stops:                 stops,
+			expectedMonitoringRef: "020035811",
+		},
+		{
+			name:          "Code not found in stops",
+			naptanCode:    "020035812",
+			stops:         stops,
+			expectedError: errors.New(`Invalid stop code "020035812": stop not found`),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAPI := mock_traveline.NewMockAPI(ctrl)
+
+			if test.expectedError == nil {
+				mockAPI.
+					EXPECT().
+					BuildServiceRequest(matcher.IsGUID(), gomock.Eq(test.expectedMonitoringRef), gomock.Eq(now), gomock.Any()).
+					Return("<request/>", nil)
+				mockAPI.
+					EXPECT().
+					Send(gomock.Eq("<request/>")).
+					Return("<response/>", nil)
+				mockAPI.
+					EXPECT().
+					ParseServiceDelivery(gomock.Eq("<response/>")).
+					Return(&traveline.MonitoredVehicleJourney{
+						MonitoredCall: traveline.MonitoredCall{
+							AimedDepartureTime: "2020-03-30T12:34:56.911+01:00",
+						},
+					}, nil)
+			}
+
+			req := transport.NewTraveline(mockAPI)
+			req.Stops = test.stops
+
+			result, err := req.GetNextDepartureTime(test.naptanCode, now)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			if diff := cmp.Diff(&transport.DepartureInfo{AimedDepartureTime: &nextDepartureTime, Source: transport.LiveSource}, result); diff != "" {
+				t.Errorf("GetNextDepartureTime() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetNextTravelChecksDiscovery(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	stopMonitoring, err := os.ReadFile("../traveline/testdata/stop_monitoring.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	stopPoints := `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><StopPointsDelivery>` +
+		`<AnnotatedStopPointRef><StopPointRef>020035811</StopPointRef></AnnotatedStopPointRef>` +
+		`</StopPointsDelivery></Siri>`
+
+	tests := []struct {
+		name                 string
+		naptanCode           string
+		stopPointsStatusCode int
+		stopPoints           string
+		expectedLine         string
+		expectedError        error
+	}{
+		{
+			name:                 "Stop covered",
+			naptanCode:           "020035811",
+			stopPointsStatusCode: http.StatusOK,
+			stopPoints:           stopPoints,
+			expectedLine:         "42",
+		},
+		{
+			name:                 "Stop not covered",
+			naptanCode:           "020035812",
+			stopPointsStatusCode: http.StatusOK,
+			stopPoints:           stopPoints,
+			expectedError:        errors.New(`Stop "020035812" is not covered by the producer`),
+		},
+		{
+			name:                 "Discovery fails",
+			naptanCode:           "020035812",
+			stopPointsStatusCode: http.StatusInternalServerError,
+			expectedLine:         "42",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := siritest.NewProducer(t).
+				WithAuth("TravelineAPI999", "letmein").
+				Respond("StopPointsRequest", test.stopPointsStatusCode, test.stopPoints).
+				Respond("StopMonitoringRequest", http.StatusOK, string(stopMonitoring))
+			defer server.Close()
+
+			api := &traveline.Client{
+				Username: "TravelineAPI999",
+				Password: "letmein",
+				URL:      server.URL,
+				Client:   server.Client(),
+			}
+			req := transport.NewTraveline(api)
+			req.Discovery = traveline.NewDiscovery(api, time.Hour)
+
+			result, err := req.GetNextDepartureTime(test.naptanCode, when)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if result.LineName != test.expectedLine {
+				t.Errorf("Expected line %s; got %s", test.expectedLine, result.LineName)
+			}
+		})
+	}
+}
+
+func TestGetNextTravelWithCalls(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	stopMonitoring, err := os.ReadFile("../traveline/testdata/stop_monitoring_calls.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	parseTime := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &parsed
+	}
+
+	server := siritest.NewProducer(t).
+		WithAuth("TravelineAPI999", "letmein").
+		Respond("StopMonitoringRequest", http.StatusOK, string(stopMonitoring))
+	defer server.Close()
+
+	api := &traveline.Client{
+		Username: "TravelineAPI999",
+		Password: "letmein",
+		URL:      server.URL,
+		Client:   server.Client(),
+	}
+	req := transport.NewTraveline(api)
+	req.RequestOptions = traveline.RequestOptions{DetailLevel: traveline.CallsDetail}
+
+	result, err := req.GetNextDepartureTime("020035811", when)
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	expectedPreviousCalls := []transport.PredictedCall{
+		{
+			StopPointRef:        "020035800",
+			StopPointName:       "Toddington, The Green",
+			Order:               1,
+			AimedDepartureTime:  parseTime("2020-03-30T12:30:00+01:00"),
+			ActualDepartureTime: parseTime("2020-03-30T12:32:00+01:00"),
+		},
+	}
+	if diff := cmp.Diff(expectedPreviousCalls, result.PreviousCalls); diff != "" {
+		t.Errorf("PreviousCalls (-want +got):\n%s", diff)
+	}
+
+	expectedOnwardCalls := []transport.PredictedCall{
+		{
+			StopPointRef:          "020035822",
+			StopPointName:         "High Street",
+			Order:                 3,
+			AimedArrivalTime:      parseTime("2020-03-30T12:44:00+01:00"),
+			ExpectedArrivalTime:   parseTime("2020-03-30T12:45:00+01:00"),
+			AimedDepartureTime:    parseTime("2020-03-30T12:44:00+01:00"),
+			ExpectedDepartureTime: parseTime("2020-03-30T12:45:30+01:00"),
+		},
+		{
+			StopPointRef:        "020035899",
+			StopPointName:       "City Centre",
+			Order:               4,
+			AimedArrivalTime:    parseTime("2020-03-30T12:45:00+01:00"),
+			ExpectedArrivalTime: parseTime("2020-03-30T12:47:00+01:00"),
+		},
+	}
+	if diff := cmp.Diff(expectedOnwardCalls, result.OnwardCalls); diff != "" {
+		t.Errorf("OnwardCalls (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetNextTravelStatus(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	response, err := os.ReadFile("../traveline/testdata/stop_monitoring_status.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tests := []struct {
+		name             string
+		excludeCancelled bool
+		response         string
+		expectedJourney  string
+		expectedStatus   transport.Status
+		expectedSource   string
+		expectedError    error
+	}{
+		{
+			name:            "Cancelled journey is returned",
+			response:        string(response),
+			expectedJourney: "1042",
+			expectedStatus:  transport.Cancelled,
+			expectedSource:  transport.LiveSource,
+		},
+		{
+			name:             "Cancelled journey is excluded",
+			excludeCancelled: true,
+			response:         string(response),
+			expectedJourney:  "2005",
+			expectedStatus:   transport.NoReport,
+			expectedSource:   transport.ScheduledSource,
+		},
+		{
+			name:             "Only cancelled journeys",
+			excludeCancelled: true,
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><StopMonitoringDelivery>` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><MonitoredCall>` +
+				`<AimedDepartureTime>2020-03-30T12:40:00+01:00</AimedDepartureTime><DepartureStatus>cancelled</DepartureStatus>` +
+				`</MonitoredCall></MonitoredVehicleJourney></MonitoredStopVisit>` +
+				`</StopMonitoringDelivery></ServiceDelivery></Siri>`,
+			expectedError: errors.New("No next departure times found"),
+		},
+		{
+			name: "Arrival status used without a departure status",
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><StopMonitoringDelivery>` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><FramedVehicleJourneyRef><DatedVehicleJourneyRef>1042</DatedVehicleJourneyRef></FramedVehicleJourneyRef>` +
+				`<Monitored>true</Monitored><MonitoredCall>` +
+				`<AimedDepartureTime>2020-03-30T12:40:00+01:00</AimedDepartureTime><ArrivalStatus>delayed</ArrivalStatus>` +
+				`</MonitoredCall></MonitoredVehicleJourney></MonitoredStopVisit>` +
+				`</StopMonitoringDelivery></ServiceDelivery></Siri>`,
+			expectedJourney: "1042",
+			expectedStatus:  transport.Delayed,
+			expectedSource:  transport.LiveSource,
+		},
+		{
+			name:             "Cancelled arrival is excluded",
+			excludeCancelled: true,
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><StopMonitoringDelivery>` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><MonitoredCall>` +
+				`<AimedDepartureTime>2020-03-30T12:40:00+01:00</AimedDepartureTime><ArrivalStatus>cancelled</ArrivalStatus>` +
+				`</MonitoredCall></MonitoredVehicleJourney></MonitoredStopVisit>` +
+				`</StopMonitoringDelivery></ServiceDelivery></Siri>`,
+			expectedError: errors.New("No next departure times found"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := siritest.NewProducer(t).
+				WithAuth("TravelineAPI999", "letmein").
+				Respond("StopMonitoringRequest", http.StatusOK, test.response)
+			defer server.Close()
+
+			api := &traveline.Client{
+				Username: "TravelineAPI999",
+				Password: "letmein",
+				URL:      server.URL,
+				Client:   server.Client(),
+			}
+			req := transport.NewTraveline(api)
+			req.ExcludeCancelled = test.excludeCancelled
+
+			result, err := req.GetNextDepartureTime("020035811", when)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			if result.JourneyRef != test.expectedJourney {
+				t.Errorf("Expected journey %s; got %s", test.expectedJourney, result.JourneyRef)
+			}
+			if result.Status != test.expectedStatus {
+				t.Errorf("Expected status %s; got %s", test.expectedStatus, result.Status)
+			}
+			if result.Source != test.expectedSource {
+				t.Errorf("Expected source %s; got %s", test.expectedSource, result.Source)
+			}
+		})
+	}
+}
+
+func TestGetNextTravelLite(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	response, err := os.ReadFile("../traveline/testdata/stop_monitoring_lite.json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stop-monitoring.json" || r.URL.Query().Get("MonitoringRef") != "020035811" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// The delivery answers the request by its message identifier
+		messageIdentifier := r.URL.Query().Get("MessageIdentifier")
+		_, _ = w.Write([]byte(strings.ReplaceAll(string(response), "ab7c1e9b-d06f-44cc-b190-4d36fb564386", messageIdentifier)))
+	}))
+	defer server.Close()
+
+	api := traveline.NewLiteClient("TravelineAPI999", "letmein", server.URL, server.Client())
+	req := transport.NewTraveline(api)
+	req.ExcludeCancelled = true
+
+	result, err := req.GetNextDepartureTime("020035811", when)
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	if result.JourneyRef != "2005" {
+		t.Errorf("Expected journey 2005; got %s", result.JourneyRef)
+	}
+	if result.LineName != "X5" || result.DirectionName != "Bedford" {
+		t.Errorf("Expected X5 to Bedford; got %s to %s", result.LineName, result.DirectionName)
+	}
+	if result.Source != transport.ScheduledSource {
+		t.Errorf("Expected source %s; got %s", transport.ScheduledSource, result.Source)
+	}
+}
+
+func TestGetNextTravelSkipsTerminatingJourneys(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	parseTime := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &parsed
+	}
+
+	tests := []struct {
+		name           string
+		response       string
+		expectedResult *transport.DepartureInfo
+	}{
+		{
+			name: "Journey terminating at the stop is skipped",
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><StopMonitoringDelivery>` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><PublishedLineName>X5</PublishedLineName>` +
+				`<DestinationRef>020035811</DestinationRef>` +
+				`<MonitoredCall><AimedArrivalTime>2020-03-30T12:38:00+01:00</AimedArrivalTime></MonitoredCall>` +
+				`</MonitoredVehicleJourney></MonitoredStopVisit>` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><PublishedLineName>42</PublishedLineName>` +
+				`<MonitoredCall><ExpectedDepartureTime>2020-03-30T12:45:00+01:00</ExpectedDepartureTime></MonitoredCall>` +
+				`</MonitoredVehicleJourney></MonitoredStopVisit>` +
+				`</StopMonitoringDelivery></ServiceDelivery></Siri>`,
+			expectedResult: &transport.DepartureInfo{
+				LineName:              "42",
+				ExpectedDepartureTime: parseTime("2020-03-30T12:45:00+01:00"),
+				Source:                transport.LiveSource,
+			},
+		},
+		{
+			name: "Journey through the stop without a departure time departs on arrival",
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><StopMonitoringDelivery>` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><PublishedLineName>X5</PublishedLineName>` +
+				`<DestinationRef>0200BDA00101</DestinationRef><MonitoredCall>` +
+				`<AimedArrivalTime>2020-03-30T12:38:00+01:00</AimedArrivalTime>` +
+				`<ExpectedArrivalTime>2020-03-30T12:39:00+01:00</ExpectedArrivalTime></MonitoredCall>` +
+				`</MonitoredVehicleJourney></MonitoredStopVisit>` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><PublishedLineName>42</PublishedLineName>` +
+				`<MonitoredCall><ExpectedDepartureTime>2020-03-30T12:45:00+01:00</ExpectedDepartureTime></MonitoredCall>` +
+				`</MonitoredVehicleJourney></MonitoredStopVisit>` +
+				`</StopMonitoringDelivery></ServiceDelivery></Siri>`,
+			expectedResult: &transport.DepartureInfo{
+				LineName:              "X5",
+				AimedDepartureTime:    parseTime("2020-03-30T12:38:00+01:00"),
+				ExpectedDepartureTime: parseTime("2020-03-30T12:39:00+01:00"),
+				Source:                transport.LiveSource,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		for _, excludeCancelled := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s with ExcludeCancelled %t", test.name, excludeCancelled), func(t *testing.T) {
+				server := siritest.NewProducer(t).
+					WithAuth("TravelineAPI999", "letmein").
+					Respond("StopMonitoringRequest", http.StatusOK, test.response)
+				defer server.Close()
+
+				req := transport.NewTraveline(&traveline.Client{
+					Username: "TravelineAPI999",
+					Password: "letmein",
+					URL:      server.URL,
+					Client:   server.Client(),
+				})
+				req.ExcludeCancelled = excludeCancelled
+
+				result, err := req.GetNextDepartureTime("020035811", when)
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+
+				if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+					t.Errorf("GetNextDepartureTime() (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"log"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/uuid"
+)
+
+// VehiclePosition represents the live position of a vehicle on a journey
+type VehiclePosition struct {
+	VehicleRef      string
+	LineName        string
+	OperatorRef     string
+	DirectionRef    string
+	DestinationName string
+	JourneyRef      string
+	Latitude        float64
+	Longitude       float64
+	// Bearing is in degrees clockwise from north
+	Bearing float64
+	// Delay is how late the vehicle is running, negative if it is early, zero if it is not known
+	Delay      time.Duration
+	RecordedAt *time.Time
+}
+
+// GetVehiclePositions returns the positions of the vehicles on the line or run by the operator,
+// either can be empty to not filter by it
+func (c *Traveline) GetVehiclePositions(lineRef string, operatorRef string, when time.Time) ([]VehiclePosition, error) {
+	filter := traveline.VehicleFilter{LineRef: lineRef, OperatorRef: operatorRef}
+	if err := c.checkLine(lineRef, when); err != nil {
+		return nil, err
+	}
+
+	requestRef := uuid.New().String()
+	request, err := c.API.BuildVehicleMonitoringRequest(requestRef, filter, when)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.API.Send(request)
+	if err != nil {
+		return nil, err
+	}
+
+	activities, err := c.API.ParseVehicleMonitoringDelivery(requestRef, response)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := []VehiclePosition{}
+	for _, activity := range activities {
+		if !filter.Matches(activity) {
+			continue
+		}
+
+		journey := activity.MonitoredVehicleJourney
+		position := VehiclePosition{
+			VehicleRef:      journey.VehicleRef,
+			LineName:        journey.PublishedLineName,
+			OperatorRef:     journey.OperatorRef,
+			DirectionRef:    journey.DirectionRef,
+			DestinationName: journey.DestinationName,
+			JourneyRef:      journey.FramedVehicleJourneyRef.DatedVehicleJourneyRef,
+			Latitude:        journey.VehicleLocation.Latitude,
+			Longitude:       journey.VehicleLocation.Longitude,
+			Bearing:         journey.Bearing,
+		}
+		if position.LineName == "" {
+			position.LineName = journey.LineRef
+		}
+
+		// The delay is supplementary, so the position is still returned without it
+		if journey.Delay != "" {
+			delay, err := traveline.ParseDuration(journey.Delay)
+			if err != nil {
+				log.Printf("Ignoring delay of vehicle %s: %s", journey.VehicleRef, err)
+			}
+			position.Delay = delay
+		}
+
+		if activity.RecordedAtTime != "" {
+			recordedAt, err := convertDepartureTime(activity.RecordedAtTime)
+			if err != nil {
+				return nil, err
+			}
+			position.RecordedAt = &recordedAt
+		}
+
+		positions = append(positions, position)
+	}
+
+	return positions, nil
+}
@@ -0,0 +1,162 @@
+package transport_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/internal/siritest"
+	"github.com/conradhodge/travel-api-client/transport"
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetVehiclePositions(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	response, err := os.ReadFile("../traveline/testdata/vehicle_monitoring.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	parse := func(value string) *time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return &parsed
+	}
+
+	first := transport.VehiclePosition{
+		VehicleRef:      "GLBE-1001",
+		LineName:        "42",
+		OperatorRef:     "GLBE",
+		DirectionRef:    "outbound",
+		DestinationName: "Toddington",
+		JourneyRef:      "1234",
+		Latitude:        51.94911,
+		Longitude:       -0.53385,
+		Bearing:         180,
+		Delay:           150 * time.Second,
+		RecordedAt:      parse("2020-03-30T12:34:40+01:00"),
+	}
+	second := transport.VehiclePosition{
+		VehicleRef:      "ABCD-2002",
+		LineName:        "42",
+		OperatorRef:     "ABCD",
+		DirectionRef:    "inbound",
+		DestinationName: "Bedford",
+		Latitude:        52.13757,
+		Longitude:       -0.47081,
+		Delay:           -time.Minute,
+		RecordedAt:      parse("2020-03-30T12:34:50+01:00"),
+	}
+
+	tests := []struct {
+		name           string
+		lineRef        string
+		operatorRef    string
+		statusCode     int
+		response       string
+		expectedError  error
+		expectedResult []transport.VehiclePosition
+	}{
+		{
+			name:           "Vehicles on line",
+			lineRef:        "42",
+			statusCode:     http.StatusOK,
+			response:       string(response),
+			expectedResult: []transport.VehiclePosition{first, second},
+		},
+		{
+			name:           "Vehicles filtered by operator",
+			operatorRef:    "ABCD",
+			statusCode:     http.StatusOK,
+			response:       string(response),
+			expectedResult: []transport.VehiclePosition{second},
+		},
+		{
+			name:           "No vehicles",
+			lineRef:        "43",
+			statusCode:     http.StatusOK,
+			response:       string(response),
+			expectedResult: []transport.VehiclePosition{},
+		},
+		{
+			name:       "Invalid delay is ignored",
+			statusCode: http.StatusOK,
+			response: `<Siri><ServiceDelivery><VehicleMonitoringDelivery><VehicleActivity>` +
+				`<MonitoredVehicleJourney><LineRef>42</LineRef><Delay>late</Delay>` +
+				`<VehicleRef>GLBE-1001</VehicleRef></MonitoredVehicleJourney>` +
+				`</VehicleActivity></VehicleMonitoringDelivery></ServiceDelivery></Siri>`,
+			expectedResult: []transport.VehiclePosition{{VehicleRef: "GLBE-1001", LineName: "42"}},
+		},
+		{
+			name:          "Error status",
+			statusCode:    http.StatusUnauthorized,
+			response:      "Invalid user credentials",
+			expectedError: errors.New("error status from API: 401"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := siritest.NewProducer(t).
+				WithAuth("TravelineAPI999", "letmein").
+				Respond("VehicleMonitoringRequest", test.statusCode, test.response)
+			defer server.Close()
+
+			req := transport.NewTraveline(&traveline.Client{
+				Username: "TravelineAPI999",
+				Password: "letmein",
+				URL:      server.URL,
+				Client:   server.Client(),
+			})
+
+			result, err := req.GetVehiclePositions(test.lineRef, test.operatorRef, when)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if diff := cmp.Diff(test.expectedResult, result); diff != "" {
+				t.Errorf("GetVehiclePositions() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetVehiclePositionsChecksDiscovery(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	lines := `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><LinesDelivery>` +
+		`<AnnotatedLineRef><LineRef>GLBE:42</LineRef><LineName>42</LineName></AnnotatedLineRef>` +
+		`</LinesDelivery></Siri>`
+
+	server := siritest.NewProducer(t).
+		WithAuth("TravelineAPI999", "letmein").
+		Respond("LinesRequest", http.StatusOK, lines)
+	defer server.Close()
+
+	api := &traveline.Client{
+		Username: "TravelineAPI999",
+		Password: "letmein",
+		URL:      server.URL,
+		Client:   server.Client(),
+	}
+	req := transport.NewTraveline(api)
+	req.Discovery = traveline.NewDiscovery(api, time.Hour)
+
+	_, err := req.GetVehiclePositions("X39", "", when)
+
+	expectedError := `Line "X39" is not covered by the producer`
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf("Expected error '%s'; got '%v'", expectedError, err)
+	}
+}
@@ -0,0 +1,177 @@
+package transxchange
+
+import "time"
+
+// Bank holidays that can be named in a BankHolidayOperation
+const (
+	NewYearsDay                      = "NewYearsDay"
+	Jan2ndScotland                   = "Jan2ndScotland"
+	GoodFriday                       = "GoodFriday"
+	EasterMonday                     = "EasterMonday"
+	MayDay                           = "MayDay"
+	SpringBank                       = "SpringBank"
+	LateSummerBankHolidayNotScotland = "LateSummerBankHolidayNotScotland"
+	AugustBankHolidayScotland        = "AugustBankHolidayScotland"
+	StAndrewsDay                     = "StAndrewsDay"
+	ChristmasEve                     = "ChristmasEve"
+	ChristmasDay                     = "ChristmasDay"
+	BoxingDay                        = "BoxingDay"
+	NewYearsEve                      = "NewYearsEve"
+	NewYearsDayHoliday               = "NewYearsDayHoliday"
+	Jan2ndScotlandHoliday            = "Jan2ndScotlandHoliday"
+	StAndrewsDayHoliday              = "StAndrewsDayHoliday"
+	ChristmasDayHoliday              = "ChristmasDayHoliday"
+	BoxingDayHoliday                 = "BoxingDayHoliday"
+)
+
+// bankHolidayGroups are the names that refer to several bank holidays
+var bankHolidayGroups = map[string][]string{
+	"AllBankHolidays": {
+		NewYearsDay, Jan2ndScotland, GoodFriday, EasterMonday, MayDay, SpringBank,
+		LateSummerBankHolidayNotScotland, AugustBankHolidayScotland, StAndrewsDay,
+		ChristmasDay, BoxingDay, NewYearsDayHoliday, Jan2ndScotlandHoliday,
+		StAndrewsDayHoliday, ChristmasDayHoliday, BoxingDayHoliday,
+	},
+	"AllHolidaysExceptChristmas": {
+		NewYearsDay, Jan2ndScotland, GoodFriday, EasterMonday, MayDay, SpringBank,
+		LateSummerBankHolidayNotScotland, AugustBankHolidayScotland, StAndrewsDay,
+		NewYearsDayHoliday, Jan2ndScotlandHoliday, StAndrewsDayHoliday,
+	},
+	"Christmas":            {ChristmasDay, BoxingDay},
+	"DisplacementHolidays": {NewYearsDayHoliday, Jan2ndScotlandHoliday, StAndrewsDayHoliday, ChristmasDayHoliday, BoxingDayHoliday},
+	"EarlyRunOff":          {ChristmasEve, NewYearsEve},
+	"HolidayMondays":       {EasterMonday, MayDay, SpringBank, LateSummerBankHolidayNotScotland, AugustBankHolidayScotland},
+}
+
+// movedBankHolidays are the years when a bank holiday was moved from its usual date by proclamation
+var movedBankHolidays = map[int]map[string]string{
+	1995: {MayDay: "1995-05-08"},
+	2002: {SpringBank: "2002-06-04"},
+	2012: {SpringBank: "2012-06-04"},
+	2020: {MayDay: "2020-05-08"},
+	2022: {SpringBank: "2022-06-02"},
+}
+
+// bankHolidays returns the names of the bank holidays on the date
+func bankHolidays(date time.Time) []string {
+	day := date.Format(dateFormat)
+
+	var names []string
+	for name, holiday := range bankHolidayDates(date.Year()) {
+		if holiday == day {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// bankHolidayDates returns the dates of the bank holidays in the year, in the format 2006-01-02
+func bankHolidayDates(year int) map[string]string {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	easter := easterSunday(year)
+
+	dates := map[string]time.Time{
+		NewYearsDay:                      date(time.January, 1),
+		Jan2ndScotland:                   date(time.January, 2),
+		GoodFriday:                       easter.AddDate(0, 0, -2),
+		EasterMonday:                     easter.AddDate(0, 0, 1),
+		MayDay:                           firstMonday(date(time.May, 1)),
+		SpringBank:                       lastMonday(date(time.May, 31)),
+		AugustBankHolidayScotland:        firstMonday(date(time.August, 1)),
+		LateSummerBankHolidayNotScotland: lastMonday(date(time.August, 31)),
+		StAndrewsDay:                     date(time.November, 30),
+		ChristmasEve:                     date(time.December, 24),
+		ChristmasDay:                     date(time.December, 25),
+		BoxingDay:                        date(time.December, 26),
+		NewYearsEve:                      date(time.December, 31),
+	}
+
+	// Holidays that fall at the weekend are replaced by the next weekday that isn't already a holiday
+	substitutes := []struct {
+		name    string
+		holiday string
+		taken   []string
+	}{
+		{name: NewYearsDayHoliday, holiday: NewYearsDay},
+		{name: Jan2ndScotlandHoliday, holiday: Jan2ndScotland, taken: []string{NewYearsDay, NewYearsDayHoliday}},
+		{name: StAndrewsDayHoliday, holiday: StAndrewsDay},
+		{name: ChristmasDayHoliday, holiday: ChristmasDay, taken: []string{BoxingDay}},
+		{name: BoxingDayHoliday, holiday: BoxingDay, taken: []string{ChristmasDay, ChristmasDayHoliday}},
+	}
+	for _, substitute := range substitutes {
+		holiday := dates[substitute.holiday]
+		if !isWeekend(holiday) {
+			continue
+		}
+
+		d := holiday.AddDate(0, 0, 1)
+		for isWeekend(d) || isTaken(d, dates, substitute.taken) {
+			d = d.AddDate(0, 0, 1)
+		}
+		dates[substitute.name] = d
+	}
+
+	formatted := make(map[string]string, len(dates))
+	for name, d := range dates {
+		formatted[name] = d.Format(dateFormat)
+	}
+	for name, d := range movedBankHolidays[year] {
+		formatted[name] = d
+	}
+
+	return formatted
+}
+
+// easterSunday returns the date of Easter Sunday in the year, using the anonymous Gregorian algorithm
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func firstMonday(date time.Time) time.Time {
+	for date.Weekday() != time.Monday {
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return date
+}
+
+func lastMonday(date time.Time) time.Time {
+	for date.Weekday() != time.Monday {
+		date = date.AddDate(0, 0, -1)
+	}
+
+	return date
+}
+
+func isWeekend(date time.Time) bool {
+	return date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+}
+
+// isTaken returns whether the date is one of the named holidays
+func isTaken(date time.Time, dates map[string]time.Time, names []string) bool {
+	for _, name := range names {
+		if d, ok := dates[name]; ok && d.Equal(date) {
+			return true
+		}
+	}
+
+	return false
+}
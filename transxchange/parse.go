@@ -0,0 +1,449 @@
+package transxchange
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/pkg/errors"
+)
+
+// timezone is the timezone of the times in TransXChange documents, which are always UK local time
+const timezone = "Europe/London"
+
+type document struct {
+	StopPoints []struct {
+		StopPointRef string `xml:"StopPointRef"`
+		CommonName   string `xml:"CommonName"`
+	} `xml:"StopPoints>AnnotatedStopPointRef"`
+	JourneyPatternSections []struct {
+		ID          string `xml:"id,attr"`
+		TimingLinks []struct {
+			From    stopUsage `xml:"From"`
+			To      stopUsage `xml:"To"`
+			RunTime string    `xml:"RunTime"`
+		} `xml:"JourneyPatternTimingLink"`
+	} `xml:"JourneyPatternSections>JourneyPatternSection"`
+	Operators []struct {
+		ID                   string `xml:"id,attr"`
+		NationalOperatorCode string `xml:"NationalOperatorCode"`
+		OperatorCode         string `xml:"OperatorCode"`
+		OperatorShortName    string `xml:"OperatorShortName"`
+	} `xml:"Operators>Operator"`
+	Services []struct {
+		ServiceCode string `xml:"ServiceCode"`
+		Lines       []struct {
+			ID       string `xml:"id,attr"`
+			LineName string `xml:"LineName"`
+		} `xml:"Lines>Line"`
+		OperatingPeriod       DateRange         `xml:"OperatingPeriod"`
+		OperatingProfile      *operatingProfile `xml:"OperatingProfile"`
+		RegisteredOperatorRef string            `xml:"RegisteredOperatorRef"`
+		Mode                  string            `xml:"Mode"`
+		StandardService       struct {
+			Origin          string `xml:"Origin"`
+			Destination     string `xml:"Destination"`
+			JourneyPatterns []struct {
+				ID                        string   `xml:"id,attr"`
+				DestinationDisplay        string   `xml:"DestinationDisplay"`
+				Direction                 string   `xml:"Direction"`
+				JourneyPatternSectionRefs []string `xml:"JourneyPatternSectionRefs"`
+			} `xml:"JourneyPattern"`
+		} `xml:"StandardService"`
+	} `xml:"Services>Service"`
+	VehicleJourneys []struct {
+		OperatingProfile   *operatingProfile `xml:"OperatingProfile"`
+		VehicleJourneyCode string            `xml:"VehicleJourneyCode"`
+		ServiceRef         string            `xml:"ServiceRef"`
+		LineRef            string            `xml:"LineRef"`
+		JourneyPatternRef  string            `xml:"JourneyPatternRef"`
+		VehicleJourneyRef  string            `xml:"VehicleJourneyRef"`
+		DepartureTime      string            `xml:"DepartureTime"`
+		DepartureDayShift  int               `xml:"DepartureDayShift"`
+	} `xml:"VehicleJourneys>VehicleJourney"`
+}
+
+type stopUsage struct {
+	StopPointRef string `xml:"StopPointRef"`
+	Activity     string `xml:"Activity"`
+	WaitTime     string `xml:"WaitTime"`
+}
+
+type operatingProfile struct {
+	RegularDayType *struct {
+		DaysOfWeek   *elementNames `xml:"DaysOfWeek"`
+		HolidaysOnly *struct{}     `xml:"HolidaysOnly"`
+	} `xml:"RegularDayType"`
+	DaysOfOperation      []DateRange `xml:"SpecialDaysOperation>DaysOfOperation>DateRange"`
+	DaysOfNonOperation   []DateRange `xml:"SpecialDaysOperation>DaysOfNonOperation>DateRange"`
+	BankHolidayOperation struct {
+		DaysOfOperation    holidays `xml:"DaysOfOperation"`
+		DaysOfNonOperation holidays `xml:"DaysOfNonOperation"`
+	} `xml:"BankHolidayOperation"`
+}
+
+// elementNames stores the names of the child elements, e.g. <MondayToFriday/>
+type elementNames struct {
+	Elements []struct {
+		XMLName xml.Name
+	} `xml:",any"`
+}
+
+// holidays stores the bank holidays, as elements named after them, and any other public holidays
+type holidays struct {
+	elementNames
+	OtherPublicHolidays []struct {
+		Date string `xml:"Date"`
+	} `xml:"OtherPublicHoliday"`
+}
+
+// daysOfWeek are the days that each DaysOfWeek element represents
+var daysOfWeek = map[string][]time.Weekday{
+	"Monday":           {time.Monday},
+	"Tuesday":          {time.Tuesday},
+	"Wednesday":        {time.Wednesday},
+	"Thursday":         {time.Thursday},
+	"Friday":           {time.Friday},
+	"Saturday":         {time.Saturday},
+	"Sunday":           {time.Sunday},
+	"MondayToFriday":   {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"MondayToSaturday": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+	"MondayToSunday":   {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday},
+	"Weekend":          {time.Saturday, time.Sunday},
+	"NotMonday":        {time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday},
+	"NotTuesday":       {time.Monday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday},
+	"NotWednesday":     {time.Monday, time.Tuesday, time.Thursday, time.Friday, time.Saturday, time.Sunday},
+	"NotThursday":      {time.Monday, time.Tuesday, time.Wednesday, time.Friday, time.Saturday, time.Sunday},
+	"NotFriday":        {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Saturday, time.Sunday},
+	"NotSaturday":      {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Sunday},
+	"NotSunday":        {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+}
+
+// Load returns the timetable from the TransXChange files, a service is often split across several files
+func Load(paths ...string) (*Timetable, error) {
+	timetable, err := newTimetable()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		if err := timetable.addFile(path); err != nil {
+			return nil, err
+		}
+	}
+	timetable.index()
+
+	return timetable, nil
+}
+
+// Parse returns the timetable from the TransXChange document
+func Parse(r io.Reader) (*Timetable, error) {
+	timetable, err := newTimetable()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := timetable.add(r); err != nil {
+		return nil, err
+	}
+	timetable.index()
+
+	return timetable, nil
+}
+
+func newTimetable() (*Timetable, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load timezone %s", timezone)
+	}
+
+	return &Timetable{
+		StopPoints: make(map[string]StopPoint),
+		Operators:  make(map[string]Operator),
+		Services:   make(map[string]*Service),
+		Location:   location,
+	}, nil
+}
+
+func (t *Timetable) addFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return errors.Wrapf(t.add(f), "%s", path)
+}
+
+// add adds the stops, operators, services and journeys in the document to the timetable
+func (t *Timetable) add(r io.Reader) error {
+	doc := document{}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return errors.Wrap(err, "cannot parse TransXChange document")
+	}
+
+	for _, stopPoint := range doc.StopPoints {
+		ref := strings.TrimSpace(stopPoint.StopPointRef)
+		t.StopPoints[strings.ToUpper(ref)] = StopPoint{
+			StopPointRef: ref,
+			CommonName:   strings.TrimSpace(stopPoint.CommonName),
+		}
+	}
+
+	for _, operator := range doc.Operators {
+		code := operator.NationalOperatorCode
+		if code == "" {
+			code = operator.OperatorCode
+		}
+		t.Operators[operator.ID] = Operator{
+			ID:        operator.ID,
+			Code:      code,
+			ShortName: operator.OperatorShortName,
+		}
+	}
+
+	sections := make(map[string][]TimingLink)
+	for _, section := range doc.JourneyPatternSections {
+		for _, link := range section.TimingLinks {
+			from, err := parseStopUsage(link.From)
+			if err != nil {
+				return err
+			}
+			to, err := parseStopUsage(link.To)
+			if err != nil {
+				return err
+			}
+			runTime, err := parseDuration(link.RunTime)
+			if err != nil {
+				return err
+			}
+
+			sections[section.ID] = append(sections[section.ID], TimingLink{From: from, To: to, RunTime: runTime})
+		}
+	}
+
+	// The operating profile of each service in the document, for its journeys that don't have their own
+	// when the service was loaded from an earlier file with a different profile
+	profiles := make(map[string]*OperatingProfile)
+	// The services as they are in this document, as the IDs of their lines and patterns are only unique within it
+	services := make(map[string]*Service)
+	for _, s := range doc.Services {
+		service := &Service{
+			Code:            s.ServiceCode,
+			Lines:           make(map[string]string),
+			Mode:            s.Mode,
+			OperatorRef:     s.RegisteredOperatorRef,
+			Origin:          s.StandardService.Origin,
+			Destination:     s.StandardService.Destination,
+			StartDate:       s.OperatingPeriod.StartDate,
+			EndDate:         s.OperatingPeriod.EndDate,
+			JourneyPatterns: make(map[string]*JourneyPattern),
+		}
+		if service.Mode == "" {
+			service.Mode = "bus"
+		}
+		if s.OperatingProfile != nil {
+			service.OperatingProfile = s.OperatingProfile.convert()
+		}
+
+		for _, line := range s.Lines {
+			service.Lines[line.ID] = line.LineName
+		}
+
+		for _, jp := range s.StandardService.JourneyPatterns {
+			pattern := &JourneyPattern{
+				ID:                 jp.ID,
+				Direction:          jp.Direction,
+				DestinationDisplay: jp.DestinationDisplay,
+			}
+			for _, ref := range jp.JourneyPatternSectionRefs {
+				links, ok := sections[ref]
+				if !ok {
+					return errors.Errorf("journey pattern %s refers to unknown section %s", jp.ID, ref)
+				}
+				pattern.TimingLinks = append(pattern.TimingLinks, links...)
+			}
+			service.JourneyPatterns[pattern.ID] = pattern
+		}
+
+		profiles[service.Code] = service.OperatingProfile
+		services[service.Code] = service
+		if existing, ok := t.Services[service.Code]; ok {
+			existing.merge(service)
+			continue
+		}
+		t.Services[service.Code] = service
+	}
+
+	journeys := make(map[string]*VehicleJourney)
+	for _, vj := range doc.VehicleJourneys {
+		departureTime, err := parseTimeOfDay(vj.DepartureTime)
+		if err != nil {
+			return errors.Wrapf(err, "vehicle journey %s", vj.VehicleJourneyCode)
+		}
+
+		journey := &VehicleJourney{
+			Code:              vj.VehicleJourneyCode,
+			ServiceRef:        vj.ServiceRef,
+			LineRef:           vj.LineRef,
+			JourneyPatternRef: vj.JourneyPatternRef,
+			DepartureTime:     departureTime + time.Duration(vj.DepartureDayShift)*24*time.Hour,
+		}
+		if vj.OperatingProfile != nil {
+			journey.OperatingProfile = vj.OperatingProfile.convert()
+		} else if service, ok := t.Services[journey.ServiceRef]; ok && service.OperatingProfile != profiles[journey.ServiceRef] {
+			journey.OperatingProfile = profiles[journey.ServiceRef]
+		}
+
+		// A journey can follow the same pattern as another journey rather than referring to it directly
+		if journey.JourneyPatternRef == "" && vj.VehicleJourneyRef != "" {
+			if ref, ok := journeys[vj.VehicleJourneyRef]; ok {
+				journey.JourneyPatternRef = ref.JourneyPatternRef
+			}
+		}
+
+		service, ok := services[journey.ServiceRef]
+		if !ok {
+			service, ok = t.Services[journey.ServiceRef]
+		}
+		if ok {
+			journey.LineName = service.Lines[journey.LineRef]
+			journey.JourneyPattern = service.JourneyPatterns[journey.JourneyPatternRef]
+		}
+
+		journeys[journey.Code] = journey
+		t.Journeys = append(t.Journeys, journey)
+	}
+
+	return nil
+}
+
+// merge adds the lines and journey patterns of the part of the service from another file that have IDs
+// not already used, the details that the service doesn't already have are taken from the other part
+func (s *Service) merge(other *Service) {
+	for id, name := range other.Lines {
+		if _, ok := s.Lines[id]; !ok {
+			s.Lines[id] = name
+		}
+	}
+	for id, pattern := range other.JourneyPatterns {
+		if _, ok := s.JourneyPatterns[id]; !ok {
+			s.JourneyPatterns[id] = pattern
+		}
+	}
+
+	if s.OperatorRef == "" {
+		s.OperatorRef = other.OperatorRef
+	}
+	if s.Origin == "" {
+		s.Origin = other.Origin
+	}
+	if s.Destination == "" {
+		s.Destination = other.Destination
+	}
+	if s.StartDate == "" || (other.StartDate != "" && other.StartDate < s.StartDate) {
+		s.StartDate = other.StartDate
+	}
+	// An open ended part makes the whole service open ended
+	if s.EndDate != "" && (other.EndDate == "" || other.EndDate > s.EndDate) {
+		s.EndDate = other.EndDate
+	}
+	if s.OperatingProfile == nil {
+		s.OperatingProfile = other.OperatingProfile
+	}
+}
+
+func parseStopUsage(usage stopUsage) (StopUsage, error) {
+	waitTime, err := parseDuration(usage.WaitTime)
+	if err != nil {
+		return StopUsage{}, err
+	}
+
+	return StopUsage{
+		StopPointRef: strings.TrimSpace(usage.StopPointRef),
+		Activity:     usage.Activity,
+		WaitTime:     waitTime,
+	}, nil
+}
+
+// convert returns the operating profile, a profile without a regular day type runs every day
+func (p *operatingProfile) convert() *OperatingProfile {
+	profile := &OperatingProfile{
+		DaysOfOperation:                   p.DaysOfOperation,
+		DaysOfNonOperation:                p.DaysOfNonOperation,
+		BankHolidaysOfOperation:           p.BankHolidayOperation.DaysOfOperation.names(),
+		BankHolidaysOfNonOperation:        p.BankHolidayOperation.DaysOfNonOperation.names(),
+		OtherPublicHolidaysOfOperation:    p.BankHolidayOperation.DaysOfOperation.dates(),
+		OtherPublicHolidaysOfNonOperation: p.BankHolidayOperation.DaysOfNonOperation.dates(),
+	}
+
+	switch {
+	case p.RegularDayType == nil || (p.RegularDayType.DaysOfWeek == nil && p.RegularDayType.HolidaysOnly == nil):
+		profile.DaysOfWeek = everyDay()
+	case p.RegularDayType.HolidaysOnly != nil:
+		profile.HolidaysOnly = true
+	default:
+		for _, element := range p.RegularDayType.DaysOfWeek.Elements {
+			for _, day := range daysOfWeek[element.XMLName.Local] {
+				profile.DaysOfWeek[day] = true
+			}
+		}
+	}
+
+	return profile
+}
+
+// names returns the bank holidays, with whether each was named explicitly rather than as part of a group
+func (h holidays) names() map[string]bool {
+	names := make(map[string]bool)
+	for _, element := range h.Elements {
+		name := element.XMLName.Local
+		group, ok := bankHolidayGroups[name]
+		if !ok {
+			names[name] = true
+			continue
+		}
+		for _, holiday := range group {
+			if _, ok := names[holiday]; !ok {
+				names[holiday] = false
+			}
+		}
+	}
+
+	return names
+}
+
+func (h holidays) dates() []string {
+	var dates []string
+	for _, holiday := range h.OtherPublicHolidays {
+		dates = append(dates, strings.TrimSpace(holiday.Date))
+	}
+
+	return dates
+}
+
+func everyDay() [7]bool {
+	return [7]bool{true, true, true, true, true, true, true}
+}
+
+// parseDuration returns the XML schema duration, e.g. PT1H5M, an empty value is zero
+func parseDuration(value string) (time.Duration, error) {
+	if strings.TrimSpace(value) == "" {
+		return 0, nil
+	}
+
+	return traveline.ParseDuration(value)
+}
+
+// parseTimeOfDay returns the time since midnight of the time in the format 15:04:05
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04:05", strings.TrimSpace(value))
+	if err != nil {
+		return 0, errors.Errorf("invalid departure time %q", value)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+}
@@ -0,0 +1,238 @@
+package transxchange_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/transxchange"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoad(t *testing.T) {
+	timetable, err := transxchange.Load("testdata/service.xml")
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	expectedOperators := map[string]transxchange.Operator{
+		"O1": {ID: "O1", Code: "FBRI", ShortName: "First Bristol"},
+	}
+	if diff := cmp.Diff(expectedOperators, timetable.Operators); diff != "" {
+		t.Fatalf("Unexpected operators (-want +got):\n%s", diff)
+	}
+
+	if len(timetable.StopPoints) != 3 {
+		t.Fatalf("Expected 3 stop points; got %d", len(timetable.StopPoints))
+	}
+
+	service, ok := timetable.Services["PB0000001:72"]
+	if !ok {
+		t.Fatalf("Expected service PB0000001:72")
+	}
+
+	weekdays := [7]bool{false, true, true, true, true, true, false}
+	expectedService := &transxchange.Service{
+		Code:        "PB0000001:72",
+		Lines:       map[string]string{"L1": "72"},
+		Mode:        "bus",
+		OperatorRef: "O1",
+		Origin:      "Temple Meads",
+		Destination: "Cribbs Causeway",
+		StartDate:   "2020-01-01",
+		EndDate:     "2020-12-31",
+		OperatingProfile: &transxchange.OperatingProfile{
+			DaysOfWeek:              weekdays,
+			BankHolidaysOfOperation: map[string]bool{},
+			BankHolidaysOfNonOperation: map[string]bool{
+				transxchange.NewYearsDay:                      false,
+				transxchange.Jan2ndScotland:                   false,
+				transxchange.GoodFriday:                       false,
+				transxchange.EasterMonday:                     false,
+				transxchange.MayDay:                           false,
+				transxchange.SpringBank:                       false,
+				transxchange.LateSummerBankHolidayNotScotland: false,
+				transxchange.AugustBankHolidayScotland:        false,
+				transxchange.StAndrewsDay:                     false,
+				transxchange.ChristmasDay:                     false,
+				transxchange.BoxingDay:                        false,
+				transxchange.NewYearsDayHoliday:               false,
+				transxchange.Jan2ndScotlandHoliday:            false,
+				transxchange.StAndrewsDayHoliday:              false,
+				transxchange.ChristmasDayHoliday:              false,
+				transxchange.BoxingDayHoliday:                 false,
+			},
+		},
+		JourneyPatterns: map[string]*transxchange.JourneyPattern{
+			"JP1": {
+				ID:                 "JP1",
+				Direction:          "outbound",
+				DestinationDisplay: "Cribbs Causeway",
+				TimingLinks: []transxchange.TimingLink{
+					{
+						From:    transxchange.StopUsage{StopPointRef: "0100BRP90310", Activity: "pickUp"},
+						To:      transxchange.StopUsage{StopPointRef: "0100BRP90311", WaitTime: 2 * time.Minute},
+						RunTime: 10 * time.Minute,
+					},
+					{
+						From:    transxchange.StopUsage{StopPointRef: "0100BRP90311", WaitTime: time.Minute},
+						To:      transxchange.StopUsage{StopPointRef: "0100BRP90312", Activity: "setDown"},
+						RunTime: 15 * time.Minute,
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedService, service); diff != "" {
+		t.Fatalf("Unexpected service (-want +got):\n%s", diff)
+	}
+
+	if len(timetable.Journeys) != 4 {
+		t.Fatalf("Expected 4 vehicle journeys; got %d", len(timetable.Journeys))
+	}
+
+	// The journey pattern is taken from the referenced journey
+	journey := timetable.Journeys[3]
+	if journey.JourneyPatternRef != "JP1" {
+		t.Fatalf("Expected journey pattern 'JP1'; got '%s'", journey.JourneyPatternRef)
+	}
+	if journey.DepartureTime != 12*time.Hour {
+		t.Fatalf("Expected departure time '%s'; got '%s'", 12*time.Hour, journey.DepartureTime)
+	}
+	if journey.OperatingProfile == nil || !journey.OperatingProfile.HolidaysOnly {
+		t.Fatalf("Expected holidays only operating profile")
+	}
+}
+
+func TestLoadServiceSplitAcrossFiles(t *testing.T) {
+	timetable, err := transxchange.Load("testdata/service.xml", "testdata/service_inbound.xml")
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	service, ok := timetable.Services["PB0000001:72"]
+	if !ok {
+		t.Fatalf("Expected service PB0000001:72")
+	}
+
+	if diff := cmp.Diff(map[string]string{"L1": "72", "L2": "72A"}, service.Lines); diff != "" {
+		t.Errorf("Unexpected lines (-want +got):\n%s", diff)
+	}
+	if _, ok := service.JourneyPatterns["JP1"]; !ok {
+		t.Errorf("Expected journey pattern JP1 from the first file")
+	}
+	if _, ok := service.JourneyPatterns["JP2"]; !ok {
+		t.Errorf("Expected journey pattern JP2 from the second file")
+	}
+	if service.StartDate != "2019-12-01" || service.EndDate != "" {
+		t.Errorf("Expected the service to run from 2019-12-01 and be open ended; got %s to %s", service.StartDate, service.EndDate)
+	}
+	if service.Origin != "Temple Meads" {
+		t.Errorf("Expected the origin of the first file; got %s", service.Origin)
+	}
+
+	// Journeys from both files are indexed, using the operating profile of the service in their own file
+	weekday, _ := time.Parse(time.RFC3339, "2020-03-30T07:00:00+01:00")
+	departures := timetable.Departures("0100BRP90310", weekday, 1)
+	if len(departures) != 1 || departures[0].Journey.Code != "VJ1" {
+		t.Fatalf("Expected weekday journey VJ1 from the first file; got %v", departures)
+	}
+	saturday, _ := time.Parse(time.RFC3339, "2020-03-28T09:30:00Z")
+	departures = timetable.Departures("0100BRP90312", saturday, 1)
+	if len(departures) != 1 || departures[0].Journey.Code != "VJ5" || departures[0].LineName != "72A" {
+		t.Fatalf("Expected Saturday journey VJ5 on line 72A from the second file; got %v", departures)
+	}
+	monday, _ := time.Parse(time.RFC3339, "2020-03-30T00:00:00+01:00")
+	departures = timetable.Departures("0100BRP90312", monday, 1)
+	if len(departures) != 1 || departures[0].Time.Weekday() != time.Saturday {
+		t.Fatalf("Expected the next VJ5 departure to be on Saturday; got %v", departures)
+	}
+}
+
+func TestLoadServiceSplitAcrossFilesWithSameIDs(t *testing.T) {
+	// Both files have journey pattern JP1, section JPS1 and line L1, in opposite directions
+	timetable, err := transxchange.Load("testdata/service.xml", "testdata/service_reused_ids.xml")
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	weekday, _ := time.Parse(time.RFC3339, "2020-03-30T07:00:00+01:00")
+	departures := timetable.Departures("0100BRP90310", weekday, 1)
+	if len(departures) != 1 || departures[0].Journey.Code != "VJ1" || departures[0].LineName != "72" ||
+		departures[0].JourneyPattern.Direction != "outbound" {
+		t.Fatalf("Expected outbound weekday journey VJ1 on line 72 from the first file; got %v", departures)
+	}
+
+	saturday, _ := time.Parse(time.RFC3339, "2020-03-28T09:30:00Z")
+	departures = timetable.Departures("0100BRP90312", saturday, 1)
+	if len(departures) != 1 || departures[0].Journey.Code != "VJ6" || departures[0].LineName != "72B" ||
+		departures[0].JourneyPattern.Direction != "inbound" {
+		t.Fatalf("Expected inbound Saturday journey VJ6 on line 72B from the second file; got %v", departures)
+	}
+}
+
+func TestLoadFileNotFound(t *testing.T) {
+	_, err := transxchange.Load("testdata/missing.xml")
+	if err == nil {
+		t.Fatalf("Expected error; got no error")
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		document      string
+		expectedError error
+	}{
+		{
+			name:     "Empty document",
+			document: `<TransXChange xmlns="http://www.transxchange.org.uk/"></TransXChange>`,
+		},
+		{
+			name:          "Invalid XML",
+			document:      `<TransXChange>`,
+			expectedError: errors.New("cannot parse TransXChange document: XML syntax error on line 1: unexpected EOF"),
+		},
+		{
+			name: "Invalid run time",
+			document: `<TransXChange><JourneyPatternSections><JourneyPatternSection id="JPS1">
+				<JourneyPatternTimingLink><RunTime>5 minutes</RunTime></JourneyPatternTimingLink>
+				</JourneyPatternSection></JourneyPatternSections></TransXChange>`,
+			expectedError: errors.New(`invalid duration: "5 minutes"`),
+		},
+		{
+			name: "Invalid departure time",
+			document: `<TransXChange><VehicleJourneys><VehicleJourney>
+				<VehicleJourneyCode>VJ1</VehicleJourneyCode><DepartureTime>7.30</DepartureTime>
+				</VehicleJourney></VehicleJourneys></TransXChange>`,
+			expectedError: errors.New(`vehicle journey VJ1: invalid departure time "7.30"`),
+		},
+		{
+			name: "Unknown journey pattern section",
+			document: `<TransXChange><Services><Service><ServiceCode>S1</ServiceCode><StandardService>
+				<JourneyPattern id="JP1"><JourneyPatternSectionRefs>JPS1</JourneyPatternSectionRefs></JourneyPattern>
+				</StandardService></Service></Services></TransXChange>`,
+			expectedError: errors.New("journey pattern JP1 refers to unknown section JPS1"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := transxchange.Parse(strings.NewReader(test.document))
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+		})
+	}
+}
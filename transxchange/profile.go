@@ -0,0 +1,91 @@
+package transxchange
+
+import "time"
+
+// dateFormat is the format of dates in TransXChange documents
+const dateFormat = "2006-01-02"
+
+// OperatingProfile represents the days that a vehicle journey or service runs
+type OperatingProfile struct {
+	// DaysOfWeek is indexed by time.Weekday
+	DaysOfWeek [7]bool
+	// HolidaysOnly is set if the journey only runs on the bank holidays of operation
+	HolidaysOnly bool
+	// DaysOfOperation and DaysOfNonOperation are special days that override all other rules
+	DaysOfOperation    []DateRange
+	DaysOfNonOperation []DateRange
+	// BankHolidaysOfOperation and BankHolidaysOfNonOperation map each bank holiday name to whether it was
+	// named explicitly, rather than as part of a group such as AllBankHolidays
+	BankHolidaysOfOperation    map[string]bool
+	BankHolidaysOfNonOperation map[string]bool
+	// OtherPublicHolidaysOfOperation and OtherPublicHolidaysOfNonOperation are one off holidays, e.g. a jubilee
+	OtherPublicHolidaysOfOperation    []string
+	OtherPublicHolidaysOfNonOperation []string
+}
+
+// RunsOn returns whether the profile runs on the date.
+// Special days take precedence, followed by bank holidays and then the days of the week.
+// An explicitly named bank holiday takes precedence over a group, e.g. running on GoodFriday
+// but not AllBankHolidays, and if both apply equally the journey does not run.
+func (p *OperatingProfile) RunsOn(date time.Time) bool {
+	day := date.Format(dateFormat)
+
+	for _, r := range p.DaysOfNonOperation {
+		if r.Contains(day) {
+			return false
+		}
+	}
+	for _, r := range p.DaysOfOperation {
+		if r.Contains(day) {
+			return true
+		}
+	}
+
+	if containsString(p.OtherPublicHolidaysOfNonOperation, day) {
+		return false
+	}
+	if containsString(p.OtherPublicHolidaysOfOperation, day) {
+		return true
+	}
+
+	holidays := bankHolidays(date)
+	operates, operatesExplicitly := matchHolidays(p.BankHolidaysOfOperation, holidays)
+	doesNotOperate, doesNotOperateExplicitly := matchHolidays(p.BankHolidaysOfNonOperation, holidays)
+	switch {
+	case operates && doesNotOperate:
+		return operatesExplicitly && !doesNotOperateExplicitly
+	case operates:
+		return true
+	case doesNotOperate:
+		return false
+	}
+
+	if p.HolidaysOnly {
+		return false
+	}
+
+	return p.DaysOfWeek[date.Weekday()]
+}
+
+// matchHolidays returns whether any of the holidays are in the rule and whether one was named explicitly
+func matchHolidays(rule map[string]bool, holidays []string) (bool, bool) {
+	matched, explicit := false, false
+	for _, holiday := range holidays {
+		if named, ok := rule[holiday]; ok {
+			matched = true
+			explicit = explicit || named
+		}
+	}
+
+	return matched, explicit
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,193 @@
+package transxchange_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/transxchange"
+)
+
+func TestRunsOn(t *testing.T) {
+	weekdays := [7]bool{false, true, true, true, true, true, false}
+
+	tests := []struct {
+		name     string
+		profile  transxchange.OperatingProfile
+		date     string
+		expected bool
+	}{
+		{
+			name:     "Runs on weekday",
+			profile:  transxchange.OperatingProfile{DaysOfWeek: weekdays},
+			date:     "2020-03-30",
+			expected: true,
+		},
+		{
+			name:     "Does not run at the weekend",
+			profile:  transxchange.OperatingProfile{DaysOfWeek: weekdays},
+			date:     "2020-03-28",
+			expected: false,
+		},
+		{
+			name: "Special day of non-operation",
+			profile: transxchange.OperatingProfile{
+				DaysOfWeek:         weekdays,
+				DaysOfNonOperation: []transxchange.DateRange{{StartDate: "2020-03-30", EndDate: "2020-04-03"}},
+			},
+			date:     "2020-04-01",
+			expected: false,
+		},
+		{
+			name: "Special day of operation",
+			profile: transxchange.OperatingProfile{
+				DaysOfWeek:      weekdays,
+				DaysOfOperation: []transxchange.DateRange{{StartDate: "2020-03-28", EndDate: "2020-03-28"}},
+			},
+			date:     "2020-03-28",
+			expected: true,
+		},
+		{
+			name: "Special day of operation overrides bank holiday",
+			profile: transxchange.OperatingProfile{
+				DaysOfWeek:                 weekdays,
+				DaysOfOperation:            []transxchange.DateRange{{StartDate: "2020-04-10", EndDate: "2020-04-10"}},
+				BankHolidaysOfNonOperation: map[string]bool{transxchange.GoodFriday: true},
+			},
+			date:     "2020-04-10",
+			expected: true,
+		},
+		{
+			name: "Bank holiday of non-operation",
+			profile: transxchange.OperatingProfile{
+				DaysOfWeek:                 weekdays,
+				BankHolidaysOfNonOperation: map[string]bool{transxchange.EasterMonday: true},
+			},
+			date:     "2020-04-13",
+			expected: false,
+		},
+		{
+			name: "Bank holiday of operation at the weekend",
+			profile: transxchange.OperatingProfile{
+				DaysOfWeek:              weekdays,
+				BankHolidaysOfOperation: map[string]bool{transxchange.BoxingDay: true},
+			},
+			date:     "2020-12-26",
+			expected: true,
+		},
+		{
+			name: "Named bank holiday takes precedence over group",
+			profile: transxchange.OperatingProfile{
+				BankHolidaysOfOperation:    map[string]bool{transxchange.GoodFriday: true},
+				BankHolidaysOfNonOperation: map[string]bool{transxchange.GoodFriday: false, transxchange.EasterMonday: false},
+			},
+			date:     "2020-04-10",
+			expected: true,
+		},
+		{
+			name: "Non-operation wins when both named",
+			profile: transxchange.OperatingProfile{
+				DaysOfWeek:                 weekdays,
+				BankHolidaysOfOperation:    map[string]bool{transxchange.GoodFriday: true},
+				BankHolidaysOfNonOperation: map[string]bool{transxchange.GoodFriday: true},
+			},
+			date:     "2020-04-10",
+			expected: false,
+		},
+		{
+			name: "Moved May Day",
+			profile: transxchange.OperatingProfile{
+				DaysOfWeek:                 weekdays,
+				BankHolidaysOfNonOperation: map[string]bool{transxchange.MayDay: true},
+			},
+			date:     "2020-05-08",
+			expected: false,
+		},
+		{
+			name: "Usual May Day runs when moved",
+			profile: transxchange.OperatingProfile{
+				DaysOfWeek:                 weekdays,
+				BankHolidaysOfNonOperation: map[string]bool{transxchange.MayDay: true},
+			},
+			date:     "2020-05-04",
+			expected: true,
+		},
+		{
+			name: "Substitute Boxing Day",
+			profile: transxchange.OperatingProfile{
+				DaysOfWeek:                 weekdays,
+				BankHolidaysOfNonOperation: map[string]bool{transxchange.BoxingDayHoliday: true},
+			},
+			date:     "2020-12-28",
+			expected: false,
+		},
+		{
+			name: "Substitute Christmas Day and Boxing Day",
+			profile: transxchange.OperatingProfile{
+				DaysOfWeek:                 weekdays,
+				BankHolidaysOfNonOperation: map[string]bool{transxchange.BoxingDayHoliday: true},
+			},
+			date:     "2021-12-28",
+			expected: false,
+		},
+		{
+			name: "Substitute New Year's Day",
+			profile: transxchange.OperatingProfile{
+				DaysOfWeek:                 weekdays,
+				BankHolidaysOfNonOperation: map[string]bool{transxchange.NewYearsDayHoliday: true},
+			},
+			date:     "2022-01-03",
+			expected: false,
+		},
+		{
+			name: "Spring bank holiday",
+			profile: transxchange.OperatingProfile{
+				DaysOfWeek:                 weekdays,
+				BankHolidaysOfNonOperation: map[string]bool{transxchange.SpringBank: true},
+			},
+			date:     "2021-05-31",
+			expected: false,
+		},
+		{
+			name: "Other public holiday",
+			profile: transxchange.OperatingProfile{
+				DaysOfWeek:                        weekdays,
+				OtherPublicHolidaysOfNonOperation: []string{"2022-06-03"},
+			},
+			date:     "2022-06-03",
+			expected: false,
+		},
+		{
+			name: "Holidays only on a normal day",
+			profile: transxchange.OperatingProfile{
+				HolidaysOnly:            true,
+				BankHolidaysOfOperation: map[string]bool{transxchange.EasterMonday: true},
+			},
+			date:     "2020-04-14",
+			expected: false,
+		},
+		{
+			name: "Holidays only on a holiday",
+			profile: transxchange.OperatingProfile{
+				HolidaysOnly:            true,
+				BankHolidaysOfOperation: map[string]bool{transxchange.EasterMonday: true},
+			},
+			date:     "2020-04-13",
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			date, err := time.Parse("2006-01-02", test.date)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			}
+
+			result := test.profile.RunsOn(date)
+
+			if result != test.expected {
+				t.Fatalf("Expected %t; got %t", test.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package transxchange
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// lookaheadDays is the number of days after the requested time that departures are searched for
+const lookaheadDays = 7
+
+// Departure represents a scheduled departure from a stop
+type Departure struct {
+	StopPoint      StopPoint
+	Service        *Service
+	JourneyPattern *JourneyPattern
+	Journey        *VehicleJourney
+	LineName       string
+	Time           time.Time
+}
+
+// call represents a journey departing from a stop, offset is the time after the journey's departure time
+type call struct {
+	service *Service
+	pattern *JourneyPattern
+	journey *VehicleJourney
+	offset  time.Duration
+}
+
+// index builds the index of the journeys departing from each stop once all the documents are added
+func (t *Timetable) index() {
+	t.byStop = make(map[string][]call)
+
+	for _, journey := range t.Journeys {
+		service, ok := t.Services[journey.ServiceRef]
+		if !ok {
+			continue
+		}
+		pattern := journey.JourneyPattern
+		if pattern == nil {
+			continue
+		}
+
+		var offset time.Duration
+		for i, link := range pattern.TimingLinks {
+			// The wait time at a stop can be given on the link to it and the link from it,
+			// the wait at the first stop is included in the journey's departure time
+			if i > 0 {
+				offset += pattern.TimingLinks[i-1].To.WaitTime + link.From.WaitTime
+			}
+
+			if picksUp(link.From.Activity) {
+				stop := strings.ToUpper(link.From.StopPointRef)
+				t.byStop[stop] = append(t.byStop[stop], call{
+					service: service,
+					pattern: pattern,
+					journey: journey,
+					offset:  offset,
+				})
+			}
+
+			offset += link.RunTime
+		}
+	}
+}
+
+// picksUp returns whether passengers can board at a stop with the activity
+func picksUp(activity string) bool {
+	return activity == "" || activity == "pickUp" || activity == "pickUpAndSetDown"
+}
+
+// Departures returns up to limit scheduled departures from the stop at or after the time, earliest first.
+// The stop is identified by its ATCO code, and departures are searched for up to a week ahead.
+func (t *Timetable) Departures(stopPointRef string, from time.Time, limit int) []Departure {
+	stop := strings.ToUpper(strings.TrimSpace(stopPointRef))
+	calls, ok := t.byStop[stop]
+	if !ok || limit <= 0 {
+		return nil
+	}
+
+	from = from.In(t.Location)
+	date := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, t.Location)
+
+	var departures []Departure
+	// Start the day before as journeys can run past midnight
+	for day := -1; day <= lookaheadDays; day++ {
+		operatingDate := date.AddDate(0, 0, day)
+		for _, c := range calls {
+			departure, ok := t.departure(stop, c, operatingDate)
+			if !ok || departure.Time.Before(from) {
+				continue
+			}
+			departures = append(departures, departure)
+		}
+
+		if day < 0 {
+			continue
+		}
+
+		sort.SliceStable(departures, func(i, j int) bool {
+			return departures[i].Time.Before(departures[j].Time)
+		})
+
+		// Journeys on the next day can't depart earlier than those found so far
+		if len(departures) >= limit && departures[limit-1].Time.Before(operatingDate.AddDate(0, 0, 1)) {
+			break
+		}
+	}
+
+	if len(departures) > limit {
+		departures = departures[:limit]
+	}
+
+	return departures
+}
+
+// departure returns the departure of the call if its journey runs on the date
+func (t *Timetable) departure(stop string, c call, date time.Time) (Departure, bool) {
+	day := date.Format(dateFormat)
+	if day < c.service.StartDate || (c.service.EndDate != "" && day > c.service.EndDate) {
+		return Departure{}, false
+	}
+
+	profile := c.journey.OperatingProfile
+	if profile == nil {
+		profile = c.service.OperatingProfile
+	}
+	if profile != nil && !profile.RunsOn(date) {
+		return Departure{}, false
+	}
+
+	// Times are wall clock times, so the seconds are normalised by time.Date rather than added as a duration
+	seconds := int((c.journey.DepartureTime + c.offset) / time.Second)
+	departureTime := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, seconds, 0, t.Location)
+
+	stopPoint, ok := t.StopPoints[stop]
+	if !ok {
+		stopPoint = StopPoint{StopPointRef: stop}
+	}
+
+	return Departure{
+		StopPoint:      stopPoint,
+		Service:        c.service,
+		JourneyPattern: c.pattern,
+		Journey:        c.journey,
+		LineName:       c.journey.LineName,
+		Time:           departureTime,
+	}, true
+}
+
+// HasStop returns whether the stop with the ATCO code is in the timetable
+func (t *Timetable) HasStop(stopPointRef string) bool {
+	stop := strings.ToUpper(strings.TrimSpace(stopPointRef))
+	if _, ok := t.StopPoints[stop]; ok {
+		return true
+	}
+	_, ok := t.byStop[stop]
+
+	return ok
+}
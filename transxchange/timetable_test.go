@@ -0,0 +1,136 @@
+package transxchange_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/transxchange"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDepartures(t *testing.T) {
+	timetable, err := transxchange.Load("testdata/service.xml")
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	parse := func(value string) time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return parsed
+	}
+
+	tests := []struct {
+		name          string
+		stopPointRef  string
+		from          time.Time
+		limit         int
+		expectedTimes []time.Time
+		expectedCodes []string
+	}{
+		{
+			name:          "Weekday departures from first stop",
+			stopPointRef:  "0100BRP90310",
+			from:          parse("2020-03-30T07:00:00+01:00"),
+			limit:         2,
+			expectedTimes: []time.Time{parse("2020-03-30T07:30:00+01:00"), parse("2020-03-30T23:50:00+01:00")},
+			expectedCodes: []string{"VJ1", "VJ2"},
+		},
+		{
+			name:          "Wait times are added at intermediate stop",
+			stopPointRef:  "0100brp90311",
+			from:          parse("2020-03-30T07:00:00+01:00"),
+			limit:         1,
+			expectedTimes: []time.Time{parse("2020-03-30T07:43:00+01:00")},
+			expectedCodes: []string{"VJ1"},
+		},
+		{
+			name:          "Journey running past midnight",
+			stopPointRef:  "0100BRP90311",
+			from:          parse("2020-03-31T00:00:00+01:00"),
+			limit:         1,
+			expectedTimes: []time.Time{parse("2020-03-31T00:03:00+01:00")},
+			expectedCodes: []string{"VJ2"},
+		},
+		{
+			name:          "Saturday journey",
+			stopPointRef:  "0100BRP90310",
+			from:          parse("2020-03-28T00:30:00Z"),
+			limit:         1,
+			expectedTimes: []time.Time{parse("2020-03-28T09:00:00Z")},
+			expectedCodes: []string{"VJ3"},
+		},
+		{
+			name:          "Named bank holiday of operation",
+			stopPointRef:  "0100BRP90310",
+			from:          parse("2020-04-10T00:30:00+01:00"),
+			limit:         1,
+			expectedTimes: []time.Time{parse("2020-04-10T09:00:00+01:00")},
+			expectedCodes: []string{"VJ3"},
+		},
+		{
+			name:          "Special day of non-operation",
+			stopPointRef:  "0100BRP90310",
+			from:          parse("2020-06-06T00:30:00+01:00"),
+			limit:         1,
+			expectedTimes: []time.Time{parse("2020-06-08T07:30:00+01:00")},
+			expectedCodes: []string{"VJ1"},
+		},
+		{
+			name:          "Holidays only journey on substitute bank holiday",
+			stopPointRef:  "0100BRP90310",
+			from:          parse("2020-12-27T00:30:00Z"),
+			limit:         1,
+			expectedTimes: []time.Time{parse("2020-12-28T12:00:00Z")},
+			expectedCodes: []string{"VJ4"},
+		},
+		{
+			name:         "After the operating period",
+			stopPointRef: "0100BRP90310",
+			from:         parse("2021-01-01T00:30:00Z"),
+			limit:        1,
+		},
+		{
+			name:         "Set down only",
+			stopPointRef: "0100BRP90312",
+			from:         parse("2020-03-30T07:00:00+01:00"),
+			limit:        1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			departures := timetable.Departures(test.stopPointRef, test.from, test.limit)
+
+			var times []time.Time
+			var codes []string
+			for _, departure := range departures {
+				times = append(times, departure.Time)
+				codes = append(codes, departure.Journey.Code)
+				if departure.LineName != "72" {
+					t.Fatalf("Expected line name '72'; got '%s'", departure.LineName)
+				}
+			}
+
+			if diff := cmp.Diff(test.expectedTimes, times, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+				t.Fatalf("Unexpected departure times (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.expectedCodes, codes); diff != "" {
+				t.Fatalf("Unexpected journeys (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHasStop(t *testing.T) {
+	timetable, err := transxchange.Load("testdata/service.xml")
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	if !timetable.HasStop("0100brp90312") {
+		t.Fatalf("Expected stop 0100brp90312 to be in the timetable")
+	}
+	if timetable.HasStop("0100BRP90313") {
+		t.Fatalf("Expected stop 0100BRP90313 not to be in the timetable")
+	}
+}
@@ -0,0 +1,98 @@
+package transxchange
+
+import "time"
+
+// Timetable stores the services and vehicle journeys parsed from TransXChange documents,
+// indexed by the stops the journeys depart from
+type Timetable struct {
+	StopPoints map[string]StopPoint
+	Operators  map[string]Operator
+	Services   map[string]*Service
+	Journeys   []*VehicleJourney
+
+	// Location is the timezone that departure times are in
+	Location *time.Location
+
+	byStop map[string][]call
+}
+
+// StopPoint represents an AnnotatedStopPointRef, identified by its NaPTAN ATCO code
+type StopPoint struct {
+	StopPointRef string
+	CommonName   string
+}
+
+// Operator represents an operator of the services
+type Operator struct {
+	ID        string
+	Code      string
+	ShortName string
+}
+
+// Service represents a registered bus service and its journey patterns. The IDs of lines and journey
+// patterns are only unique within a document, so when the service is split across files an ID is kept
+// for the first file that uses it, and each journey refers to the line and pattern of its own file.
+type Service struct {
+	Code        string
+	Lines       map[string]string
+	Mode        string
+	OperatorRef string
+	Origin      string
+	Destination string
+	// StartDate and EndDate are the period the service is registered to run, EndDate is empty if open ended
+	StartDate string
+	EndDate   string
+	// OperatingProfile is used by journeys that don't have their own
+	OperatingProfile *OperatingProfile
+	JourneyPatterns  map[string]*JourneyPattern
+}
+
+// JourneyPattern represents the sequence of stops and run times of journeys on a service
+type JourneyPattern struct {
+	ID                 string
+	Direction          string
+	DestinationDisplay string
+	TimingLinks        []TimingLink
+}
+
+// TimingLink represents the run time between two stops of a journey pattern
+type TimingLink struct {
+	From    StopUsage
+	To      StopUsage
+	RunTime time.Duration
+}
+
+// StopUsage represents a stop of a journey pattern
+type StopUsage struct {
+	StopPointRef string
+	// Activity is pickUp, setDown, pickUpAndSetDown or pass, pickUpAndSetDown if not set
+	Activity string
+	WaitTime time.Duration
+}
+
+// VehicleJourney represents a scheduled journey following a journey pattern
+type VehicleJourney struct {
+	Code              string
+	ServiceRef        string
+	LineRef           string
+	JourneyPatternRef string
+	// LineName and JourneyPattern are resolved from the document the journey is in,
+	// JourneyPattern is nil if the document doesn't have the pattern
+	LineName       string
+	JourneyPattern *JourneyPattern
+	// DepartureTime is the time of day the journey departs from its first stop
+	DepartureTime time.Duration
+	// OperatingProfile is nil if the journey uses the profile of its service
+	OperatingProfile *OperatingProfile
+}
+
+// DateRange represents the inclusive range of dates in the format 2006-01-02
+type DateRange struct {
+	StartDate string
+	EndDate   string
+}
+
+// Contains returns whether the date is in the range
+func (r DateRange) Contains(date string) bool {
+	return date >= r.StartDate && (r.EndDate == "" || date <= r.EndDate)
+}
@@ -1,8 +1,6 @@
 package traveline
 
 import (
-	"encoding/xml"
-	"io"
 	"log"
 	"net/http"
 	"strings"
@@ -15,7 +13,13 @@ import (
 type Client struct {
 	Username string
 	Password string
-	Client   *http.Client
+	// URL of the API, the Traveline NextBuses API is used if not set
+	URL string
+	// Version is the Siri version of the requests, SiriVersion1 is used if not set
+	Version string
+	// Limits bounds the responses that are read and parsed, the defaults are used if not set
+	Limits Limits
+	Client *http.Client
 }
 
 // NewClient returns the client to access the Traveline API
@@ -23,45 +27,76 @@ func NewClient(username string, password string, httpClient *http.Client) API {
 	return &Client{
 		Username: username,
 		Password: password,
+		URL:      url,
+		Version:  siriVersion,
 		Client:   httpClient,
 	}
 }
 
-// BuildServiceRequest will return the XML for the request for the stop that the NaPTAN code represents
-func (c *Client) BuildServiceRequest(requestRef string, naptanCode string, when time.Time) (string, error) {
-	serviceRequest := &ServiceRequest{
-		Version:                                siriVersion,
-		XMLNS:                                  siriXMLNS,
-		ServiceRequestRequestTimestamp:         when.Format(time.RFC3339),
-		ServiceRequestRequestorRef:             c.Username,
-		StopMonitoringRequestRequestTimestamp:  when.Format(time.RFC3339),
-		StopMonitoringRequestMessageIdentifier: requestRef,
-		StopMonitoringRequestMonitoringRef:     naptanCode,
+// siri returns the Siri version and XMLNS of the requests
+func (c *Client) siri() (string, string, error) {
+	version := c.Version
+	if version == "" {
+		version = siriVersion
 	}
 
-	log.Printf("StopMonitoringRequestRequestTimestamp: %s", serviceRequest.StopMonitoringRequestRequestTimestamp)
-	log.Printf("StopMonitoringRequestMessageIdentifier: %s", serviceRequest.StopMonitoringRequestMessageIdentifier)
-	log.Printf("StopMonitoringRequestMonitoringRef: %s", serviceRequest.StopMonitoringRequestMonitoringRef)
+	xmlns, ok := siriNamespaces[version]
+	if !ok {
+		return "", "", errors.Errorf("unsupported Siri version: %s", version)
+	}
+
+	return version, xmlns, nil
+}
+
+// BuildServiceRequest will return the XML for the request for the stop that the NaPTAN code represents,
+// the options can be used to change the window of departures returned
+func (c *Client) BuildServiceRequest(
+	requestRef string,
+	naptanCode string,
+	when time.Time,
+	options RequestOptions,
+) (string, error) {
+	if err := options.validate(); err != nil {
+		return "", err
+	}
 
-	requestBody, err := xml.Marshal(serviceRequest)
+	request, err := c.NewServiceRequest(when)
 	if err != nil {
 		return "", err
 	}
 
-	return string(requestBody), nil
+	stopMonitoringRequest := StopMonitoringRequest{
+		FunctionalRequest:         NewFunctionalRequest(requestRef, when),
+		PreviewInterval:           formatDuration(options.PreviewInterval),
+		StartTime:                 formatTime(options.StartTime),
+		MonitoringRef:             naptanCode,
+		MaximumStopVisits:         options.MaximumStopVisits,
+		MinimumStopVisitsPerLine:  options.MinimumStopVisitsPerLine,
+		StopMonitoringDetailLevel: string(options.DetailLevel),
+	}
+	serviceRequest := request.ServiceRequest
+	serviceRequest.StopMonitoringRequest = append(serviceRequest.StopMonitoringRequest, stopMonitoringRequest)
+
+	log.Printf("StopMonitoringRequestRequestTimestamp: %s", stopMonitoringRequest.RequestTimestamp)
+	log.Printf("StopMonitoringRequestMessageIdentifier: %s", stopMonitoringRequest.MessageIdentifier)
+	log.Printf("StopMonitoringRequestMonitoringRef: %s", stopMonitoringRequest.MonitoringRef)
+
+	return request.Marshal()
 }
 
 // ParseServiceDelivery the response from the Traveline API and return the time of the next departure
 func (c *Client) ParseServiceDelivery(response string) (*MonitoredVehicleJourney, error) {
 	serviceDelivery := ServiceDelivery{}
-	err := xml.Unmarshal([]byte(response), &serviceDelivery)
+	err := unmarshal([]byte(response), &serviceDelivery, c.Limits)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("RequestMessageRef: %s", serviceDelivery.ServiceDelivery.StopMonitoringDelivery.RequestMessageRef)
-
-	monitorStopVisits := serviceDelivery.ServiceDelivery.StopMonitoringDelivery.MonitoredStopVisit
+	var monitorStopVisits []MonitoredStopVisit
+	for _, delivery := range serviceDelivery.ServiceDelivery.StopMonitoringDelivery {
+		log.Printf("RequestMessageRef: %s", delivery.RequestMessageRef)
+		monitorStopVisits = append(monitorStopVisits, delivery.MonitoredStopVisit...)
+	}
 	if len(monitorStopVisits) == 0 {
 		return nil, &NoTimesFoundError{}
 	}
@@ -82,9 +117,35 @@ func (c *Client) ParseServiceDelivery(response string) (*MonitoredVehicleJourney
 	return &monitorStopVisits[0].MonitoredVehicleJourney, nil
 }
 
+// ParseStopMonitoringDelivery will parse the response from the Traveline API and return every visit
+// to the stop in the deliveries for the request, rather than just the next departure
+func (c *Client) ParseStopMonitoringDelivery(requestRef string, response string) ([]MonitoredStopVisit, error) {
+	serviceDelivery := ServiceDelivery{}
+	err := unmarshal([]byte(response), &serviceDelivery, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	var visits []MonitoredStopVisit
+	for _, delivery := range serviceDelivery.ServiceDelivery.StopMonitoringDelivery {
+		if !delivery.answers(requestRef) {
+			continue
+		}
+		log.Printf("RequestMessageRef: %s, Visits: %d", delivery.RequestMessageRef, len(delivery.MonitoredStopVisit))
+		visits = append(visits, delivery.MonitoredStopVisit...)
+	}
+
+	return visits, nil
+}
+
 // Send will send the request to Traveline API
 func (c *Client) Send(request string) (string, error) {
-	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(request))
+	apiURL := c.URL
+	if apiURL == "" {
+		apiURL = url
+	}
+
+	req, err := http.NewRequest(http.MethodPost, apiURL, strings.NewReader(request))
 	if err != nil {
 		return "", err
 	}
@@ -102,13 +163,16 @@ func (c *Client) Send(request string) (string, error) {
 		}
 	}()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.Limits.read(resp.Body)
 	if err != nil {
 		return "", err
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		log.Printf("Error response from API: %v", resp)
+		if fault := faultError(body, c.Limits); fault != nil {
+			return string(body), fault
+		}
 		return string(body), errors.Errorf("error status from API: %d", resp.StatusCode)
 	}
 
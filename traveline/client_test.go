@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"os"
 	"testing"
 	"time"
 
@@ -22,7 +23,12 @@ func TestBuildServiceRequest(t *testing.T) {
 		&http.Client{},
 	)
 
-	request, err := client.BuildServiceRequest("ab7c1e9b-d06f-44cc-b190-4d36fb564386", naptanCode, when)
+	request, err := client.BuildServiceRequest(
+		"ab7c1e9b-d06f-44cc-b190-4d36fb564386",
+		naptanCode,
+		when,
+		traveline.RequestOptions{},
+	)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %s", err.Error())
@@ -39,6 +45,192 @@ func TestBuildServiceRequest(t *testing.T) {
 	}
 }
 
+func TestBuildServiceRequestVersion(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+
+	tests := []struct {
+		name            string
+		version         string
+		expectedRequest string
+		expectedError   error
+	}{
+		{
+			name:    "Default version",
+			version: "",
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<StopMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<MonitoringRef>123456789</MonitoringRef></StopMonitoringRequest></ServiceRequest></Siri>`,
+		},
+		{
+			name:    "Siri 1.0",
+			version: traveline.SiriVersion1,
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<StopMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<MonitoringRef>123456789</MonitoringRef></StopMonitoringRequest></ServiceRequest></Siri>`,
+		},
+		{
+			name:    "Siri 2.0",
+			version: traveline.SiriVersion2,
+			expectedRequest: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<StopMonitoringRequest version="2.0"><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<MonitoringRef>123456789</MonitoringRef></StopMonitoringRequest></ServiceRequest></Siri>`,
+		},
+		{
+			name:          "Unsupported version",
+			version:       "1.3",
+			expectedError: errors.New("unsupported Siri version: 1.3"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein", Version: test.version}
+
+			request, err := client.BuildServiceRequest(
+				"ab7c1e9b-d06f-44cc-b190-4d36fb564386",
+				"123456789",
+				when,
+				traveline.RequestOptions{},
+			)
+
+			if test.expectedError != nil {
+				if err == nil || err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if request != test.expectedRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedRequest, request))
+			}
+		})
+	}
+}
+
+func TestBuildServiceRequestWithOptions(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	startTime, _ := time.Parse(time.RFC3339, "2020-03-30T13:00:00+01:00")
+
+	tests := []struct {
+		name            string
+		options         traveline.RequestOptions
+		expectedRequest string
+		expectedError   error
+	}{
+		{
+			name: "Preview interval and start time",
+			options: traveline.RequestOptions{
+				PreviewInterval: 90 * time.Minute,
+				StartTime:       startTime,
+			},
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<StopMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<PreviewInterval>PT1H30M</PreviewInterval><StartTime>2020-03-30T13:00:00+01:00</StartTime>` +
+				`<MonitoringRef>123456789</MonitoringRef></StopMonitoringRequest></ServiceRequest></Siri>`,
+		},
+		{
+			name: "Maximum and minimum stop visits",
+			options: traveline.RequestOptions{
+				MaximumStopVisits:        10,
+				MinimumStopVisitsPerLine: 1,
+			},
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<StopMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<MonitoringRef>123456789</MonitoringRef><MaximumStopVisits>10</MaximumStopVisits>` +
+				`<MinimumStopVisitsPerLine>1</MinimumStopVisitsPerLine></StopMonitoringRequest></ServiceRequest></Siri>`,
+		},
+		{
+			name: "Preview interval in seconds",
+			options: traveline.RequestOptions{
+				PreviewInterval: 45 * time.Second,
+			},
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<StopMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<PreviewInterval>PT45S</PreviewInterval>` +
+				`<MonitoringRef>123456789</MonitoringRef></StopMonitoringRequest></ServiceRequest></Siri>`,
+		},
+		{
+			name: "Calls detail level",
+			options: traveline.RequestOptions{
+				DetailLevel: traveline.CallsDetail,
+			},
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<StopMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<MonitoringRef>123456789</MonitoringRef><StopMonitoringDetailLevel>calls</StopMonitoringDetailLevel>` +
+				`</StopMonitoringRequest></ServiceRequest></Siri>`,
+		},
+		{
+			name:          "Negative preview interval",
+			options:       traveline.RequestOptions{PreviewInterval: -time.Minute},
+			expectedError: errors.New("invalid preview interval: -1m0s"),
+		},
+		{
+			name:          "Negative maximum stop visits",
+			options:       traveline.RequestOptions{MaximumStopVisits: -1},
+			expectedError: errors.New("invalid maximum stop visits: -1"),
+		},
+		{
+			name:          "Negative minimum stop visits per line",
+			options:       traveline.RequestOptions{MinimumStopVisitsPerLine: -1},
+			expectedError: errors.New("invalid minimum stop visits per line: -1"),
+		},
+		{
+			name:          "Unknown detail level",
+			options:       traveline.RequestOptions{DetailLevel: "everything"},
+			expectedError: errors.New("invalid detail level: everything"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := traveline.NewClient(
+				"TravelineAPI999",
+				"letmein",
+				&http.Client{},
+			)
+
+			request, err := client.BuildServiceRequest(
+				"ab7c1e9b-d06f-44cc-b190-4d36fb564386",
+				"123456789",
+				when,
+				test.options,
+			)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if request != test.expectedRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedRequest, request))
+			}
+		})
+	}
+}
+
 func TestParseServiceDelivery(t *testing.T) {
 	tests := []struct {
 		name                  string
@@ -80,10 +272,7 @@ func TestParseServiceDelivery(t *testing.T) {
 				PublishedLineName: "42",
 				DirectionName:     "Toddington, The Green",
 				OperatorRef:       "153",
-				MonitoredCall: struct {
-					AimedDepartureTime    string "xml:\"AimedDepartureTime\""
-					ExpectedDepartureTime string "xml:\"ExpectedDepartureTime\""
-				}{
+				MonitoredCall: traveline.MonitoredCall{
 					AimedDepartureTime:    "2014-07-01T15:09:00.000+01:00",
 					ExpectedDepartureTime: "2014-07-01T15:12:00.000+01:00",
 				},
@@ -122,10 +311,7 @@ func TestParseServiceDelivery(t *testing.T) {
 				PublishedLineName: "42",
 				DirectionName:     "Toddington, The Green",
 				OperatorRef:       "153",
-				MonitoredCall: struct {
-					AimedDepartureTime    string "xml:\"AimedDepartureTime\""
-					ExpectedDepartureTime string "xml:\"ExpectedDepartureTime\""
-				}{
+				MonitoredCall: traveline.MonitoredCall{
 					AimedDepartureTime: "2014-07-01T15:09:00.000+01:00",
 				},
 			},
@@ -196,6 +382,313 @@ func NewTestClient(fn RoundTripFunc) *http.Client {
 	}
 }
 
+func TestParseServiceDeliveryWithCalls(t *testing.T) {
+	response, err := os.ReadFile("testdata/stop_monitoring_calls.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+	journey, err := client.ParseServiceDelivery(string(response))
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	expectedPreviousCalls := []traveline.PreviousCall{
+		{
+			StopPointRef:        "020035800",
+			VisitNumber:         1,
+			StopPointName:       "Toddington, The Green",
+			AimedDepartureTime:  "2020-03-30T12:30:00+01:00",
+			ActualDepartureTime: "2020-03-30T12:32:00+01:00",
+		},
+	}
+	if diff := cmp.Diff(expectedPreviousCalls, journey.PreviousCalls); diff != "" {
+		t.Errorf("PreviousCalls (-want +got):\n%s", diff)
+	}
+
+	expectedOnwardCalls := []traveline.OnwardCall{
+		{
+			StopPointRef:          "020035822",
+			VisitNumber:           3,
+			StopPointName:         "High Street",
+			AimedArrivalTime:      "2020-03-30T12:44:00+01:00",
+			ExpectedArrivalTime:   "2020-03-30T12:45:00+01:00",
+			AimedDepartureTime:    "2020-03-30T12:44:00+01:00",
+			ExpectedDepartureTime: "2020-03-30T12:45:30+01:00",
+		},
+		{
+			StopPointRef:        "020035899",
+			VisitNumber:         4,
+			StopPointName:       "City Centre",
+			AimedArrivalTime:    "2020-03-30T12:45:00+01:00",
+			ExpectedArrivalTime: "2020-03-30T12:47:00+01:00",
+		},
+	}
+	if diff := cmp.Diff(expectedOnwardCalls, journey.OnwardCalls); diff != "" {
+		t.Errorf("OnwardCalls (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseServiceDeliveryVersion2(t *testing.T) {
+	response, err := os.ReadFile("testdata/stop_monitoring_v2.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein", Version: traveline.SiriVersion2}
+
+	journey, err := client.ParseServiceDelivery(string(response))
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	expectedCall := traveline.MonitoredCall{
+		AimedDepartureTime:    "2020-03-30T12:40:00+01:00",
+		ExpectedDepartureTime: "2020-03-30T12:43:00+01:00",
+	}
+	if journey.PublishedLineName != "42" || journey.DirectionName != "Toddington, The Green" {
+		t.Errorf("Journey not as expected: %s %s", journey.PublishedLineName, journey.DirectionName)
+	}
+	if diff := cmp.Diff(expectedCall, journey.MonitoredCall); diff != "" {
+		t.Errorf("MonitoredCall (-want +got):\n%s", diff)
+	}
+}
+
+func TestStopMonitoringVersions(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+
+	tests := []struct {
+		name              string
+		version           string
+		expectedRequest   string
+		response          string
+		expectedOccupancy string
+	}{
+		{
+			name:    "Siri 1.0",
+			version: traveline.SiriVersion1,
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<StopMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<MonitoringRef>020035811</MonitoringRef></StopMonitoringRequest></ServiceRequest></Siri>`,
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><StopMonitoringDelivery>` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><PublishedLineName>42</PublishedLineName>` +
+				`<Occupancy>seatsAvailable</Occupancy><MonitoredCall>` +
+				`<AimedDepartureTime>2020-03-30T12:40:00+01:00</AimedDepartureTime></MonitoredCall>` +
+				`<PreviousCalls><PreviousCall><StopPointRef>020035800</StopPointRef><VisitNumber>1</VisitNumber></PreviousCall></PreviousCalls>` +
+				`<OnwardCalls><OnwardCall><StopPointRef>020035822</StopPointRef><VisitNumber>3</VisitNumber></OnwardCall></OnwardCalls>` +
+				`</MonitoredVehicleJourney></MonitoredStopVisit></StopMonitoringDelivery></ServiceDelivery></Siri>`,
+			expectedOccupancy: "seatsAvailable",
+		},
+		{
+			name:    "Siri 2.0",
+			version: traveline.SiriVersion2,
+			expectedRequest: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<StopMonitoringRequest version="2.0"><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<MonitoringRef>020035811</MonitoringRef></StopMonitoringRequest></ServiceRequest></Siri>`,
+			response: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><ServiceDelivery><StopMonitoringDelivery version="2.0">` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><PublishedLineName>42</PublishedLineName>` +
+				`<Occupancy>fewSeatsAvailable</Occupancy><MonitoredCall>` +
+				`<AimedDepartureTime>2020-03-30T12:40:00+01:00</AimedDepartureTime></MonitoredCall>` +
+				`<PreviousCalls><PreviousCall><StopPointRef>020035800</StopPointRef><Order>1</Order></PreviousCall></PreviousCalls>` +
+				`<OnwardCalls><OnwardCall><StopPointRef>020035822</StopPointRef><Order>3</Order></OnwardCall></OnwardCalls>` +
+				`</MonitoredVehicleJourney></MonitoredStopVisit></StopMonitoringDelivery></ServiceDelivery></Siri>`,
+			expectedOccupancy: "seatsAvailable",
+		},
+		{
+			name:    "Siri 2.0 occupancy not known",
+			version: traveline.SiriVersion2,
+			expectedRequest: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<StopMonitoringRequest version="2.0"><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<MonitoringRef>020035811</MonitoringRef></StopMonitoringRequest></ServiceRequest></Siri>`,
+			response: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><ServiceDelivery><StopMonitoringDelivery version="2.0">` +
+				`<MonitoredStopVisit><MonitoredVehicleJourney><PublishedLineName>42</PublishedLineName>` +
+				`<Occupancy>unknown</Occupancy><MonitoredCall>` +
+				`<AimedDepartureTime>2020-03-30T12:40:00+01:00</AimedDepartureTime></MonitoredCall>` +
+				`<PreviousCalls><PreviousCall><StopPointRef>020035800</StopPointRef><Order>1</Order></PreviousCall></PreviousCalls>` +
+				`<OnwardCalls><OnwardCall><StopPointRef>020035822</StopPointRef><Order>3</Order></OnwardCall></OnwardCalls>` +
+				`</MonitoredVehicleJourney></MonitoredStopVisit></StopMonitoringDelivery></ServiceDelivery></Siri>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein", Version: test.version}
+
+			request, err := client.BuildServiceRequest(
+				"ab7c1e9b-d06f-44cc-b190-4d36fb564386",
+				"020035811",
+				when,
+				traveline.RequestOptions{},
+			)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if request != test.expectedRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedRequest, request))
+			}
+
+			journey, err := client.ParseServiceDelivery(test.response)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			expectedJourney := traveline.MonitoredVehicleJourney{
+				PublishedLineName: "42",
+				Occupancy:         test.expectedOccupancy,
+				MonitoredCall:     traveline.MonitoredCall{AimedDepartureTime: "2020-03-30T12:40:00+01:00"},
+				PreviousCalls:     []traveline.PreviousCall{{StopPointRef: "020035800", VisitNumber: 1}},
+				OnwardCalls:       []traveline.OnwardCall{{StopPointRef: "020035822", VisitNumber: 3}},
+			}
+			if diff := cmp.Diff(expectedJourney, *journey); diff != "" {
+				t.Errorf("ParseServiceDelivery() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseStopMonitoringDelivery(t *testing.T) {
+	response, err := os.ReadFile("testdata/stop_monitoring_arrivals.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+	visits, err := client.ParseStopMonitoringDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", string(response))
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	if len(visits) != 3 {
+		t.Fatalf("Expected 3 visits; got %d", len(visits))
+	}
+	journey := visits[0].MonitoredVehicleJourney
+	if journey.DestinationRef != "020035899" || journey.DestinationName != "City Centre" {
+		t.Errorf("Destination not as expected: %s %s", journey.DestinationRef, journey.DestinationName)
+	}
+	expectedCall := traveline.MonitoredCall{
+		AimedArrivalTime:    "2020-03-30T12:45:00+01:00",
+		ExpectedArrivalTime: "2020-03-30T12:47:00+01:00",
+	}
+	if diff := cmp.Diff(expectedCall, journey.MonitoredCall); diff != "" {
+		t.Errorf("MonitoredCall (-want +got):\n%s", diff)
+	}
+
+	if _, err := client.ParseStopMonitoringDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", "<Siri"); err == nil {
+		t.Fatalf("Expected error for invalid XML; got no error")
+	}
+}
+
+func TestParseStopMonitoringDeliveryMatchesRequest(t *testing.T) {
+	response := `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery>` +
+		`<StopMonitoringDelivery><RequestMessageRef>REQ-1</RequestMessageRef>` +
+		`<MonitoredStopVisit><MonitoringRef>020035811</MonitoringRef></MonitoredStopVisit></StopMonitoringDelivery>` +
+		`<StopMonitoringDelivery><RequestMessageRef>REQ-2</RequestMessageRef>` +
+		`<MonitoredStopVisit><MonitoringRef>020035812</MonitoringRef></MonitoredStopVisit></StopMonitoringDelivery>` +
+		`<StopMonitoringDelivery>` +
+		`<MonitoredStopVisit><MonitoringRef>0200BDA00101</MonitoringRef></MonitoredStopVisit></StopMonitoringDelivery>` +
+		`</ServiceDelivery></Siri>`
+
+	tests := []struct {
+		name                   string
+		requestRef             string
+		expectedMonitoringRefs []string
+	}{
+		{
+			name:                   "Deliveries for the request and without a request ref",
+			requestRef:             "REQ-2",
+			expectedMonitoringRefs: []string{"020035812", "0200BDA00101"},
+		},
+		{
+			name:                   "No delivery for the request",
+			requestRef:             "REQ-3",
+			expectedMonitoringRefs: []string{"0200BDA00101"},
+		},
+	}
+
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			visits, err := client.ParseStopMonitoringDelivery(test.requestRef, response)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			var monitoringRefs []string
+			for _, visit := range visits {
+				monitoringRefs = append(monitoringRefs, visit.MonitoringRef)
+			}
+			if diff := cmp.Diff(test.expectedMonitoringRefs, monitoringRefs); diff != "" {
+				t.Errorf("MonitoringRefs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseStopMonitoringDeliveryStatus(t *testing.T) {
+	response, err := os.ReadFile("testdata/stop_monitoring_status.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+	visits, err := client.ParseStopMonitoringDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", string(response))
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	var statuses []string
+	for _, visit := range visits {
+		journey := visit.MonitoredVehicleJourney
+		statuses = append(statuses, journey.Monitored+" "+journey.MonitoredCall.DepartureStatus)
+	}
+	expected := []string{"true cancelled", "false ", "true delayed"}
+	if diff := cmp.Diff(expected, statuses); diff != "" {
+		t.Errorf("Statuses (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseStopMonitoringDeliveryVehicles(t *testing.T) {
+	response, err := os.ReadFile("testdata/stop_monitoring_vehicles.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+	visits, err := client.ParseStopMonitoringDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", string(response))
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+	if len(visits) != 2 {
+		t.Fatalf("Expected 2 visits; got %d", len(visits))
+	}
+
+	if occupancy := visits[0].MonitoredVehicleJourney.Occupancy; occupancy != "full" {
+		t.Errorf("Expected occupancy full; got %s", occupancy)
+	}
+
+	journey := visits[1].MonitoredVehicleJourney
+	if diff := cmp.Diff([]string{"lowFloor", "wifi"}, journey.VehicleFeatureRef); diff != "" {
+		t.Errorf("VehicleFeatureRef (-want +got):\n%s", diff)
+	}
+	extensions := journey.Extensions.VehicleJourney
+	if extensions.SeatedOccupancy != "10" || extensions.SeatedCapacity != "40" ||
+		extensions.WheelchairOccupancy != "0" || extensions.WheelchairCapacity != "1" {
+		t.Errorf("Extensions not as expected: %+v", extensions)
+	}
+}
+
 func TestSend(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -221,6 +714,18 @@ func TestSend(t *testing.T) {
 			expectedResponse: "Invalid user credentials",
 			expectedError:    errors.New("error status from API: 401"),
 		},
+		{
+			name:    "SOAP fault received from API request",
+			request: "<Siri><ServiceRequest></ServiceRequest></Siri>",
+			response: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+				`<soap:Fault><faultcode>soap:Server</faultcode><faultstring>Service unavailable</faultstring>` +
+				`</soap:Fault></soap:Body></soap:Envelope>`,
+			statusCode: http.StatusInternalServerError,
+			expectedResponse: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+				`<soap:Fault><faultcode>soap:Server</faultcode><faultstring>Service unavailable</faultstring>` +
+				`</soap:Fault></soap:Body></soap:Envelope>`,
+			expectedError: errors.New(`SOAP fault "Server": Service unavailable`),
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
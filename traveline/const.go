@@ -4,6 +4,38 @@ package traveline
 const url = "https://nextbus.mxdata.co.uk/nextbuses/1.0/1"
 const contentType = "application/xml"
 
-// Siri version and XMLNS for request
-const siriVersion = "1.0"
-const siriXMLNS = "http://www.siri.org.uk/"
+// Siri versions that requests can be made with
+const (
+	SiriVersion1 = "1.0"
+	SiriVersion2 = "2.0"
+)
+
+// Siri version used when the client doesn't set one
+const siriVersion = SiriVersion1
+
+// siriNamespaces are the XMLNS for requests of each Siri version
+var siriNamespaces = map[string]string{
+	SiriVersion1: "http://www.siri.org.uk/",
+	SiriVersion2: "http://www.siri.org.uk/siri",
+}
+
+// Namespaces of the SOAP envelopes that Siri deliveries can be wrapped in
+const (
+	soap11XMLNS = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12XMLNS = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// Content type of Siri-Lite deliveries
+const liteContentType = "application/json"
+
+// occupancyLevels maps the Siri 2.0 occupancy onto the Siri 1.0 levels,
+// an unknown occupancy is the same as not publishing it
+var occupancyLevels = map[string]string{
+	"empty":                   "seatsAvailable",
+	"manySeatsAvailable":      "seatsAvailable",
+	"fewSeatsAvailable":       "seatsAvailable",
+	"standingRoomOnly":        "standingAvailable",
+	"crushedStandingRoomOnly": "standingAvailable",
+	"notAcceptingPassengers":  "full",
+	"unknown":                 "",
+}
@@ -0,0 +1,129 @@
+package traveline
+
+import (
+	"encoding/xml"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Consumer is an http.Handler that receives the deliveries a producer pushes to the consumer address of
+// subscriptions, passing them to the callbacks that are set before acknowledging them.
+// The callbacks are called before the producer receives the acknowledgement, so should not block.
+type Consumer struct {
+	// ConsumerRef identifies the consumer in acknowledgements
+	ConsumerRef         string
+	OnStopMonitoring    func(subscriptionRef string, visits []MonitoredStopVisit)
+	OnVehicleMonitoring func(subscriptionRef string, activities []VehicleActivity)
+	OnSituationExchange func(subscriptionRef string, situations []PtSituationElement)
+	// OnHeartbeat is called with the status of the producer when it sends a heartbeat notification
+	OnHeartbeat func(producerRef string, status bool)
+	// Limits bounds the deliveries that are read and parsed, the defaults are used if not set
+	Limits Limits
+}
+
+// ServeHTTP handles a delivery pushed by the producer
+func (c *Consumer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := c.Limits.read(r.Body)
+	var limitErr *LimitExceededError
+	if errors.As(err, &limitErr) {
+		log.Printf("Invalid delivery: %s", err)
+		c.acknowledge(w, http.StatusRequestEntityTooLarge, PushedDelivery{}, err.Error())
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	delivery := PushedDelivery{}
+	if err := unmarshal(body, &delivery, c.Limits); err != nil {
+		log.Printf("Invalid delivery: %s", err)
+		c.acknowledge(w, http.StatusBadRequest, delivery, err.Error())
+		return
+	}
+
+	if heartbeat := delivery.HeartbeatNotification; heartbeat != nil {
+		log.Printf("HeartbeatNotification ProducerRef: %s, Status: %t", heartbeat.ProducerRef, heartbeat.Status)
+		if c.OnHeartbeat != nil {
+			c.OnHeartbeat(heartbeat.ProducerRef, heartbeat.Status)
+		}
+		c.acknowledge(w, http.StatusOK, delivery, "")
+		return
+	}
+
+	serviceDelivery := delivery.ServiceDelivery
+	if serviceDelivery == nil {
+		c.acknowledge(w, http.StatusBadRequest, delivery, "no service delivery or heartbeat notification")
+		return
+	}
+
+	log.Printf("ServiceDelivery ProducerRef: %s", serviceDelivery.ProducerRef)
+
+	for _, stopMonitoring := range serviceDelivery.StopMonitoringDelivery {
+		if c.OnStopMonitoring != nil {
+			c.OnStopMonitoring(stopMonitoring.SubscriptionRef, stopMonitoring.MonitoredStopVisit)
+		}
+	}
+	for _, vehicleMonitoring := range serviceDelivery.VehicleMonitoringDelivery {
+		if c.OnVehicleMonitoring != nil {
+			c.OnVehicleMonitoring(vehicleMonitoring.SubscriptionRef, vehicleMonitoring.VehicleActivity)
+		}
+	}
+	for _, situationExchange := range serviceDelivery.SituationExchangeDelivery {
+		if c.OnSituationExchange != nil {
+			c.OnSituationExchange(situationExchange.SubscriptionRef, situationExchange.Situations)
+		}
+	}
+
+	c.acknowledge(w, http.StatusOK, delivery, "")
+}
+
+// acknowledge writes the data received acknowledgement in the Siri version of the delivery,
+// the delivery failed if there is an error description
+func (c *Consumer) acknowledge(w http.ResponseWriter, statusCode int, delivery PushedDelivery, errorDescription string) {
+	version, xmlns := deliveryVersion(delivery)
+	acknowledgement := &DataReceivedAcknowledgement{
+		Version:           version,
+		XMLNS:             xmlns,
+		ResponseTimestamp: time.Now().Format(time.RFC3339),
+		ConsumerRef:       c.ConsumerRef,
+		Status:            errorDescription == "",
+		ErrorDescription:  errorDescription,
+	}
+
+	body, err := xml.Marshal(acknowledgement)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-type", contentType)
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(body); err != nil {
+		log.Printf("Failed to acknowledge delivery: %s", err)
+	}
+}
+
+// deliveryVersion returns the Siri version and XMLNS of the delivery, found from its namespace or
+// version, the default version is used if neither are known
+func deliveryVersion(delivery PushedDelivery) (string, string) {
+	for version, xmlns := range siriNamespaces {
+		if delivery.XMLName.Space == xmlns {
+			return version, xmlns
+		}
+	}
+	if xmlns, ok := siriNamespaces[delivery.Version]; ok {
+		return delivery.Version, xmlns
+	}
+
+	return siriVersion, siriNamespaces[siriVersion]
+}
@@ -0,0 +1,171 @@
+package traveline_test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConsumer(t *testing.T) {
+	delivery, err := os.ReadFile("testdata/pushed_delivery.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		body               string
+		expectedStatusCode int
+		expectedAck        bool
+		expectedXMLNS      string
+		expectedCallbacks  []string
+	}{
+		{
+			name:               "Service delivery",
+			method:             http.MethodPost,
+			body:               string(delivery),
+			expectedStatusCode: http.StatusOK,
+			expectedAck:        true,
+			expectedXMLNS:      "http://www.siri.org.uk/",
+			expectedCallbacks: []string{
+				"stop SUB-STOP 0180BAC30249 1042",
+				"vehicles SUB-VEHICLES GLBE-1001",
+				"situations SUB-SITUATIONS SIT-1",
+			},
+		},
+		{
+			name:               "Siri 2.0 service delivery",
+			method:             http.MethodPost,
+			body:               strings.Replace(string(delivery), `"http://www.siri.org.uk/"`, `"http://www.siri.org.uk/siri"`, 1),
+			expectedStatusCode: http.StatusOK,
+			expectedAck:        true,
+			expectedXMLNS:      "http://www.siri.org.uk/siri",
+			expectedCallbacks: []string{
+				"stop SUB-STOP 0180BAC30249 1042",
+				"vehicles SUB-VEHICLES GLBE-1001",
+				"situations SUB-SITUATIONS SIT-1",
+			},
+		},
+		{
+			name:   "SOAP wrapped service delivery",
+			method: http.MethodPost,
+			body: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+				strings.TrimPrefix(string(delivery), `<?xml version="1.0" encoding="UTF-8"?>`) +
+				`</soap:Body></soap:Envelope>`,
+			expectedStatusCode: http.StatusOK,
+			expectedAck:        true,
+			expectedXMLNS:      "http://www.siri.org.uk/",
+			expectedCallbacks: []string{
+				"stop SUB-STOP 0180BAC30249 1042",
+				"vehicles SUB-VEHICLES GLBE-1001",
+				"situations SUB-SITUATIONS SIT-1",
+			},
+		},
+		{
+			name:   "Heartbeat notification",
+			method: http.MethodPost,
+			body: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><HeartbeatNotification>` +
+				`<RequestTimestamp>2020-03-30T12:35:00+01:00</RequestTimestamp><ProducerRef>NextBuses</ProducerRef>` +
+				`<Status>true</Status></HeartbeatNotification></Siri>`,
+			expectedStatusCode: http.StatusOK,
+			expectedAck:        true,
+			expectedXMLNS:      "http://www.siri.org.uk/",
+			expectedCallbacks:  []string{"heartbeat NextBuses true"},
+		},
+		{
+			name:   "Siri 2.0 heartbeat notification",
+			method: http.MethodPost,
+			body: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><HeartbeatNotification>` +
+				`<RequestTimestamp>2020-03-30T12:35:00+01:00</RequestTimestamp><ProducerRef>NextBuses</ProducerRef>` +
+				`<Status>true</Status></HeartbeatNotification></Siri>`,
+			expectedStatusCode: http.StatusOK,
+			expectedAck:        true,
+			expectedXMLNS:      "http://www.siri.org.uk/siri",
+			expectedCallbacks:  []string{"heartbeat NextBuses true"},
+		},
+		{
+			name:               "Invalid XML",
+			method:             http.MethodPost,
+			body:               "<Siri",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedXMLNS:      "http://www.siri.org.uk/",
+		},
+		{
+			name:               "Unknown message",
+			method:             http.MethodPost,
+			body:               `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><CheckStatusRequest/></Siri>`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedXMLNS:      "http://www.siri.org.uk/",
+		},
+		{
+			name:               "Not a POST",
+			method:             http.MethodGet,
+			expectedStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var callbacks []string
+			consumer := &traveline.Consumer{
+				ConsumerRef: "TravelineAPI999",
+				OnStopMonitoring: func(subscriptionRef string, visits []traveline.MonitoredStopVisit) {
+					for _, visit := range visits {
+						callbacks = append(callbacks, "stop "+subscriptionRef+" "+visit.MonitoringRef+" "+
+							visit.MonitoredVehicleJourney.FramedVehicleJourneyRef.DatedVehicleJourneyRef)
+					}
+				},
+				OnVehicleMonitoring: func(subscriptionRef string, activities []traveline.VehicleActivity) {
+					for _, activity := range activities {
+						callbacks = append(callbacks, "vehicles "+subscriptionRef+" "+activity.MonitoredVehicleJourney.VehicleRef)
+					}
+				},
+				OnSituationExchange: func(subscriptionRef string, situations []traveline.PtSituationElement) {
+					for _, situation := range situations {
+						callbacks = append(callbacks, "situations "+subscriptionRef+" "+situation.SituationNumber)
+					}
+				},
+				OnHeartbeat: func(producerRef string, status bool) {
+					if status {
+						callbacks = append(callbacks, "heartbeat "+producerRef+" true")
+					}
+				},
+			}
+
+			request := httptest.NewRequest(test.method, "/siri", strings.NewReader(test.body))
+			recorder := httptest.NewRecorder()
+			consumer.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.expectedStatusCode {
+				t.Fatalf("Expected status code %d; got %d", test.expectedStatusCode, recorder.Code)
+			}
+			if diff := cmp.Diff(test.expectedCallbacks, callbacks); diff != "" {
+				t.Errorf("Callbacks (-want +got):\n%s", diff)
+			}
+
+			if recorder.Code == http.StatusMethodNotAllowed {
+				return
+			}
+			acknowledgement := traveline.DataReceivedAcknowledgement{}
+			if err := xml.Unmarshal(recorder.Body.Bytes(), &acknowledgement); err != nil {
+				t.Fatalf("Expected acknowledgement; got '%s'", err)
+			}
+			if acknowledgement.Status != test.expectedAck {
+				t.Errorf("Expected acknowledgement status %t; got %t", test.expectedAck, acknowledgement.Status)
+			}
+			if acknowledgement.XMLNS != test.expectedXMLNS {
+				t.Errorf("Expected acknowledgement XMLNS %s; got %s", test.expectedXMLNS, acknowledgement.XMLNS)
+			}
+			if acknowledgement.ConsumerRef != "TravelineAPI999" {
+				t.Errorf("Expected consumer ref TravelineAPI999; got %s", acknowledgement.ConsumerRef)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package traveline
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// unmarshal decodes the Siri element of the response into v. The Siri element can be in the default
+// namespace, have a prefix, or be in the body of a SOAP 1.1 or SOAP 1.2 envelope.
+// A SOAP Fault in the body is returned as a SOAPFaultError. The response is checked against the limits first.
+func unmarshal(response []byte, v interface{}, limits Limits) error {
+	if err := limits.check(response); err != nil {
+		return err
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(response))
+
+	root, err := child(decoder)
+	if err != nil {
+		return err
+	}
+	if root == nil {
+		return errors.New("no Siri element found")
+	}
+	if !isSOAP(root.Name, "Envelope") {
+		return decoder.DecodeElement(v, root)
+	}
+
+	for {
+		element, err := child(decoder)
+		if err != nil {
+			return err
+		}
+		if element == nil {
+			return errors.New("no body found in SOAP envelope")
+		}
+		if isSOAP(element.Name, "Body") {
+			return unmarshalBody(decoder, v)
+		}
+		if err := decoder.Skip(); err != nil {
+			return err
+		}
+	}
+}
+
+// unmarshalBody decodes the SOAP Fault or the first Siri element in the SOAP body,
+// which may be wrapped in the elements of the operation
+func unmarshalBody(decoder *xml.Decoder, v interface{}) error {
+	element, err := child(decoder)
+	if err != nil {
+		return err
+	}
+	if element == nil {
+		return errors.New("no Siri element found in SOAP body")
+	}
+
+	if isSOAP(element.Name, "Fault") {
+		fault := soapFault{}
+		if err := decoder.DecodeElement(&fault, element); err != nil {
+			return err
+		}
+		return fault.error()
+	}
+
+	for depth := 1; element.Name.Local != "Siri"; {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			element = &token
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				return errors.New("no Siri element found in SOAP body")
+			}
+		}
+	}
+
+	return decoder.DecodeElement(v, element)
+}
+
+// faultError returns the SOAPFaultError if the response is a SOAP Fault, otherwise nil
+func faultError(response []byte, limits Limits) error {
+	err := unmarshal(response, &struct{}{}, limits)
+
+	var faultErr *SOAPFaultError
+	if errors.As(err, &faultErr) {
+		return faultErr
+	}
+
+	return nil
+}
+
+// child returns the next child element of the decoder, or nil if its parent element ends
+func child(decoder *xml.Decoder) (*xml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			return &token, nil
+		case xml.EndElement:
+			return nil, nil
+		}
+	}
+}
+
+// isSOAP returns whether the name is the SOAP 1.1 or SOAP 1.2 element
+func isSOAP(name xml.Name, local string) bool {
+	return name.Local == local && (name.Space == soap11XMLNS || name.Space == soap12XMLNS)
+}
+
+// error returns the SOAPFaultError of either SOAP version, removing the prefixes of the codes
+func (f soapFault) error() error {
+	fault := &SOAPFaultError{
+		Code:    unprefixed(f.FaultCode),
+		Subcode: unprefixed(f.Subcode),
+		Reason:  strings.TrimSpace(f.FaultString),
+	}
+	if f.Code != "" {
+		fault.Code = unprefixed(f.Code)
+		fault.Reason = strings.TrimSpace(f.Reason)
+	}
+
+	return fault
+}
+
+// unprefixed returns the qualified name without its prefix
+func unprefixed(name string) string {
+	name = strings.TrimSpace(name)
+	return name[strings.LastIndex(name, ":")+1:]
+}
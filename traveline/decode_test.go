@@ -0,0 +1,113 @@
+package traveline_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/go-cmp/cmp"
+)
+
+const stopMonitoringDelivery = `<ServiceDelivery><StopMonitoringDelivery><MonitoredStopVisit>` +
+	`<MonitoredVehicleJourney><PublishedLineName>42</PublishedLineName>` +
+	`<MonitoredCall><AimedDepartureTime>2020-03-30T12:40:00+01:00</AimedDepartureTime></MonitoredCall>` +
+	`</MonitoredVehicleJourney></MonitoredStopVisit></StopMonitoringDelivery></ServiceDelivery>`
+
+const prefixedStopMonitoringDelivery = `<siri:ServiceDelivery><siri:StopMonitoringDelivery><siri:MonitoredStopVisit>` +
+	`<siri:MonitoredVehicleJourney><siri:PublishedLineName>42</siri:PublishedLineName>` +
+	`<siri:MonitoredCall><siri:AimedDepartureTime>2020-03-30T12:40:00+01:00</siri:AimedDepartureTime></siri:MonitoredCall>` +
+	`</siri:MonitoredVehicleJourney></siri:MonitoredStopVisit></siri:StopMonitoringDelivery></siri:ServiceDelivery>`
+
+func TestParseServiceDeliveryEncodings(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      string
+		expectedError error
+	}{
+		{
+			name:     "Default namespace",
+			response: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri">` + stopMonitoringDelivery + `</Siri>`,
+		},
+		{
+			name: "Prefixed namespace",
+			response: `<siri:Siri version="2.0" xmlns:siri="http://www.siri.org.uk/siri">` +
+				prefixedStopMonitoringDelivery + `</siri:Siri>`,
+		},
+		{
+			name: "SOAP 1.1 envelope",
+			response: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">` +
+				`<soap:Header><Security>token</Security></soap:Header><soap:Body>` +
+				`<Siri version="1.0" xmlns="http://www.siri.org.uk/">` + stopMonitoringDelivery + `</Siri>` +
+				`</soap:Body></soap:Envelope>`,
+		},
+		{
+			name: "SOAP 1.2 envelope with prefixed Siri in an operation",
+			response: `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body>` +
+				`<siri:GetStopMonitoringResponse xmlns:siri="http://www.siri.org.uk/siri"><siri:Siri version="2.0">` +
+				prefixedStopMonitoringDelivery + `</siri:Siri></siri:GetStopMonitoringResponse>` +
+				`</env:Body></env:Envelope>`,
+		},
+		{
+			name: "SOAP 1.1 fault",
+			response: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+				`<soap:Fault><faultcode>soap:Client</faultcode><faultstring>Unknown requestor</faultstring>` +
+				`</soap:Fault></soap:Body></soap:Envelope>`,
+			expectedError: &traveline.SOAPFaultError{Code: "Client", Reason: "Unknown requestor"},
+		},
+		{
+			name: "SOAP 1.2 fault",
+			response: `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>` +
+				`<env:Code><env:Value>env:Sender</env:Value><env:Subcode><env:Value>siri:AccessNotAllowed</env:Value>` +
+				`</env:Subcode></env:Code><env:Reason><env:Text xml:lang="en">Access not allowed</env:Text></env:Reason>` +
+				`</env:Fault></env:Body></env:Envelope>`,
+			expectedError: &traveline.SOAPFaultError{Code: "Sender", Subcode: "AccessNotAllowed", Reason: "Access not allowed"},
+		},
+		{
+			name: "SOAP envelope without Siri",
+			response: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+				`<GetStopMonitoringResponse><Answer/></GetStopMonitoringResponse></soap:Body></soap:Envelope>`,
+			expectedError: errors.New("no Siri element found in SOAP body"),
+		},
+		{
+			name:          "SOAP envelope without body",
+			response:      `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"></soap:Envelope>`,
+			expectedError: errors.New("no body found in SOAP envelope"),
+		},
+	}
+
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			journey, err := client.ParseServiceDelivery(test.response)
+
+			if test.expectedError != nil {
+				if err == nil || err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError, err)
+				}
+				var faultErr *traveline.SOAPFaultError
+				if expectedFault, ok := test.expectedError.(*traveline.SOAPFaultError); ok {
+					if !errors.As(err, &faultErr) {
+						t.Fatalf("Expected SOAPFaultError; got %T", err)
+					}
+					if diff := cmp.Diff(expectedFault, faultErr); diff != "" {
+						t.Errorf("SOAPFaultError (-want +got):\n%s", diff)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			expectedCall := traveline.MonitoredCall{AimedDepartureTime: "2020-03-30T12:40:00+01:00"}
+			if journey.PublishedLineName != "42" {
+				t.Errorf("Expected line 42; got %s", journey.PublishedLineName)
+			}
+			if diff := cmp.Diff(expectedCall, journey.MonitoredCall); diff != "" {
+				t.Errorf("MonitoredCall (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
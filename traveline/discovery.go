@@ -0,0 +1,212 @@
+package traveline
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BuildStopPointsRequest will return the XML for the request for the stops that the producer covers
+func (c *Client) BuildStopPointsRequest(when time.Time) (string, error) {
+	request, err := c.newRequest()
+	if err != nil {
+		return "", err
+	}
+
+	stopPointsRequest := c.requestor(when)
+	request.StopPointsRequest = &stopPointsRequest
+
+	log.Printf("StopPointsRequest RequestTimestamp: %s", stopPointsRequest.RequestTimestamp)
+
+	return request.Marshal()
+}
+
+// ParseStopPointsDelivery will parse the response from the producer and return the stops it covers
+func (c *Client) ParseStopPointsDelivery(response string) ([]AnnotatedStopPointRef, error) {
+	stopPointsDelivery := StopPointsDelivery{}
+	err := unmarshal([]byte(response), &stopPointsDelivery, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	stopPoints := stopPointsDelivery.StopPointsDelivery.AnnotatedStopPointRef
+	log.Printf("StopPoints: %d", len(stopPoints))
+
+	return stopPoints, nil
+}
+
+// BuildLinesRequest will return the XML for the request for the lines that the producer covers
+func (c *Client) BuildLinesRequest(when time.Time) (string, error) {
+	request, err := c.newRequest()
+	if err != nil {
+		return "", err
+	}
+
+	linesRequest := c.requestor(when)
+	request.LinesRequest = &linesRequest
+
+	log.Printf("LinesRequest RequestTimestamp: %s", linesRequest.RequestTimestamp)
+
+	return request.Marshal()
+}
+
+// ParseLinesDelivery will parse the response from the producer and return the lines it covers
+func (c *Client) ParseLinesDelivery(response string) ([]AnnotatedLineRef, error) {
+	linesDelivery := LinesDelivery{}
+	err := unmarshal([]byte(response), &linesDelivery, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := linesDelivery.LinesDelivery.AnnotatedLineRef
+	log.Printf("Lines: %d", len(lines))
+
+	return lines, nil
+}
+
+// Discovery is used to find the stops and lines that the producer covers, caching them so that
+// codes and line filters can be checked before spending a request on them
+type Discovery struct {
+	API DiscoveryAPI
+	// TTL is how long the stops and lines are cached before they are requested again
+	TTL time.Duration
+
+	mu             sync.Mutex
+	stopPoints     map[string]AnnotatedStopPointRef
+	stopsErr       error
+	stopsFetchedAt time.Time
+	lines          map[string]AnnotatedLineRef
+	linesErr       error
+	linesFetchedAt time.Time
+}
+
+// NewDiscovery returns the discovery that caches the stops and lines for the TTL
+func NewDiscovery(api DiscoveryAPI, ttl time.Duration) *Discovery {
+	return &Discovery{API: api, TTL: ttl}
+}
+
+// StopPoints returns the stops that the producer covers by their upper case code,
+// requesting them if they are not cached or have expired.
+// A failed request is cached for the TTL too, so a producer that is down is not asked on every call.
+func (d *Discovery) StopPoints(when time.Time) (map[string]AnnotatedStopPointRef, error) {
+	d.mu.Lock()
+	if (d.stopPoints != nil || d.stopsErr != nil) && when.Before(d.stopsFetchedAt.Add(d.TTL)) {
+		defer d.mu.Unlock()
+		return d.stopPoints, d.stopsErr
+	}
+	d.mu.Unlock()
+
+	// The lock isn't held while requesting, so other calls aren't blocked on the producer
+	stopPoints, err := d.requestStopPoints(when)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopPoints, d.stopsErr, d.stopsFetchedAt = stopPoints, err, when
+
+	return stopPoints, err
+}
+
+// requestStopPoints requests the stops from the producer, keyed by their upper case code
+func (d *Discovery) requestStopPoints(when time.Time) (map[string]AnnotatedStopPointRef, error) {
+	request, err := d.API.BuildStopPointsRequest(when)
+	if err != nil {
+		return nil, err
+	}
+	response, err := d.API.Send(request)
+	if err != nil {
+		return nil, err
+	}
+	stopPoints, err := d.API.ParseStopPointsDelivery(response)
+	if err != nil {
+		return nil, err
+	}
+
+	byCode := make(map[string]AnnotatedStopPointRef, len(stopPoints))
+	for _, stopPoint := range stopPoints {
+		byCode[strings.ToUpper(stopPoint.StopPointRef)] = stopPoint
+	}
+
+	return byCode, nil
+}
+
+// Lines returns the lines that the producer covers by their upper case ref,
+// requesting them if they are not cached or have expired.
+// A failed request is cached for the TTL, as for the stops.
+func (d *Discovery) Lines(when time.Time) (map[string]AnnotatedLineRef, error) {
+	d.mu.Lock()
+	if (d.lines != nil || d.linesErr != nil) && when.Before(d.linesFetchedAt.Add(d.TTL)) {
+		defer d.mu.Unlock()
+		return d.lines, d.linesErr
+	}
+	d.mu.Unlock()
+
+	lines, err := d.requestLines(when)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lines, d.linesErr, d.linesFetchedAt = lines, err, when
+
+	return lines, err
+}
+
+// requestLines requests the lines from the producer, keyed by their upper case ref
+func (d *Discovery) requestLines(when time.Time) (map[string]AnnotatedLineRef, error) {
+	request, err := d.API.BuildLinesRequest(when)
+	if err != nil {
+		return nil, err
+	}
+	response, err := d.API.Send(request)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := d.API.ParseLinesDelivery(response)
+	if err != nil {
+		return nil, err
+	}
+
+	byRef := make(map[string]AnnotatedLineRef, len(lines))
+	for _, line := range lines {
+		byRef[strings.ToUpper(line.LineRef)] = line
+	}
+
+	return byRef, nil
+}
+
+// ValidateStop checks that the producer covers the stop with the code.
+// Producers that don't support discovery return no stops, so every stop is assumed to be covered.
+func (d *Discovery) ValidateStop(naptanCode string, when time.Time) error {
+	stopPoints, err := d.StopPoints(when)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := stopPoints[strings.ToUpper(naptanCode)]; !ok && len(stopPoints) > 0 {
+		return &StopNotCoveredError{NaptanCode: naptanCode}
+	}
+
+	return nil
+}
+
+// ValidateLine checks that the producer covers the line, which can be given by its ref or name.
+// Producers that don't support discovery return no lines, so every line is assumed to be covered.
+func (d *Discovery) ValidateLine(lineRef string, when time.Time) error {
+	lines, err := d.Lines(when)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	if _, ok := lines[strings.ToUpper(lineRef)]; ok {
+		return nil
+	}
+	for _, line := range lines {
+		if strings.EqualFold(line.LineName, lineRef) {
+			return nil
+		}
+	}
+
+	return &LineNotCoveredError{LineRef: lineRef}
+}
@@ -0,0 +1,420 @@
+package traveline_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/conradhodge/travel-api-client/internal/siritest"
+	"github.com/conradhodge/travel-api-client/mock/mock_traveline"
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildDiscoveryRequests(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+	request, err := client.BuildStopPointsRequest(when)
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+	expectedRequest := `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><StopPointsRequest>` +
+		`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+		`</StopPointsRequest></Siri>`
+	if request != expectedRequest {
+		t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(expectedRequest, request))
+	}
+
+	request, err = client.BuildLinesRequest(when)
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+	expectedRequest = `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><LinesRequest>` +
+		`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+		`</LinesRequest></Siri>`
+	if request != expectedRequest {
+		t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(expectedRequest, request))
+	}
+}
+
+func TestParseStopPointsDelivery(t *testing.T) {
+	response, err := os.ReadFile("testdata/stop_points.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+	stopPoints, err := client.ParseStopPointsDelivery(string(response))
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	expected := []traveline.AnnotatedStopPointRef{
+		{StopPointRef: "0180BAC30249", Monitored: true, StopName: "The Green", LineRef: []string{"42", "X39"}},
+		{StopPointRef: "0180BAC30250", StopName: "Station Road"},
+	}
+	if diff := cmp.Diff(expected, stopPoints); diff != "" {
+		t.Errorf("ParseStopPointsDelivery() (-want +got):\n%s", diff)
+	}
+
+	if _, err := client.ParseStopPointsDelivery("<Siri"); err == nil {
+		t.Fatalf("Expected error for invalid XML; got no error")
+	}
+}
+
+func TestParseStopPointsDeliveryVersion2(t *testing.T) {
+	response, err := os.ReadFile("testdata/stop_points_v2.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein", Version: traveline.SiriVersion2}
+
+	stopPoints, err := client.ParseStopPointsDelivery(string(response))
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	expected := []traveline.AnnotatedStopPointRef{
+		{StopPointRef: "0180BAC30249", Monitored: true, StopName: "The Green", LineRef: []string{"42", "X39"}},
+		{StopPointRef: "0180BAC30250", StopName: "Station Road"},
+	}
+	if diff := cmp.Diff(expected, stopPoints); diff != "" {
+		t.Errorf("ParseStopPointsDelivery() (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseLinesDelivery(t *testing.T) {
+	response, err := os.ReadFile("testdata/lines.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+	lines, err := client.ParseLinesDelivery(string(response))
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 line; got %d", len(lines))
+	}
+	line := lines[0]
+	if line.LineRef != "GLBE:42" || line.LineName != "42" || !line.Monitored {
+		t.Errorf("Line not as expected: %+v", line)
+	}
+	if len(line.Destinations) != 1 || line.Destinations[0].PlaceName != "Bath Spa" {
+		t.Errorf("Destinations not as expected: %+v", line.Destinations)
+	}
+	if len(line.Directions) != 1 || line.Directions[0].DirectionRef != "outbound" {
+		t.Errorf("Directions not as expected: %+v", line.Directions)
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	stopPoints, err := os.ReadFile("testdata/stop_points.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	lines, err := os.ReadFile("testdata/lines.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tests := []struct {
+		name          string
+		stopPoints    string
+		lines         string
+		naptanCode    string
+		lineRef       string
+		expectedError error
+	}{
+		{
+			name:       "Covered stop and line ref",
+			stopPoints: string(stopPoints),
+			lines:      string(lines),
+			naptanCode: "0180bac30249",
+			lineRef:    "GLBE:42",
+		},
+		{
+			name:       "Covered line name",
+			stopPoints: string(stopPoints),
+			lines:      string(lines),
+			naptanCode: "0180BAC30250",
+			lineRef:    "42",
+		},
+		{
+			name:          "Stop not covered",
+			stopPoints:    string(stopPoints),
+			lines:         string(lines),
+			naptanCode:    "0180BAC30999",
+			expectedError: errors.New(`Stop "0180BAC30999" is not covered by the producer`),
+		},
+		{
+			name:          "Line not covered",
+			stopPoints:    string(stopPoints),
+			lines:         string(lines),
+			naptanCode:    "0180BAC30249",
+			lineRef:       "X39",
+			expectedError: errors.New(`Line "X39" is not covered by the producer`),
+		},
+		{
+			name:       "Discovery not supported",
+			stopPoints: `<Siri><StopPointsDelivery/></Siri>`,
+			lines:      `<Siri><LinesDelivery/></Siri>`,
+			naptanCode: "0180BAC30999",
+			lineRef:    "X39",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := siritest.NewProducer(t).
+				WithAuth("TravelineAPI999", "letmein").
+				Respond("StopPointsRequest", http.StatusOK, test.stopPoints).
+				Respond("LinesRequest", http.StatusOK, test.lines)
+			defer server.Close()
+
+			client := &traveline.Client{
+				Username: "TravelineAPI999",
+				Password: "letmein",
+				URL:      server.URL,
+				Client:   server.Client(),
+			}
+			discovery := traveline.NewDiscovery(client, time.Hour)
+
+			err := discovery.ValidateStop(test.naptanCode, when)
+			if err == nil && test.lineRef != "" {
+				err = discovery.ValidateLine(test.lineRef, when)
+			}
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+		})
+	}
+}
+
+func TestDiscoveryCache(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	stopPoints, err := os.ReadFile("testdata/stop_points.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	server := siritest.NewProducer(t).
+		WithAuth("TravelineAPI999", "letmein").
+		Respond("StopPointsRequest", http.StatusOK, string(stopPoints))
+	defer server.Close()
+
+	client := &traveline.Client{
+		Username: "TravelineAPI999",
+		Password: "letmein",
+		URL:      server.URL,
+		Client:   server.Client(),
+	}
+	discovery := traveline.NewDiscovery(client, time.Hour)
+
+	for _, offset := range []time.Duration{0, 30 * time.Minute, 59 * time.Minute, time.Hour} {
+		if _, err := discovery.StopPoints(when.Add(offset)); err != nil {
+			t.Fatalf("Expected no error; got '%s'", err)
+		}
+	}
+
+	// Requested again once the TTL has passed
+	if requests := server.Received(); len(requests) != 2 {
+		t.Fatalf("Expected 2 requests; got %d", len(requests))
+	}
+}
+
+func TestDiscoveryCachesFailures(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	lines, err := os.ReadFile("testdata/lines.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	server := siritest.NewProducer(t).
+		WithAuth("TravelineAPI999", "letmein").
+		RespondOnce("LinesRequest", http.StatusInternalServerError, "").
+		Respond("LinesRequest", http.StatusOK, string(lines))
+	defer server.Close()
+
+	client := &traveline.Client{
+		Username: "TravelineAPI999",
+		Password: "letmein",
+		URL:      server.URL,
+		Client:   server.Client(),
+	}
+	discovery := traveline.NewDiscovery(client, time.Hour)
+
+	// The failure is returned until the TTL has passed, without requesting the lines again
+	for _, offset := range []time.Duration{0, 30 * time.Minute} {
+		if _, err := discovery.Lines(when.Add(offset)); err == nil {
+			t.Fatalf("Expected error at %s; got no error", offset)
+		}
+	}
+	if requests := server.Received(); len(requests) != 1 {
+		t.Fatalf("Expected 1 request; got %d", len(requests))
+	}
+
+	if _, err := discovery.Lines(when.Add(time.Hour)); err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+	if requests := server.Received(); len(requests) != 2 {
+		t.Fatalf("Expected 2 requests; got %d", len(requests))
+	}
+}
+
+func TestDiscoveryDoesNotBlockWhileRequesting(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_traveline.NewMockAPI(ctrl)
+	api.EXPECT().BuildStopPointsRequest(when).Return("stops", nil)
+	api.EXPECT().Send("stops").Return("stops", nil)
+	api.EXPECT().ParseStopPointsDelivery("stops").
+		Return([]traveline.AnnotatedStopPointRef{{StopPointRef: "0180BAC30249"}}, nil)
+
+	discovery := traveline.NewDiscovery(api, time.Hour)
+	if err := discovery.ValidateStop("0180BAC30249", when); err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	// The lines request is held by the producer until the cached stops have been read
+	requested := make(chan struct{})
+	release := make(chan struct{})
+	api.EXPECT().BuildLinesRequest(when).Return("lines", nil)
+	api.EXPECT().Send("lines").DoAndReturn(func(string) (string, error) {
+		close(requested)
+		<-release
+		return "lines", nil
+	})
+	api.EXPECT().ParseLinesDelivery("lines").Return(nil, nil)
+
+	done := make(chan error)
+	go func() {
+		done <- discovery.ValidateLine("42", when)
+	}()
+	<-requested
+
+	stopPoints := make(chan error)
+	go func() {
+		stopPoints <- discovery.ValidateStop("0180BAC30249", when)
+	}()
+	select {
+	case err := <-stopPoints:
+		if err != nil {
+			t.Errorf("Expected no error; got '%s'", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Expected the cached stops while the lines are requested")
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+}
+
+func TestDiscoveryVersions(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+
+	tests := []struct {
+		name                      string
+		version                   string
+		expectedStopPointsRequest string
+		stopPointsResponse        string
+		expectedLinesRequest      string
+		linesResponse             string
+	}{
+		{
+			name:    "Siri 1.0",
+			version: traveline.SiriVersion1,
+			expectedStopPointsRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><StopPointsRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`</StopPointsRequest></Siri>`,
+			stopPointsResponse: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><StopPointsDelivery version="1.0">` +
+				`<AnnotatedStopPointRef><StopPointRef>0180BAC30249</StopPointRef><Monitored>true</Monitored>` +
+				`<StopName>The Green</StopName><Lines><LineRef>42</LineRef></Lines></AnnotatedStopPointRef>` +
+				`</StopPointsDelivery></Siri>`,
+			expectedLinesRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><LinesRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`</LinesRequest></Siri>`,
+			linesResponse: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><LinesDelivery version="1.0">` +
+				`<AnnotatedLineRef><LineRef>GLBE:42</LineRef><LineName>42</LineName><Monitored>true</Monitored>` +
+				`</AnnotatedLineRef></LinesDelivery></Siri>`,
+		},
+		{
+			name:    "Siri 2.0",
+			version: traveline.SiriVersion2,
+			expectedStopPointsRequest: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><StopPointsRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`</StopPointsRequest></Siri>`,
+			stopPointsResponse: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><StopPointsDelivery version="2.0">` +
+				`<AnnotatedStopPointRef><StopPointRef>0180BAC30249</StopPointRef><Monitored>true</Monitored>` +
+				`<StopName>The Green</StopName><Lines><LineDirection><LineRef>42</LineRef></LineDirection></Lines>` +
+				`</AnnotatedStopPointRef></StopPointsDelivery></Siri>`,
+			expectedLinesRequest: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><LinesRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`</LinesRequest></Siri>`,
+			linesResponse: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><LinesDelivery version="2.0">` +
+				`<AnnotatedLineRef><LineRef>GLBE:42</LineRef><LineName>42</LineName><Monitored>true</Monitored>` +
+				`</AnnotatedLineRef></LinesDelivery></Siri>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein", Version: test.version}
+
+			request, err := client.BuildStopPointsRequest(when)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if request != test.expectedStopPointsRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedStopPointsRequest, request))
+			}
+
+			stopPoints, err := client.ParseStopPointsDelivery(test.stopPointsResponse)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			expectedStopPoints := []traveline.AnnotatedStopPointRef{
+				{StopPointRef: "0180BAC30249", Monitored: true, StopName: "The Green", LineRef: []string{"42"}},
+			}
+			if diff := cmp.Diff(expectedStopPoints, stopPoints); diff != "" {
+				t.Errorf("ParseStopPointsDelivery() (-want +got):\n%s", diff)
+			}
+
+			request, err = client.BuildLinesRequest(when)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if request != test.expectedLinesRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedLinesRequest, request))
+			}
+
+			lines, err := client.ParseLinesDelivery(test.linesResponse)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if len(lines) != 1 || lines[0].LineRef != "GLBE:42" || lines[0].LineName != "42" || !lines[0].Monitored {
+				t.Fatalf("Lines not as expected: %+v", lines)
+			}
+		})
+	}
+}
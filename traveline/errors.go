@@ -1,8 +1,81 @@
 package traveline
 
+import (
+	"fmt"
+	"time"
+)
+
 // NoTimesFoundError indicates that no departure times can be found
 type NoTimesFoundError struct{}
 
 func (e NoTimesFoundError) Error() string {
 	return "No next departure times found"
 }
+
+// SubscriptionError indicates that the producer did not accept a subscription request or its termination
+type SubscriptionError struct {
+	SubscriptionRef string
+	Reason          string
+}
+
+func (e SubscriptionError) Error() string {
+	return fmt.Sprintf("Subscription \"%s\" failed: %s", e.SubscriptionRef, e.Reason)
+}
+
+// StopNotCoveredError indicates that the producer does not cover the stop
+type StopNotCoveredError struct {
+	NaptanCode string
+}
+
+func (e StopNotCoveredError) Error() string {
+	return fmt.Sprintf("Stop \"%s\" is not covered by the producer", e.NaptanCode)
+}
+
+// LineNotCoveredError indicates that the producer does not cover the line
+type LineNotCoveredError struct {
+	LineRef string
+}
+
+func (e LineNotCoveredError) Error() string {
+	return fmt.Sprintf("Line \"%s\" is not covered by the producer", e.LineRef)
+}
+
+// SOAPFaultError indicates that the producer returned a SOAP Fault rather than a delivery.
+// The code is the SOAP 1.1 fault code or SOAP 1.2 code value without its prefix, e.g. Client or Sender.
+type SOAPFaultError struct {
+	Code    string
+	Subcode string
+	Reason  string
+}
+
+func (e SOAPFaultError) Error() string {
+	return fmt.Sprintf("SOAP fault \"%s\": %s", e.Code, e.Reason)
+}
+
+// LimitExceededError indicates that a response is larger than one of the limits of the client
+type LimitExceededError struct {
+	Limit string
+	Max   int64
+}
+
+func (e LimitExceededError) Error() string {
+	return fmt.Sprintf("Response exceeds the %s limit of %d", e.Limit, e.Max)
+}
+
+// InvalidIntervalError indicates that the interval to run at is not positive
+type InvalidIntervalError struct {
+	Interval time.Duration
+}
+
+func (e InvalidIntervalError) Error() string {
+	return fmt.Sprintf("Invalid interval %s, it must be positive", e.Interval)
+}
+
+// InvalidDurationError indicates that the duration of a subscription is not positive
+type InvalidDurationError struct {
+	Duration time.Duration
+}
+
+func (e InvalidDurationError) Error() string {
+	return fmt.Sprintf("Invalid duration %s, it must be positive", e.Duration)
+}
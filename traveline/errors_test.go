@@ -2,6 +2,7 @@ package traveline_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/conradhodge/travel-api-client/traveline"
 )
@@ -15,3 +16,73 @@ func TestNoTimesFoundError(t *testing.T) {
 		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
 	}
 }
+
+func TestSubscriptionError(t *testing.T) {
+	err := traveline.SubscriptionError{SubscriptionRef: "SUB-STOP", Reason: "rejected by producer"}
+
+	expectedError := `Subscription "SUB-STOP" failed: rejected by producer`
+
+	if err.Error() != expectedError {
+		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
+	}
+}
+
+func TestStopNotCoveredError(t *testing.T) {
+	err := traveline.StopNotCoveredError{NaptanCode: "0180BAC30249"}
+
+	expectedError := `Stop "0180BAC30249" is not covered by the producer`
+
+	if err.Error() != expectedError {
+		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
+	}
+}
+
+func TestLineNotCoveredError(t *testing.T) {
+	err := traveline.LineNotCoveredError{LineRef: "42"}
+
+	expectedError := `Line "42" is not covered by the producer`
+
+	if err.Error() != expectedError {
+		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
+	}
+}
+
+func TestSOAPFaultError(t *testing.T) {
+	err := traveline.SOAPFaultError{Code: "Sender", Subcode: "AccessNotAllowed", Reason: "Access not allowed"}
+
+	expectedError := `SOAP fault "Sender": Access not allowed`
+
+	if err.Error() != expectedError {
+		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
+	}
+}
+
+func TestLimitExceededError(t *testing.T) {
+	err := traveline.LimitExceededError{Limit: "stop visits", Max: 1000}
+
+	expectedError := "Response exceeds the stop visits limit of 1000"
+
+	if err.Error() != expectedError {
+		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
+	}
+}
+
+func TestInvalidIntervalError(t *testing.T) {
+	err := traveline.InvalidIntervalError{Interval: -time.Minute}
+
+	expectedError := "Invalid interval -1m0s, it must be positive"
+
+	if err.Error() != expectedError {
+		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
+	}
+}
+
+func TestInvalidDurationError(t *testing.T) {
+	err := traveline.InvalidDurationError{Duration: 0}
+
+	expectedError := "Invalid duration 0s, it must be positive"
+
+	if err.Error() != expectedError {
+		t.Fatalf("Expected error:\n%s\ngot:\n%s", expectedError, err.Error())
+	}
+}
@@ -0,0 +1,44 @@
+package traveline_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/conradhodge/travel-api-client/traveline"
+)
+
+func FuzzParseServiceDelivery(f *testing.F) {
+	deliveries, err := filepath.Glob("testdata/stop_monitoring*.xml")
+	if err != nil {
+		f.Fatalf("Unexpected error: %s", err.Error())
+	}
+	for _, delivery := range deliveries {
+		response, err := os.ReadFile(delivery)
+		if err != nil {
+			f.Fatalf("Unexpected error: %s", err.Error())
+		}
+		f.Add(string(response))
+	}
+
+	// Malformed and hostile deliveries
+	f.Add("")
+	f.Add("<Siri")
+	f.Add(`<Siri><ServiceDelivery></Siri>`)
+	f.Add(`<Siri><ServiceDelivery><StopMonitoringDelivery><MonitoredStopVisit>` +
+		`<MonitoredVehicleJourney><MonitoredCall><AimedDepartureTime>` +
+		`</MonitoredStopVisit></StopMonitoringDelivery></ServiceDelivery></Siri>`)
+	f.Add(`<!DOCTYPE Siri [<!ENTITY a "aaaaaaaaaa"><!ENTITY b "&a;&a;&a;&a;">]><Siri>&b;</Siri>`)
+	f.Add(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+		`<soap:Fault><faultcode>soap:Client</faultcode></soap:Fault></soap:Body></soap:Envelope>`)
+	f.Add(`<siri:Siri xmlns:siri="http://www.siri.org.uk/siri"><siri:ServiceDelivery/></siri:Siri>`)
+
+	client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein"}
+
+	f.Fuzz(func(t *testing.T, response string) {
+		journey, err := client.ParseServiceDelivery(response)
+		if err == nil && journey == nil {
+			t.Fatalf("Expected a journey or an error for %q", response)
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package traveline
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// defaultHistorySize is the number of probes kept when the health checker's history size is not set
+const defaultHistorySize = 60
+
+// Probe is the outcome of a check of the status of the producer
+type Probe struct {
+	Time      time.Time
+	Available bool
+	// Latency is how long the producer took to respond
+	Latency time.Duration
+	// Error is why the producer is not available
+	Error string
+}
+
+// HealthStatus is a summary of the recent probes of the producer
+type HealthStatus struct {
+	Healthy bool
+	// LastProbe is the most recent probe, the zero value if there has not been one
+	LastProbe Probe
+	// ServiceStartedTime is when the producer last started, as it reported it
+	ServiceStartedTime time.Time
+	// Availability is the fraction of the probes in the history that found the producer available
+	Availability float64
+	History      []Probe
+}
+
+// HealthChecker is used to probe the producer with Check Status requests, without spending
+// a Stop Monitoring request, keeping a history of the probes for readiness checks
+type HealthChecker struct {
+	API CheckStatusAPI
+	// Interval is the time between probes when running
+	Interval time.Duration
+	// HistorySize is the number of probes kept
+	HistorySize int
+
+	mu                 sync.Mutex
+	history            []Probe
+	serviceStartedTime time.Time
+}
+
+// NewHealthChecker returns the health checker that probes the producer at the interval
+func NewHealthChecker(api CheckStatusAPI, interval time.Duration) *HealthChecker {
+	return &HealthChecker{
+		API:         api,
+		Interval:    interval,
+		HistorySize: defaultHistorySize,
+	}
+}
+
+// Check probes the producer and records the outcome in the history
+func (h *HealthChecker) Check(when time.Time) Probe {
+	probe := Probe{Time: when}
+	started := time.Now()
+
+	status, err := h.checkStatus(when)
+	probe.Latency = time.Since(started)
+
+	var serviceStartedTime time.Time
+	switch {
+	case err != nil:
+		probe.Error = err.Error()
+	case !status.Status:
+		probe.Error = status.ErrorCondition.Description
+		if probe.Error == "" {
+			probe.Error = "producer status is false"
+		}
+	default:
+		probe.Available = true
+		if t, err := time.Parse(time.RFC3339, status.ServiceStartedTime); err == nil {
+			serviceStartedTime = t
+		}
+	}
+
+	h.record(probe, serviceStartedTime)
+
+	return probe
+}
+
+// checkStatus sends the Check Status request to the producer
+func (h *HealthChecker) checkStatus(when time.Time) (*ProducerStatus, error) {
+	request, err := h.API.BuildCheckStatusRequest(when)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := h.API.Send(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.API.ParseCheckStatusResponse(response)
+}
+
+// record adds the probe to the history, dropping the oldest once the history is full
+func (h *HealthChecker) record(probe Probe, serviceStartedTime time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !serviceStartedTime.IsZero() {
+		if !h.serviceStartedTime.IsZero() && !serviceStartedTime.Equal(h.serviceStartedTime) {
+			log.Printf("Producer restarted at %s", serviceStartedTime.Format(time.RFC3339))
+		}
+		h.serviceStartedTime = serviceStartedTime
+	}
+
+	size := h.HistorySize
+	if size <= 0 {
+		size = defaultHistorySize
+	}
+	h.history = append(h.history, probe)
+	if len(h.history) > size {
+		h.history = append([]Probe(nil), h.history[len(h.history)-size:]...)
+	}
+}
+
+// Run probes the producer straight away and then at each interval until the context is done.
+// An error is returned if the interval is not positive.
+func (h *HealthChecker) Run(ctx context.Context) error {
+	if h.Interval <= 0 {
+		return &InvalidIntervalError{Interval: h.Interval}
+	}
+
+	h.Check(time.Now())
+
+	ticker := time.NewTicker(h.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			h.Check(now)
+		}
+	}
+}
+
+// Healthy returns whether the producer was available when last probed, it is not healthy until probed
+// or once the last probe is stale
+func (h *HealthChecker) Healthy(when time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.healthy(when)
+}
+
+// healthy returns whether the last probe found the producer available and isn't stale. A probe is stale
+// once it's older than two intervals, as a probe should have been recorded since unless it is stuck
+// waiting for the producer.
+func (h *HealthChecker) healthy(when time.Time) bool {
+	if len(h.history) == 0 {
+		return false
+	}
+
+	lastProbe := h.history[len(h.history)-1]
+	if h.Interval > 0 && when.Sub(lastProbe.Time) > 2*h.Interval {
+		return false
+	}
+
+	return lastProbe.Available
+}
+
+// Status returns the summary of the recent probes at the time
+func (h *HealthChecker) Status(when time.Time) HealthStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status := HealthStatus{
+		ServiceStartedTime: h.serviceStartedTime,
+		History:            append([]Probe(nil), h.history...),
+	}
+	if len(h.history) == 0 {
+		return status
+	}
+
+	available := 0
+	for _, probe := range h.history {
+		if probe.Available {
+			available++
+		}
+	}
+	status.LastProbe = h.history[len(h.history)-1]
+	status.Healthy = h.healthy(when)
+	status.Availability = float64(available) / float64(len(h.history))
+
+	return status
+}
@@ -0,0 +1,114 @@
+package traveline_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/internal/siritest"
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHealthChecker(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	serviceStartedTime, _ := time.Parse(time.RFC3339, "2020-03-30T04:00:00+01:00")
+	available, err := os.ReadFile("testdata/check_status_response.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	unavailable := `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><CheckStatusResponse>` +
+		`<Status>false</Status><ErrorCondition><Description>Down for maintenance</Description></ErrorCondition>` +
+		`</CheckStatusResponse></Siri>`
+
+	server := siritest.NewProducer(t).
+		WithAuth("TravelineAPI999", "letmein").
+		RespondOnce("CheckStatusRequest", http.StatusOK, string(available)).
+		RespondOnce("CheckStatusRequest", http.StatusOK, unavailable).
+		RespondOnce("CheckStatusRequest", http.StatusInternalServerError, "").
+		RespondOnce("CheckStatusRequest", http.StatusOK, string(available))
+	defer server.Close()
+
+	client := &traveline.Client{
+		Username: "TravelineAPI999",
+		Password: "letmein",
+		URL:      server.URL,
+		Client:   server.Client(),
+	}
+	checker := traveline.NewHealthChecker(client, time.Minute)
+	checker.HistorySize = 3
+
+	if checker.Healthy(when) {
+		t.Fatalf("Expected not healthy before the first probe")
+	}
+
+	var probes []string
+	for i := 0; i < 4; i++ {
+		probe := checker.Check(when.Add(time.Duration(i) * time.Minute))
+		probes = append(probes, fmt.Sprintf("%t %s", probe.Available, probe.Error))
+	}
+
+	expectedProbes := []string{
+		"true ",
+		"false Down for maintenance",
+		"false error status from API: 500",
+		"true ",
+	}
+	if diff := cmp.Diff(expectedProbes, probes); diff != "" {
+		t.Errorf("Check() (-want +got):\n%s", diff)
+	}
+
+	lastProbeTime := when.Add(3 * time.Minute)
+	if !checker.Healthy(lastProbeTime) {
+		t.Errorf("Expected healthy after the last probe")
+	}
+	if checker.Healthy(lastProbeTime.Add(2*time.Minute + time.Second)) {
+		t.Errorf("Expected not healthy once the last probe is older than two intervals")
+	}
+
+	status := checker.Status(lastProbeTime.Add(time.Minute))
+	if !status.Healthy {
+		t.Errorf("Expected status to be healthy")
+	}
+	if len(status.History) != 3 || !status.History[0].Time.Equal(when.Add(time.Minute)) {
+		t.Errorf("Expected the last 3 probes in the history; got %+v", status.History)
+	}
+	if status.Availability != 1.0/3 {
+		t.Errorf("Expected availability 1/3; got %f", status.Availability)
+	}
+	if !status.ServiceStartedTime.Equal(serviceStartedTime) {
+		t.Errorf("Expected service started time %s; got %s", serviceStartedTime, status.ServiceStartedTime)
+	}
+	if !status.LastProbe.Time.Equal(lastProbeTime) {
+		t.Errorf("Expected last probe at %s; got %s", lastProbeTime, status.LastProbe.Time)
+	}
+	if status := checker.Status(lastProbeTime.Add(time.Hour)); status.Healthy {
+		t.Errorf("Expected status not to be healthy once the last probe is stale")
+	}
+}
+
+func TestHealthCheckerRunInvalidInterval(t *testing.T) {
+	server := siritest.NewProducer(t)
+	defer server.Close()
+
+	client := &traveline.Client{URL: server.URL, Client: server.Client()}
+
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		checker := traveline.NewHealthChecker(client, interval)
+
+		err := checker.Run(context.Background())
+
+		expectedError := fmt.Sprintf("Invalid interval %s, it must be positive", interval)
+		if err == nil || err.Error() != expectedError {
+			t.Errorf("Expected error '%s'; got '%v'", expectedError, err)
+		}
+	}
+
+	// The producer is not probed
+	if requests := server.Received(); len(requests) != 0 {
+		t.Errorf("Expected no requests; got %v", requests)
+	}
+}
@@ -0,0 +1,96 @@
+package traveline
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Default limits of the responses that are parsed
+const (
+	defaultMaxResponseSize = 10 << 20
+	defaultMaxDepth        = 64
+	defaultMaxStopVisits   = 1000
+)
+
+// Limits bounds the responses that are read and parsed, so that a hostile or broken producer can't exhaust
+// memory. The default is used for any limit that is not set.
+type Limits struct {
+	// MaxResponseSize is the largest response in bytes
+	MaxResponseSize int64
+	// MaxDepth is how deeply elements can be nested
+	MaxDepth int
+	// MaxStopVisits is the most MonitoredStopVisit elements in a delivery
+	MaxStopVisits int
+}
+
+// withDefaults returns the limits with the defaults for those not set
+func (l Limits) withDefaults() Limits {
+	if l.MaxResponseSize <= 0 {
+		l.MaxResponseSize = defaultMaxResponseSize
+	}
+	if l.MaxDepth <= 0 {
+		l.MaxDepth = defaultMaxDepth
+	}
+	if l.MaxStopVisits <= 0 {
+		l.MaxStopVisits = defaultMaxStopVisits
+	}
+
+	return l
+}
+
+// read returns the body, failing if it is larger than the maximum response size
+func (l Limits) read(body io.Reader) ([]byte, error) {
+	maxResponseSize := l.withDefaults().MaxResponseSize
+
+	response, err := io.ReadAll(io.LimitReader(body, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(response)) > maxResponseSize {
+		return nil, &LimitExceededError{Limit: "response size", Max: maxResponseSize}
+	}
+
+	return response, nil
+}
+
+// check scans the XML response before it is parsed, failing if it exceeds the limits
+// or has a DOCTYPE or entity declaration
+func (l Limits) check(response []byte) error {
+	limits := l.withDefaults()
+	if int64(len(response)) > limits.MaxResponseSize {
+		return &LimitExceededError{Limit: "response size", Max: limits.MaxResponseSize}
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(response))
+	depth, stopVisits := 0, 0
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth > limits.MaxDepth {
+				return &LimitExceededError{Limit: "element depth", Max: int64(limits.MaxDepth)}
+			}
+			if token.Name.Local == "MonitoredStopVisit" {
+				stopVisits++
+				if stopVisits > limits.MaxStopVisits {
+					return &LimitExceededError{Limit: "stop visits", Max: int64(limits.MaxStopVisits)}
+				}
+			}
+		case xml.EndElement:
+			depth--
+		case xml.Directive:
+			return errors.New("DOCTYPE and entity declarations are not allowed")
+		}
+	}
+}
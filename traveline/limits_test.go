@@ -0,0 +1,129 @@
+package traveline_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/conradhodge/travel-api-client/traveline"
+)
+
+func TestParseServiceDeliveryLimits(t *testing.T) {
+	visit := `<MonitoredStopVisit><MonitoredVehicleJourney><MonitoredCall>` +
+		`<AimedDepartureTime>2020-03-30T12:40:00+01:00</AimedDepartureTime>` +
+		`</MonitoredCall></MonitoredVehicleJourney></MonitoredStopVisit>`
+	delivery := func(visits string) string {
+		return `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><StopMonitoringDelivery>` +
+			visits + `</StopMonitoringDelivery></ServiceDelivery></Siri>`
+	}
+
+	tests := []struct {
+		name          string
+		limits        traveline.Limits
+		response      string
+		expectedError error
+	}{
+		{
+			name:     "Within the default limits",
+			response: delivery(strings.Repeat(visit, 3)),
+		},
+		{
+			name:          "Response too large",
+			limits:        traveline.Limits{MaxResponseSize: 100},
+			response:      delivery(visit),
+			expectedError: errors.New("Response exceeds the response size limit of 100"),
+		},
+		{
+			name:          "Elements too deep",
+			response:      delivery(strings.Repeat("<Extensions>", 100) + strings.Repeat("</Extensions>", 100)),
+			expectedError: errors.New("Response exceeds the element depth limit of 64"),
+		},
+		{
+			name:          "Elements deeper than the configured depth",
+			limits:        traveline.Limits{MaxDepth: 5},
+			response:      delivery(visit),
+			expectedError: errors.New("Response exceeds the element depth limit of 5"),
+		},
+		{
+			name:          "Too many stop visits",
+			limits:        traveline.Limits{MaxStopVisits: 2},
+			response:      delivery(strings.Repeat(visit, 3)),
+			expectedError: errors.New("Response exceeds the stop visits limit of 2"),
+		},
+		{
+			name: "DOCTYPE declaration",
+			response: `<?xml version="1.0"?><!DOCTYPE Siri [<!ENTITY lol "lol">]>` +
+				delivery(visit),
+			expectedError: errors.New("DOCTYPE and entity declarations are not allowed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein", Limits: test.limits}
+
+			_, err := client.ParseServiceDelivery(test.response)
+
+			if test.expectedError != nil {
+				if err == nil || err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+		})
+	}
+}
+
+func TestSendLimitsResponseSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 200)))
+	}))
+	defer server.Close()
+
+	expectedError := errors.New("Response exceeds the response size limit of 100")
+
+	client := &traveline.Client{URL: server.URL, Limits: traveline.Limits{MaxResponseSize: 100}, Client: server.Client()}
+	if _, err := client.Send("<Siri/>"); err == nil || err.Error() != expectedError.Error() {
+		t.Fatalf("Expected error '%s'; got '%s'", expectedError, err)
+	}
+
+	liteClient := &traveline.LiteClient{URL: server.URL, Limits: traveline.Limits{MaxResponseSize: 100}, Client: server.Client()}
+	if _, err := liteClient.Send("stop-monitoring.json"); err == nil || err.Error() != expectedError.Error() {
+		t.Fatalf("Expected error '%s'; got '%s'", expectedError, err)
+	}
+}
+
+func TestLiteParseLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      string
+		expectedError error
+	}{
+		{
+			name:          "Repeated key larger than the response size",
+			response:      `{"Siri":{"` + strings.Repeat("A", 1000) + `":[` + strings.Repeat("1,", 10000) + `1]}}`,
+			expectedError: errors.New("Response exceeds the response size limit of 100000"),
+		},
+		{
+			name:          "Values too deep",
+			response:      `{"Siri":` + strings.Repeat(`[`, 100) + strings.Repeat(`]`, 100) + `}`,
+			expectedError: errors.New("Response exceeds the element depth limit of 64"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &traveline.LiteClient{Limits: traveline.Limits{MaxResponseSize: 100000}}
+
+			_, err := client.ParseServiceDelivery(test.response)
+			if err == nil || err.Error() != test.expectedError.Error() {
+				t.Fatalf("Expected error '%s'; got '%s'", test.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,400 @@
+package traveline
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"log"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Siri-Lite endpoints of the services
+const (
+	liteStopMonitoring     = "stop-monitoring.json"
+	liteVehicleMonitoring  = "vehicle-monitoring.json"
+	liteSituationExchange  = "situation-exchange.json"
+	liteEstimatedTimetable = "estimated-timetable.json"
+	liteStopPoints         = "stoppoints-discovery.json"
+	liteLines              = "lines-discovery.json"
+)
+
+// LiteClient stores the details required to access a producer using Siri-Lite, where requests are
+// query strings sent with GET and deliveries are JSON. The deliveries are parsed into the same model
+// as the XML deliveries of Client, so either can be used for the services of the LiteAPI.
+// Subscriptions and Check Status are not part of Siri-Lite.
+type LiteClient struct {
+	Username string
+	Password string
+	// URL of the Siri-Lite endpoints, the request for each service is relative to it
+	URL string
+	// Limits bounds the responses that are read and parsed, the defaults are used if not set
+	Limits Limits
+	Client *http.Client
+}
+
+// NewLiteClient returns the client to access the Siri-Lite endpoints at the URL
+func NewLiteClient(username string, password string, url string, httpClient *http.Client) LiteAPI {
+	return &LiteClient{
+		Username: username,
+		Password: password,
+		URL:      url,
+		Client:   httpClient,
+	}
+}
+
+// BuildServiceRequest will return the Stop Monitoring request for the stop that the NaPTAN code represents,
+// the options can be used to change the window of departures returned
+func (c *LiteClient) BuildServiceRequest(
+	requestRef string,
+	naptanCode string,
+	when time.Time,
+	options RequestOptions,
+) (string, error) {
+	if err := options.validate(); err != nil {
+		return "", err
+	}
+
+	query := c.query(requestRef, when)
+	query.Set("MonitoringRef", naptanCode)
+	setParam(query, "PreviewInterval", formatDuration(options.PreviewInterval))
+	setParam(query, "StartTime", formatTime(options.StartTime))
+	setParam(query, "MaximumStopVisits", formatCount(options.MaximumStopVisits))
+	setParam(query, "MinimumStopVisitsPerLine", formatCount(options.MinimumStopVisitsPerLine))
+	setParam(query, "StopMonitoringDetailLevel", string(options.DetailLevel))
+
+	log.Printf("StopMonitoringRequest MessageIdentifier: %s, MonitoringRef: %s", requestRef, naptanCode)
+
+	return liteStopMonitoring + "?" + query.Encode(), nil
+}
+
+// ParseServiceDelivery will parse the JSON response and return the next departure
+func (c *LiteClient) ParseServiceDelivery(response string) (*MonitoredVehicleJourney, error) {
+	delivery, err := liteToXML(response, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.xmlClient().ParseServiceDelivery(delivery)
+}
+
+// ParseStopMonitoringDelivery will parse the JSON response and return every visit to the stop
+func (c *LiteClient) ParseStopMonitoringDelivery(requestRef string, response string) ([]MonitoredStopVisit, error) {
+	delivery, err := liteToXML(response, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.xmlClient().ParseStopMonitoringDelivery(requestRef, delivery)
+}
+
+// BuildVehicleMonitoringRequest will return the Vehicle Monitoring request for the vehicles that the filter selects
+func (c *LiteClient) BuildVehicleMonitoringRequest(requestRef string, filter VehicleFilter, when time.Time) (string, error) {
+	query := c.query(requestRef, when)
+	setParam(query, "LineRef", filter.LineRef)
+	setParam(query, "OperatorRef", filter.OperatorRef)
+
+	log.Printf("VehicleMonitoringRequest MessageIdentifier: %s", requestRef)
+
+	return liteVehicleMonitoring + "?" + query.Encode(), nil
+}
+
+// ParseVehicleMonitoringDelivery will parse the JSON response and return the vehicle activities
+func (c *LiteClient) ParseVehicleMonitoringDelivery(requestRef string, response string) ([]VehicleActivity, error) {
+	delivery, err := liteToXML(response, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.xmlClient().ParseVehicleMonitoringDelivery(requestRef, delivery)
+}
+
+// BuildSituationExchangeRequest will return the Situation Exchange request for the current situations
+func (c *LiteClient) BuildSituationExchangeRequest(requestRef string, when time.Time) (string, error) {
+	query := c.query(requestRef, when)
+
+	log.Printf("SituationExchangeRequest MessageIdentifier: %s", requestRef)
+
+	return liteSituationExchange + "?" + query.Encode(), nil
+}
+
+// ParseSituationExchangeDelivery will parse the JSON response and return the situations
+func (c *LiteClient) ParseSituationExchangeDelivery(requestRef string, response string) ([]PtSituationElement, error) {
+	delivery, err := liteToXML(response, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.xmlClient().ParseSituationExchangeDelivery(requestRef, delivery)
+}
+
+// BuildEstimatedTimetableRequest will return the Estimated Timetable request for the line,
+// all lines are requested if the line ref is empty
+func (c *LiteClient) BuildEstimatedTimetableRequest(requestRef string, lineRef string, when time.Time) (string, error) {
+	query := c.query(requestRef, when)
+	setParam(query, "LineRef", lineRef)
+
+	log.Printf("EstimatedTimetableRequest MessageIdentifier: %s", requestRef)
+
+	return liteEstimatedTimetable + "?" + query.Encode(), nil
+}
+
+// ParseEstimatedTimetableDelivery will parse the JSON response and return the estimated journeys
+func (c *LiteClient) ParseEstimatedTimetableDelivery(requestRef string, response string) ([]EstimatedVehicleJourney, error) {
+	delivery, err := liteToXML(response, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.xmlClient().ParseEstimatedTimetableDelivery(requestRef, delivery)
+}
+
+// BuildStopPointsRequest will return the request for the stops that the producer covers
+func (c *LiteClient) BuildStopPointsRequest(when time.Time) (string, error) {
+	return liteStopPoints + "?" + c.query("", when).Encode(), nil
+}
+
+// ParseStopPointsDelivery will parse the JSON response and return the stops the producer covers
+func (c *LiteClient) ParseStopPointsDelivery(response string) ([]AnnotatedStopPointRef, error) {
+	delivery, err := liteToXML(response, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.xmlClient().ParseStopPointsDelivery(delivery)
+}
+
+// BuildLinesRequest will return the request for the lines that the producer covers
+func (c *LiteClient) BuildLinesRequest(when time.Time) (string, error) {
+	return liteLines + "?" + c.query("", when).Encode(), nil
+}
+
+// ParseLinesDelivery will parse the JSON response and return the lines the producer covers
+func (c *LiteClient) ParseLinesDelivery(response string) ([]AnnotatedLineRef, error) {
+	delivery, err := liteToXML(response, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.xmlClient().ParseLinesDelivery(delivery)
+}
+
+// Send will send the request to the Siri-Lite endpoint of its service
+func (c *LiteClient) Send(request string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(c.URL, "/")+"/"+request, nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Accept", liteContentType)
+	if c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if berr := resp.Body.Close(); berr != nil {
+			err = berr
+		}
+	}()
+
+	body, err := c.Limits.read(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Error response from API: %v", resp)
+		return string(body), errors.Errorf("error status from API: %d", resp.StatusCode)
+	}
+
+	return string(body), nil
+}
+
+// query returns the parameters common to every request
+func (c *LiteClient) query(requestRef string, when time.Time) neturl.Values {
+	query := neturl.Values{}
+	query.Set("RequestTimestamp", when.Format(time.RFC3339))
+	setParam(query, "RequestorRef", c.Username)
+	setParam(query, "MessageIdentifier", requestRef)
+
+	return query
+}
+
+// xmlClient returns the client that parses the deliveries once they are converted to XML
+func (c *LiteClient) xmlClient() *Client {
+	return &Client{Username: c.Username, Limits: c.Limits}
+}
+
+// setParam sets the parameter of the query if it has a value
+func setParam(query neturl.Values, name string, value string) {
+	if value != "" {
+		query.Set(name, value)
+	}
+}
+
+// formatCount returns the count as a parameter, counts that are not set are empty
+func formatCount(count int) string {
+	if count <= 0 {
+		return ""
+	}
+
+	return strconv.Itoa(count)
+}
+
+// liteToXML converts a Siri-Lite JSON delivery to the Siri XML it represents. Each key is an element,
+// an array is a repeated element, and the value key of an object is the text of its element.
+// The depth and size of the XML are limited as it is written, as repeated keys can make it much larger.
+func liteToXML(response string, limits Limits) (string, error) {
+	limits = limits.withDefaults()
+	buffer := &limitedBuffer{max: limits.MaxResponseSize}
+	converter := &liteConverter{
+		decoder: json.NewDecoder(strings.NewReader(response)),
+		encoder: xml.NewEncoder(buffer),
+		limits:  limits,
+	}
+	converter.decoder.UseNumber()
+
+	token, err := converter.decoder.Token()
+	if err != nil {
+		return "", err
+	}
+	if token != json.Delim('{') {
+		return "", errors.New("Siri-Lite delivery is not a JSON object")
+	}
+	for converter.decoder.More() {
+		if err := converter.writeMember(); err != nil {
+			return "", err
+		}
+	}
+	if _, err := converter.decoder.Token(); err != nil {
+		return "", err
+	}
+
+	if err := converter.encoder.Flush(); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// liteConverter writes the tokens of a Siri-Lite JSON delivery as XML
+type liteConverter struct {
+	decoder *json.Decoder
+	encoder *xml.Encoder
+	limits  Limits
+	depth   int
+}
+
+// writeMember writes the next key of the JSON object and its value as XML
+func (c *liteConverter) writeMember() error {
+	token, err := c.decoder.Token()
+	if err != nil {
+		return err
+	}
+	key, ok := token.(string)
+	if !ok {
+		return errors.Errorf("invalid Siri-Lite key: %v", token)
+	}
+
+	if key == "value" {
+		return c.writeText()
+	}
+
+	return c.writeValue(key)
+}
+
+// writeValue writes the next JSON value as the XML element with the name
+func (c *liteConverter) writeValue(name string) error {
+	token, err := c.decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	c.depth++
+	defer func() { c.depth-- }()
+	if c.depth > c.limits.MaxDepth {
+		return &LimitExceededError{Limit: "element depth", Max: int64(c.limits.MaxDepth)}
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch token := token.(type) {
+	case json.Delim:
+		if token == '[' {
+			for c.decoder.More() {
+				if err := c.writeValue(name); err != nil {
+					return err
+				}
+			}
+			_, err := c.decoder.Token()
+			return err
+		}
+
+		if err := c.encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for c.decoder.More() {
+			if err := c.writeMember(); err != nil {
+				return err
+			}
+		}
+		if _, err := c.decoder.Token(); err != nil {
+			return err
+		}
+		return c.encoder.EncodeToken(start.End())
+	case nil:
+		return nil
+	default:
+		return c.encoder.EncodeElement(liteText(token), start)
+	}
+}
+
+// writeText writes the next JSON value as the text of the current XML element
+func (c *liteConverter) writeText() error {
+	token, err := c.decoder.Token()
+	if err != nil {
+		return err
+	}
+	if _, ok := token.(json.Delim); ok {
+		return errors.New("invalid Siri-Lite value")
+	}
+
+	return c.encoder.EncodeToken(xml.CharData(liteText(token)))
+}
+
+// liteText returns the JSON string, number or boolean as text
+func liteText(token json.Token) string {
+	switch token := token.(type) {
+	case string:
+		return token
+	case json.Number:
+		return token.String()
+	case bool:
+		return strconv.FormatBool(token)
+	}
+
+	return ""
+}
+
+// limitedBuffer is a buffer that fails writes that would make it larger than the maximum
+type limitedBuffer struct {
+	bytes.Buffer
+	max int64
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if int64(b.Len()+len(p)) > b.max {
+		return 0, &LimitExceededError{Limit: "response size", Max: b.max}
+	}
+
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,174 @@
+package traveline_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLiteBuildRequests(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	startTime, _ := time.Parse(time.RFC3339, "2020-03-30T13:00:00+01:00")
+	client := traveline.NewLiteClient("TravelineAPI999", "letmein", "https://example.com/siri", &http.Client{})
+
+	tests := []struct {
+		name            string
+		build           func() (string, error)
+		expectedRequest string
+		expectedError   error
+	}{
+		{
+			name: "Stop monitoring",
+			build: func() (string, error) {
+				return client.BuildServiceRequest("REQ-1", "020035811", when, traveline.RequestOptions{})
+			},
+			expectedRequest: "stop-monitoring.json?MessageIdentifier=REQ-1&MonitoringRef=020035811" +
+				"&RequestTimestamp=2020-03-30T12%3A34%3A56%2B01%3A00&RequestorRef=TravelineAPI999",
+		},
+		{
+			name: "Stop monitoring with options",
+			build: func() (string, error) {
+				return client.BuildServiceRequest("REQ-1", "020035811", when, traveline.RequestOptions{
+					PreviewInterval:   90 * time.Minute,
+					StartTime:         startTime,
+					MaximumStopVisits: 10,
+					DetailLevel:       traveline.CallsDetail,
+				})
+			},
+			expectedRequest: "stop-monitoring.json?MaximumStopVisits=10&MessageIdentifier=REQ-1&MonitoringRef=020035811" +
+				"&PreviewInterval=PT1H30M&RequestTimestamp=2020-03-30T12%3A34%3A56%2B01%3A00&RequestorRef=TravelineAPI999" +
+				"&StartTime=2020-03-30T13%3A00%3A00%2B01%3A00&StopMonitoringDetailLevel=calls",
+		},
+		{
+			name: "Invalid stop monitoring options",
+			build: func() (string, error) {
+				return client.BuildServiceRequest("REQ-1", "020035811", when, traveline.RequestOptions{MaximumStopVisits: -1})
+			},
+			expectedError: errors.New("invalid maximum stop visits: -1"),
+		},
+		{
+			name: "Vehicle monitoring",
+			build: func() (string, error) {
+				return client.BuildVehicleMonitoringRequest("REQ-2", traveline.VehicleFilter{LineRef: "42"}, when)
+			},
+			expectedRequest: "vehicle-monitoring.json?LineRef=42&MessageIdentifier=REQ-2" +
+				"&RequestTimestamp=2020-03-30T12%3A34%3A56%2B01%3A00&RequestorRef=TravelineAPI999",
+		},
+		{
+			name: "Lines discovery",
+			build: func() (string, error) {
+				return client.BuildLinesRequest(when)
+			},
+			expectedRequest: "lines-discovery.json?RequestTimestamp=2020-03-30T12%3A34%3A56%2B01%3A00&RequestorRef=TravelineAPI999",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request, err := test.build()
+
+			if test.expectedError != nil {
+				if err == nil || err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if request != test.expectedRequest {
+				t.Errorf("Expected request %s; got %s", test.expectedRequest, request)
+			}
+		})
+	}
+}
+
+func TestLiteParseStopMonitoringDelivery(t *testing.T) {
+	response, err := os.ReadFile("testdata/stop_monitoring_lite.json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	client := traveline.NewLiteClient("TravelineAPI999", "letmein", "https://example.com/siri", &http.Client{})
+
+	visits, err := client.ParseStopMonitoringDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", string(response))
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+	if len(visits) != 3 {
+		t.Fatalf("Expected 3 visits; got %d", len(visits))
+	}
+
+	expected := traveline.MonitoredVehicleJourney{
+		VehicleMode:       "bus",
+		PublishedLineName: "1",
+		DirectionName:     "Luton",
+		Monitored:         "true",
+		MonitoredCall: traveline.MonitoredCall{
+			AimedDepartureTime:    "2020-03-30T12:55:00+01:00",
+			ExpectedDepartureTime: "2020-03-30T13:01:00+01:00",
+			DepartureStatus:       "delayed",
+		},
+		OnwardCalls: []traveline.OnwardCall{
+			{StopPointRef: "020035899", VisitNumber: 12, AimedArrivalTime: "2020-03-30T13:10:00+01:00"},
+		},
+	}
+	expected.FramedVehicleJourneyRef.DataFrameRef = "2020-03-30"
+	expected.FramedVehicleJourneyRef.DatedVehicleJourneyRef = "3001"
+	if diff := cmp.Diff(expected, visits[2].MonitoredVehicleJourney); diff != "" {
+		t.Errorf("MonitoredVehicleJourney (-want +got):\n%s", diff)
+	}
+
+	journey, err := client.ParseServiceDelivery(string(response))
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+	if journey.PublishedLineName != "42" || journey.Monitored != "true" {
+		t.Errorf("Journey not as expected: %s %s", journey.PublishedLineName, journey.Monitored)
+	}
+
+	for _, invalid := range []string{"", "[]", `{"Siri":`, `{"Siri":{"value":{}}}`} {
+		if _, err := client.ParseServiceDelivery(invalid); err == nil {
+			t.Errorf("Expected error for invalid JSON %s; got no error", invalid)
+		}
+	}
+}
+
+func TestLiteSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected GET; got %s", r.Method)
+		}
+		if r.URL.Path != "/siri/stop-monitoring.json" || r.URL.Query().Get("MonitoringRef") != "020035811" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if username, password, _ := r.BasicAuth(); username != "TravelineAPI999" || password != "letmein" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-type", "application/json")
+		_, _ = w.Write([]byte(`{"Siri":{}}`))
+	}))
+	defer server.Close()
+
+	client := traveline.NewLiteClient("TravelineAPI999", "letmein", server.URL+"/siri/", server.Client())
+
+	response, err := client.Send("stop-monitoring.json?MonitoringRef=020035811")
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+	if response != `{"Siri":{}}` {
+		t.Errorf("Unexpected response: %s", response)
+	}
+
+	expectedError := errors.New("error status from API: 404")
+	if _, err := client.Send("vehicle-monitoring.json"); err == nil || err.Error() != expectedError.Error() {
+		t.Fatalf("Expected error '%s'; got '%s'", expectedError, err)
+	}
+}
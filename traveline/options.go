@@ -0,0 +1,133 @@
+package traveline
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var durationPattern = regexp.MustCompile(`^(-?)P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// RequestOptions represents the optional parameters of a Stop Monitoring request,
+// the zero value requests the producer defaults
+type RequestOptions struct {
+	// PreviewInterval limits the departures to those within the interval after the start time
+	PreviewInterval time.Duration
+	// StartTime is the time from which departures are returned, the request time is used when not set
+	StartTime time.Time
+	// MaximumStopVisits limits the number of departures returned
+	MaximumStopVisits int
+	// MinimumStopVisitsPerLine is the minimum number of departures returned for each line,
+	// even if that exceeds MaximumStopVisits
+	MinimumStopVisitsPerLine int
+	// DetailLevel is how much of each journey is returned, e.g. CallsDetail for the onward and previous calls
+	DetailLevel DetailLevel
+}
+
+// DetailLevel is the Siri Stop Monitoring detail level
+type DetailLevel string
+
+// Stop Monitoring detail levels, the producer default is normal
+const (
+	MinimumDetail DetailLevel = "minimum"
+	BasicDetail   DetailLevel = "basic"
+	NormalDetail  DetailLevel = "normal"
+	CallsDetail   DetailLevel = "calls"
+	FullDetail    DetailLevel = "full"
+)
+
+// validate checks that the options can be sent in a request
+func (o RequestOptions) validate() error {
+	if o.PreviewInterval < 0 {
+		return errors.Errorf("invalid preview interval: %s", o.PreviewInterval)
+	}
+	if o.MaximumStopVisits < 0 {
+		return errors.Errorf("invalid maximum stop visits: %d", o.MaximumStopVisits)
+	}
+	if o.MinimumStopVisitsPerLine < 0 {
+		return errors.Errorf("invalid minimum stop visits per line: %d", o.MinimumStopVisitsPerLine)
+	}
+	switch o.DetailLevel {
+	case "", MinimumDetail, BasicDetail, NormalDetail, CallsDetail, FullDetail:
+	default:
+		return errors.Errorf("invalid detail level: %s", o.DetailLevel)
+	}
+
+	return nil
+}
+
+// formatDuration returns the duration as an XML Schema duration, e.g. PT1H30M
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+
+	d = d.Round(time.Second)
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	seconds := d / time.Second
+
+	var b strings.Builder
+	b.WriteString("PT")
+	if hours > 0 {
+		fmt.Fprintf(&b, "%dH", hours)
+	}
+	if minutes > 0 {
+		fmt.Fprintf(&b, "%dM", minutes)
+	}
+	if seconds > 0 || (hours == 0 && minutes == 0) {
+		fmt.Fprintf(&b, "%dS", seconds)
+	}
+
+	return b.String()
+}
+
+// formatTime returns the time in the format used in requests, or an empty string if not set
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// ParseDuration returns the XML Schema duration, e.g. PT1H30M or -PT45S, as used for delays and run times
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	matches := durationPattern.FindStringSubmatch(value)
+	if matches == nil || strings.HasSuffix(value, "P") || strings.HasSuffix(value, "T") {
+		return 0, errors.Errorf("invalid duration: %q", value)
+	}
+
+	var d time.Duration
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute}
+	for i, unit := range units {
+		if matches[i+2] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(matches[i+2], 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("invalid duration: %q", value)
+		}
+		d += time.Duration(n) * unit
+	}
+	if matches[5] != "" {
+		seconds, err := strconv.ParseFloat(matches[5], 64)
+		if err != nil {
+			return 0, errors.Errorf("invalid duration: %q", value)
+		}
+		d += time.Duration(seconds * float64(time.Second))
+	}
+
+	if matches[1] == "-" {
+		d = -d
+	}
+
+	return d, nil
+}
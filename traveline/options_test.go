@@ -0,0 +1,51 @@
+package traveline_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/traveline"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value            string
+		expectedDuration time.Duration
+		expectedError    error
+	}{
+		{value: "PT0S", expectedDuration: 0},
+		{value: "PT1H30M", expectedDuration: 90 * time.Minute},
+		{value: "PT2M30S", expectedDuration: 150 * time.Second},
+		{value: "-PT45S", expectedDuration: -45 * time.Second},
+		{value: "PT1.5S", expectedDuration: 1500 * time.Millisecond},
+		{value: "P1DT1H", expectedDuration: 25 * time.Hour},
+		{value: "", expectedError: errors.New(`invalid duration: ""`)},
+		{value: "P", expectedError: errors.New(`invalid duration: "P"`)},
+		{value: "PT", expectedError: errors.New(`invalid duration: "PT"`)},
+		{value: "5 minutes", expectedError: errors.New(`invalid duration: "5 minutes"`)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			duration, err := traveline.ParseDuration(test.value)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error; got '%s'", err)
+				}
+			}
+
+			if duration != test.expectedDuration {
+				t.Fatalf("Expected duration '%s'; got '%s'", test.expectedDuration, duration)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package traveline
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// newRequest returns the envelope of a request in the Siri version of the client,
+// the request is added to it before it is marshalled
+func (c *Client) newRequest() (*Siri, error) {
+	version, xmlns, err := c.siri()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Siri{Version: version, XMLNS: xmlns}, nil
+}
+
+// requestor returns the requestor of the requests made by the client at the time
+func (c *Client) requestor(when time.Time) Requestor {
+	return Requestor{
+		RequestTimestamp: when.Format(time.RFC3339),
+		RequestorRef:     c.Username,
+	}
+}
+
+// NewServiceRequest returns the envelope of a Service Request made by the client at the time,
+// the functional requests for each service are added to the Service Request before it is marshalled
+func (c *Client) NewServiceRequest(when time.Time) (*Siri, error) {
+	request, err := c.newRequest()
+	if err != nil {
+		return nil, err
+	}
+	request.ServiceRequest = &ServiceRequest{Requestor: c.requestor(when)}
+
+	return request, nil
+}
+
+// NewFunctionalRequest returns the elements shared by the functional requests made at the time
+func NewFunctionalRequest(messageIdentifier string, when time.Time) FunctionalRequest {
+	return FunctionalRequest{
+		RequestTimestamp:  when.Format(time.RFC3339),
+		MessageIdentifier: messageIdentifier,
+	}
+}
+
+// Marshal returns the XML of the request, each functional request of a Siri 2.0 Service Request
+// states its version, Siri 1.0 functional requests have the version of the envelope.
+// The request itself isn't changed.
+func (s *Siri) Marshal() (string, error) {
+	request := *s
+	if request.ServiceRequest != nil && request.Version == SiriVersion2 {
+		request.ServiceRequest = request.ServiceRequest.withVersion(request.Version)
+	}
+
+	requestBody, err := xml.Marshal(&request)
+	if err != nil {
+		return "", err
+	}
+
+	return string(requestBody), nil
+}
+
+// withVersion returns a copy of the Service Request with the version set on every functional request
+func (r *ServiceRequest) withVersion(version string) *ServiceRequest {
+	versioned := *r
+	versioned.StopMonitoringRequest = append([]StopMonitoringRequest(nil), r.StopMonitoringRequest...)
+	versioned.VehicleMonitoringRequest = append([]VehicleMonitoringRequest(nil), r.VehicleMonitoringRequest...)
+	versioned.SituationExchangeRequest = append([]SituationExchangeRequest(nil), r.SituationExchangeRequest...)
+	versioned.EstimatedTimetableRequest = append([]EstimatedTimetableRequest(nil), r.EstimatedTimetableRequest...)
+
+	for _, functionalRequest := range versioned.functionalRequests() {
+		functionalRequest.Version = version
+	}
+
+	return &versioned
+}
+
+// functionalRequests returns the elements shared by every functional request in the Service Request
+func (r *ServiceRequest) functionalRequests() []*FunctionalRequest {
+	var functionalRequests []*FunctionalRequest
+	for i := range r.StopMonitoringRequest {
+		functionalRequests = append(functionalRequests, &r.StopMonitoringRequest[i].FunctionalRequest)
+	}
+	for i := range r.VehicleMonitoringRequest {
+		functionalRequests = append(functionalRequests, &r.VehicleMonitoringRequest[i].FunctionalRequest)
+	}
+	for i := range r.SituationExchangeRequest {
+		functionalRequests = append(functionalRequests, &r.SituationExchangeRequest[i].FunctionalRequest)
+	}
+	for i := range r.EstimatedTimetableRequest {
+		functionalRequests = append(functionalRequests, &r.EstimatedTimetableRequest[i].FunctionalRequest)
+	}
+
+	return functionalRequests
+}
+
+// answers returns whether the delivery answers the request with the message identifier,
+// a delivery without a RequestMessageRef answers any request as not every producer returns it
+func (d FunctionalDelivery) answers(requestRef string) bool {
+	return d.RequestMessageRef == "" || d.RequestMessageRef == requestRef
+}
@@ -0,0 +1,117 @@
+package traveline_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/conradhodge/travel-api-client/traveline"
+)
+
+func TestNewServiceRequest(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+
+	tests := []struct {
+		name            string
+		version         string
+		compose         func(request *traveline.ServiceRequest)
+		expectedRequest string
+		expectedError   error
+	}{
+		{
+			name: "Stop and vehicle monitoring",
+			compose: func(request *traveline.ServiceRequest) {
+				request.StopMonitoringRequest = append(request.StopMonitoringRequest, traveline.StopMonitoringRequest{
+					FunctionalRequest: traveline.NewFunctionalRequest("REQ-1", when),
+					MonitoringRef:     "020035811",
+					MaximumStopVisits: 5,
+				})
+				request.VehicleMonitoringRequest = append(request.VehicleMonitoringRequest, traveline.VehicleMonitoringRequest{
+					FunctionalRequest: traveline.NewFunctionalRequest("REQ-2", when),
+					LineRef:           "42",
+				})
+			},
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<StopMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>REQ-1</MessageIdentifier><MonitoringRef>020035811</MonitoringRef>` +
+				`<MaximumStopVisits>5</MaximumStopVisits></StopMonitoringRequest>` +
+				`<VehicleMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>REQ-2</MessageIdentifier><LineRef>42</LineRef></VehicleMonitoringRequest>` +
+				`</ServiceRequest></Siri>`,
+		},
+		{
+			name:    "Situations and estimated timetable with Siri 2.0",
+			version: traveline.SiriVersion2,
+			compose: func(request *traveline.ServiceRequest) {
+				request.SituationExchangeRequest = append(request.SituationExchangeRequest, traveline.SituationExchangeRequest{
+					FunctionalRequest: traveline.NewFunctionalRequest("REQ-3", when),
+				})
+				request.EstimatedTimetableRequest = append(request.EstimatedTimetableRequest, traveline.EstimatedTimetableRequest{
+					FunctionalRequest: traveline.NewFunctionalRequest("REQ-4", when),
+				})
+			},
+			expectedRequest: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<SituationExchangeRequest version="2.0"><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>REQ-3</MessageIdentifier></SituationExchangeRequest>` +
+				`<EstimatedTimetableRequest version="2.0"><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>REQ-4</MessageIdentifier></EstimatedTimetableRequest>` +
+				`</ServiceRequest></Siri>`,
+		},
+		{
+			name:          "Unsupported version",
+			version:       "1.3",
+			expectedError: errors.New("unsupported Siri version: 1.3"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein", Version: test.version}
+
+			serviceRequest, err := client.NewServiceRequest(when)
+
+			if test.expectedError != nil {
+				if err == nil || err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			test.compose(serviceRequest.ServiceRequest)
+			request, err := serviceRequest.Marshal()
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if request != test.expectedRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedRequest, request))
+			}
+		})
+	}
+}
+
+func TestMarshalLeavesRequestUnchanged(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein", Version: traveline.SiriVersion2}
+
+	request, err := client.NewServiceRequest(when)
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+	request.ServiceRequest.StopMonitoringRequest = append(request.ServiceRequest.StopMonitoringRequest, traveline.StopMonitoringRequest{
+		FunctionalRequest: traveline.NewFunctionalRequest("REQ-1", when),
+		MonitoringRef:     "020035811",
+	})
+
+	if _, err := request.Marshal(); err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+	if version := request.ServiceRequest.StopMonitoringRequest[0].Version; version != "" {
+		t.Fatalf("Expected the version of the functional request to be left unset; got '%s'", version)
+	}
+}
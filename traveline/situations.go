@@ -0,0 +1,45 @@
+package traveline
+
+import (
+	"log"
+	"time"
+)
+
+// BuildSituationExchangeRequest will return the XML for the request for the current situations
+func (c *Client) BuildSituationExchangeRequest(requestRef string, when time.Time) (string, error) {
+	request, err := c.NewServiceRequest(when)
+	if err != nil {
+		return "", err
+	}
+
+	situationExchangeRequest := SituationExchangeRequest{
+		FunctionalRequest: NewFunctionalRequest(requestRef, when),
+	}
+	serviceRequest := request.ServiceRequest
+	serviceRequest.SituationExchangeRequest = append(serviceRequest.SituationExchangeRequest, situationExchangeRequest)
+
+	log.Printf("SituationExchangeRequestMessageIdentifier: %s", situationExchangeRequest.MessageIdentifier)
+
+	return request.Marshal()
+}
+
+// ParseSituationExchangeDelivery will parse the response from the Traveline API and return the situations
+// in the deliveries for the request
+func (c *Client) ParseSituationExchangeDelivery(requestRef string, response string) ([]PtSituationElement, error) {
+	serviceDelivery := ServiceDelivery{}
+	err := unmarshal([]byte(response), &serviceDelivery, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	var situations []PtSituationElement
+	for _, delivery := range serviceDelivery.ServiceDelivery.SituationExchangeDelivery {
+		if !delivery.answers(requestRef) {
+			continue
+		}
+		log.Printf("RequestMessageRef: %s, Situations: %d", delivery.RequestMessageRef, len(delivery.Situations))
+		situations = append(situations, delivery.Situations...)
+	}
+
+	return situations, nil
+}
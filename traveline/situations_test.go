@@ -0,0 +1,180 @@
+package traveline_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/conradhodge/travel-api-client/traveline"
+)
+
+func TestBuildSituationExchangeRequest(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+	request, err := client.BuildSituationExchangeRequest("ab7c1e9b-d06f-44cc-b190-4d36fb564386", when)
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	expectedRequest := `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+		`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+		`<SituationExchangeRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+		`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+		`</SituationExchangeRequest></ServiceRequest></Siri>`
+
+	if request != expectedRequest {
+		t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(expectedRequest, request))
+	}
+}
+
+func TestParseSituationExchangeDelivery(t *testing.T) {
+	response, err := os.ReadFile("testdata/situation_exchange.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tests := []struct {
+		name               string
+		response           string
+		expectedSituations []string
+		expectedError      error
+	}{
+		{
+			name:               "Situations",
+			response:           string(response),
+			expectedSituations: []string{"SIT-1", "SIT-2", "SIT-3", "SIT-4", "SIT-5"},
+		},
+		{
+			name:     "No situations",
+			response: `<Siri><ServiceDelivery><SituationExchangeDelivery></SituationExchangeDelivery></ServiceDelivery></Siri>`,
+		},
+		{
+			name:          "Invalid XML",
+			response:      `<Siri>`,
+			expectedError: errors.New("XML syntax error on line 1: unexpected EOF"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+			situations, err := client.ParseSituationExchangeDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", test.response)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			if len(situations) != len(test.expectedSituations) {
+				t.Fatalf("Expected %d situations; got %d", len(test.expectedSituations), len(situations))
+			}
+			for i, situation := range situations {
+				if situation.SituationNumber != test.expectedSituations[i] {
+					t.Fatalf("Expected situation '%s'; got '%s'", test.expectedSituations[i], situation.SituationNumber)
+				}
+			}
+		})
+	}
+
+	// Check the fields of the first situation are parsed
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+	situations, _ := client.ParseSituationExchangeDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", string(response))
+	situation := situations[0]
+	if situation.Severity != "severe" || situation.Summary != "Route 42 diverted" {
+		t.Fatalf("Unexpected situation: %+v", situation)
+	}
+	if len(situation.ValidityPeriod) != 1 || situation.ValidityPeriod[0].EndTime != "2020-04-03T19:00:00+01:00" {
+		t.Fatalf("Unexpected validity period: %+v", situation.ValidityPeriod)
+	}
+	if len(situation.Affects.Lines) != 1 || situation.Affects.Lines[0].PublishedLineName != "42" {
+		t.Fatalf("Unexpected affected lines: %+v", situation.Affects.Lines)
+	}
+	if len(situation.Affects.StopPoints) != 1 || situation.Affects.StopPoints[0].StopPointRef != "020035811" {
+		t.Fatalf("Unexpected affected stops: %+v", situation.Affects.StopPoints)
+	}
+}
+
+func TestSituationExchangeVersions(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+
+	tests := []struct {
+		name            string
+		version         string
+		expectedRequest string
+		response        string
+	}{
+		{
+			name:    "Siri 1.0",
+			version: traveline.SiriVersion1,
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<SituationExchangeRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`</SituationExchangeRequest></ServiceRequest></Siri>`,
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><SituationExchangeDelivery>` +
+				`<Situations><PtSituationElement><SituationNumber>SIT-1</SituationNumber><Summary>Route 42 diverted</Summary>` +
+				`<Affects><Networks><AffectedNetwork><AffectedLine><LineRef>42</LineRef></AffectedLine></AffectedNetwork></Networks>` +
+				`<StopPoints><AffectedStopPoint><StopPointRef>020035811</StopPointRef></AffectedStopPoint></StopPoints></Affects>` +
+				`</PtSituationElement></Situations></SituationExchangeDelivery></ServiceDelivery></Siri>`,
+		},
+		{
+			name:    "Siri 2.0",
+			version: traveline.SiriVersion2,
+			expectedRequest: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<SituationExchangeRequest version="2.0"><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`</SituationExchangeRequest></ServiceRequest></Siri>`,
+			response: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><ServiceDelivery><SituationExchangeDelivery version="2.0">` +
+				`<Situations><PtSituationElement><SituationNumber>SIT-1</SituationNumber><Summary>Route 42 diverted</Summary>` +
+				`<Affects><Networks><AffectedNetwork><AffectedLine><LineRef>42</LineRef></AffectedLine></AffectedNetwork></Networks>` +
+				`<StopPoints><AffectedStopPoint><StopPointRef>020035811</StopPointRef></AffectedStopPoint></StopPoints></Affects>` +
+				`</PtSituationElement></Situations></SituationExchangeDelivery></ServiceDelivery></Siri>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein", Version: test.version}
+
+			request, err := client.BuildSituationExchangeRequest("ab7c1e9b-d06f-44cc-b190-4d36fb564386", when)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if request != test.expectedRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedRequest, request))
+			}
+
+			situations, err := client.ParseSituationExchangeDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", test.response)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if len(situations) != 1 {
+				t.Fatalf("Expected 1 situation; got %d", len(situations))
+			}
+			situation := situations[0]
+			if situation.SituationNumber != "SIT-1" || situation.Summary != "Route 42 diverted" {
+				t.Fatalf("Unexpected situation: %+v", situation)
+			}
+			if len(situation.Affects.Lines) != 1 || situation.Affects.Lines[0].LineRef != "42" {
+				t.Fatalf("Unexpected affected lines: %+v", situation.Affects.Lines)
+			}
+			if len(situation.Affects.StopPoints) != 1 || situation.Affects.StopPoints[0].StopPointRef != "020035811" {
+				t.Fatalf("Unexpected affected stops: %+v", situation.Affects.StopPoints)
+			}
+		})
+	}
+}
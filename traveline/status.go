@@ -0,0 +1,40 @@
+package traveline
+
+import (
+	"log"
+	"time"
+)
+
+// BuildCheckStatusRequest will return the XML for the request for the status of the producer
+func (c *Client) BuildCheckStatusRequest(when time.Time) (string, error) {
+	request, err := c.newRequest()
+	if err != nil {
+		return "", err
+	}
+
+	checkStatusRequest := c.requestor(when)
+	request.CheckStatusRequest = &checkStatusRequest
+
+	log.Printf("CheckStatusRequest RequestTimestamp: %s", checkStatusRequest.RequestTimestamp)
+
+	return request.Marshal()
+}
+
+// ParseCheckStatusResponse will parse the response from the producer and return its status
+func (c *Client) ParseCheckStatusResponse(response string) (*ProducerStatus, error) {
+	checkStatusResponse := CheckStatusResponse{}
+	err := unmarshal([]byte(response), &checkStatusResponse, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	status := checkStatusResponse.CheckStatusResponse
+	log.Printf(
+		"ProducerRef: %s, Status: %t, ServiceStartedTime: %s",
+		status.ProducerRef,
+		status.Status,
+		status.ServiceStartedTime,
+	)
+
+	return &status, nil
+}
@@ -0,0 +1,146 @@
+package traveline_test
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/conradhodge/travel-api-client/traveline"
+)
+
+func TestBuildCheckStatusRequest(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+	request, err := client.BuildCheckStatusRequest(when)
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	expectedRequest := `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><CheckStatusRequest>` +
+		`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+		`</CheckStatusRequest></Siri>`
+	if request != expectedRequest {
+		t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(expectedRequest, request))
+	}
+}
+
+func TestParseCheckStatusResponse(t *testing.T) {
+	response, err := os.ReadFile("testdata/check_status_response.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tests := []struct {
+		name                string
+		response            string
+		expectedStatus      bool
+		expectedStartedTime string
+		expectedDescription string
+		expectError         bool
+	}{
+		{
+			name:                "Available",
+			response:            string(response),
+			expectedStatus:      true,
+			expectedStartedTime: "2020-03-30T04:00:00+01:00",
+		},
+		{
+			name: "Unavailable",
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><CheckStatusResponse>` +
+				`<Status>false</Status><ErrorCondition><ServiceNotAvailableError/>` +
+				`<Description>Down for maintenance</Description></ErrorCondition></CheckStatusResponse></Siri>`,
+			expectedDescription: "Down for maintenance",
+		},
+		{
+			name:        "Invalid XML",
+			response:    "<Siri",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+			status, err := client.ParseCheckStatusResponse(test.response)
+
+			if test.expectError {
+				if err == nil {
+					t.Fatalf("Expected error; got no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			if status.Status != test.expectedStatus {
+				t.Errorf("Expected status %t; got %t", test.expectedStatus, status.Status)
+			}
+			if status.ServiceStartedTime != test.expectedStartedTime {
+				t.Errorf("Expected service started time %s; got %s", test.expectedStartedTime, status.ServiceStartedTime)
+			}
+			if status.ErrorCondition.Description != test.expectedDescription {
+				t.Errorf("Expected description %s; got %s", test.expectedDescription, status.ErrorCondition.Description)
+			}
+		})
+	}
+}
+
+func TestCheckStatusVersions(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+
+	tests := []struct {
+		name            string
+		version         string
+		expectedRequest string
+		response        string
+	}{
+		{
+			name:    "Siri 1.0",
+			version: traveline.SiriVersion1,
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><CheckStatusRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`</CheckStatusRequest></Siri>`,
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><CheckStatusResponse>` +
+				`<ProducerRef>NextBuses</ProducerRef><Status>true</Status>` +
+				`<ServiceStartedTime>2020-03-30T04:00:00+01:00</ServiceStartedTime></CheckStatusResponse></Siri>`,
+		},
+		{
+			name:    "Siri 2.0",
+			version: traveline.SiriVersion2,
+			expectedRequest: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><CheckStatusRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`</CheckStatusRequest></Siri>`,
+			response: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><CheckStatusResponse version="2.0">` +
+				`<ProducerRef>NextBuses</ProducerRef><Status>true</Status>` +
+				`<ServiceStartedTime>2020-03-30T04:00:00+01:00</ServiceStartedTime></CheckStatusResponse></Siri>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein", Version: test.version}
+
+			request, err := client.BuildCheckStatusRequest(when)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if request != test.expectedRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedRequest, request))
+			}
+
+			status, err := client.ParseCheckStatusResponse(test.response)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if !status.Status || status.ProducerRef != "NextBuses" || status.ServiceStartedTime != "2020-03-30T04:00:00+01:00" {
+				t.Fatalf("Unexpected status: %+v", status)
+			}
+		})
+	}
+}
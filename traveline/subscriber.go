@@ -0,0 +1,219 @@
+package traveline
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Subscriber is used to make subscriptions to a producer that pushes deliveries to a consumer address,
+// keeping track of the subscriptions so they can be renewed before they end and terminated
+type Subscriber struct {
+	API SubscriptionAPI
+	// ConsumerAddress is the URL that the producer pushes deliveries to, served by a Consumer
+	ConsumerAddress string
+	// Duration is how long subscriptions are requested for before they need to be renewed
+	Duration time.Duration
+
+	mu            sync.Mutex
+	subscriptions map[string]activeSubscription
+}
+
+// activeSubscription is a subscription accepted by the producer and the time it ends
+type activeSubscription struct {
+	subscription Subscription
+	validUntil   time.Time
+}
+
+// NewSubscriber returns the subscriber that requests subscriptions lasting the duration
+func NewSubscriber(api SubscriptionAPI, consumerAddress string, duration time.Duration) *Subscriber {
+	return &Subscriber{
+		API:             api,
+		ConsumerAddress: consumerAddress,
+		Duration:        duration,
+	}
+}
+
+// Subscribe requests the subscription from the producer and returns the time it ends.
+// Subscribing with the ref of an existing subscription replaces it.
+// An error is returned if the duration of the subscriber is not positive.
+func (s *Subscriber) Subscribe(subscription Subscription, when time.Time) (time.Time, error) {
+	// The subscriptions are tracked by their ref
+	if subscription.SubscriptionRef == "" {
+		return time.Time{}, errors.New("subscription ref is required")
+	}
+	// The subscription would have ended before it was requested
+	if s.Duration <= 0 {
+		return time.Time{}, &InvalidDurationError{Duration: s.Duration}
+	}
+
+	terminationTime := when.Add(s.Duration)
+
+	request, err := s.API.BuildSubscriptionRequest(subscription, s.ConsumerAddress, terminationTime, when)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	response, err := s.API.Send(request)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	statuses, err := s.API.ParseSubscriptionResponse(response)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	status, err := findStatus(statuses, subscription.SubscriptionRef)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// The producer can shorten the subscription
+	validUntil := terminationTime
+	if status.ValidUntil != "" {
+		if t, err := time.Parse(time.RFC3339, status.ValidUntil); err == nil {
+			validUntil = t
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscriptions == nil {
+		s.subscriptions = make(map[string]activeSubscription)
+	}
+	s.subscriptions[subscription.SubscriptionRef] = activeSubscription{
+		subscription: subscription,
+		validUntil:   validUntil,
+	}
+
+	return validUntil, nil
+}
+
+// ValidUntil returns the time the subscription ends, or false if there is no such subscription
+func (s *Subscriber) ValidUntil(subscriptionRef string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	active, ok := s.subscriptions[subscriptionRef]
+
+	return active.validUntil, ok
+}
+
+// Renew subscribes again to the subscriptions that end within the margin after the time.
+// All the subscriptions are attempted, and the error from the first that fails is returned.
+func (s *Subscriber) Renew(when time.Time, margin time.Duration) error {
+	var renewals []Subscription
+	s.mu.Lock()
+	for _, active := range s.subscriptions {
+		if active.validUntil.Before(when.Add(margin)) {
+			renewals = append(renewals, active.subscription)
+		}
+	}
+	s.mu.Unlock()
+
+	var firstErr error
+	for _, subscription := range renewals {
+		if _, err := s.Subscribe(subscription, when); err != nil {
+			log.Printf("Failed to renew subscription %s: %s", subscription.SubscriptionRef, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// Run renews the subscriptions at each interval until the context is done,
+// subscriptions are renewed when they would otherwise end before the next interval.
+// An error is returned if the interval is not positive.
+func (s *Subscriber) Run(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return &InvalidIntervalError{Interval: interval}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			// Errors are logged by Renew, the failed subscriptions are tried again at the next interval
+			_ = s.Renew(now, 2*interval)
+		}
+	}
+}
+
+// Terminate ends the subscriptions, or all the subscriptions of the requestor if none are given.
+// Once the producer has responded the subscriptions are no longer tracked, so they aren't renewed,
+// and the error for the first subscription the producer did not end is returned.
+func (s *Subscriber) Terminate(when time.Time, subscriptionRefs ...string) error {
+	request, err := s.API.BuildTerminateSubscriptionRequest(subscriptionRefs, when)
+	if err != nil {
+		return err
+	}
+
+	response, err := s.API.Send(request)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := s.API.ParseTerminateSubscriptionResponse(response)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	if len(subscriptionRefs) == 0 {
+		s.subscriptions = nil
+		for _, status := range statuses {
+			if !status.Status && firstErr == nil {
+				firstErr = &SubscriptionError{SubscriptionRef: status.SubscriptionRef, Reason: reason(status)}
+			}
+		}
+		return firstErr
+	}
+
+	for _, subscriptionRef := range subscriptionRefs {
+		delete(s.subscriptions, subscriptionRef)
+		if _, err := findStatus(statuses, subscriptionRef); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// findStatus returns the status of the subscription, or an error if the producer did not accept it
+func findStatus(statuses []ResponseStatus, subscriptionRef string) (ResponseStatus, error) {
+	for _, status := range statuses {
+		// Some producers only return the ref when there are several subscriptions
+		if status.SubscriptionRef != subscriptionRef && !(status.SubscriptionRef == "" && len(statuses) == 1) {
+			continue
+		}
+		if !status.Status {
+			return status, &SubscriptionError{SubscriptionRef: subscriptionRef, Reason: reason(status)}
+		}
+		return status, nil
+	}
+
+	return ResponseStatus{}, &SubscriptionError{SubscriptionRef: subscriptionRef, Reason: "no response status"}
+}
+
+// reason returns the description of why the producer did not accept the subscription
+func reason(status ResponseStatus) string {
+	if status.ErrorCondition.Description != "" {
+		return status.ErrorCondition.Description
+	}
+
+	return "rejected by producer"
+}
@@ -0,0 +1,164 @@
+package traveline_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/conradhodge/travel-api-client/internal/siritest"
+	"github.com/conradhodge/travel-api-client/traveline"
+)
+
+func TestSubscriber(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	validUntil, _ := time.Parse(time.RFC3339, "2020-03-30T13:34:56+01:00")
+
+	subscriptionResponse, err := os.ReadFile("testdata/subscription_response.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	terminateResponse, err := os.ReadFile("testdata/terminate_subscription_response.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	server := siritest.NewProducer(t).
+		WithAuth("TravelineAPI999", "letmein").
+		Respond("SubscriptionRequest", http.StatusOK, string(subscriptionResponse)).
+		Respond("TerminateSubscriptionRequest", http.StatusOK, string(terminateResponse))
+	defer server.Close()
+
+	client := &traveline.Client{
+		Username: "TravelineAPI999",
+		Password: "letmein",
+		URL:      server.URL,
+		Client:   server.Client(),
+	}
+	subscriber := traveline.NewSubscriber(client, "https://example.com/siri", 2*time.Hour)
+
+	// The producer shortens the subscription to an hour
+	result, err := subscriber.Subscribe(traveline.Subscription{
+		SubscriptionRef: "SUB-STOP",
+		Type:            traveline.StopMonitoringSubscription,
+		MonitoringRef:   "0180BAC30249",
+	}, when)
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+	if !result.Equal(validUntil) {
+		t.Fatalf("Expected valid until %s; got %s", validUntil, result)
+	}
+
+	// The producer rejects vehicle monitoring
+	_, err = subscriber.Subscribe(traveline.Subscription{
+		SubscriptionRef: "SUB-VEHICLES",
+		Type:            traveline.VehicleMonitoringSubscription,
+	}, when)
+	expectedError := errors.New(`Subscription "SUB-VEHICLES" failed: Vehicle monitoring is not supported`)
+	if err == nil || err.Error() != expectedError.Error() {
+		t.Fatalf("Expected error '%s'; got '%v'", expectedError, err)
+	}
+	if _, ok := subscriber.ValidUntil("SUB-VEHICLES"); ok {
+		t.Fatalf("Expected rejected subscription not to be active")
+	}
+
+	// A subscription without a ref can't be tracked, so isn't requested
+	_, err = subscriber.Subscribe(traveline.Subscription{
+		Type:          traveline.StopMonitoringSubscription,
+		MonitoringRef: "0180BAC30249",
+	}, when)
+	expectedError = errors.New("subscription ref is required")
+	if err == nil || err.Error() != expectedError.Error() {
+		t.Fatalf("Expected error '%s'; got '%v'", expectedError, err)
+	}
+
+	// Not renewed when it doesn't end within the margin
+	if err := subscriber.Renew(when.Add(30*time.Minute), 10*time.Minute); err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+	if requests := server.Received(); len(requests) != 2 {
+		t.Fatalf("Expected 2 requests; got %d", len(requests))
+	}
+
+	// Renewed when it ends within the margin
+	if err := subscriber.Renew(when.Add(55*time.Minute), 10*time.Minute); err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+	if requests := server.Received(); len(requests) != 3 || requests[2] != "SubscriptionRequest" {
+		t.Fatalf("Expected subscription to be renewed; got %v", requests)
+	}
+
+	// Still tracked when the producer doesn't respond to the termination
+	server.RespondOnce("TerminateSubscriptionRequest", http.StatusInternalServerError, "")
+	err = subscriber.Terminate(when, "SUB-STOP")
+	expectedError = errors.New("error status from API: 500")
+	if err == nil || err.Error() != expectedError.Error() {
+		t.Fatalf("Expected error '%s'; got '%v'", expectedError, err)
+	}
+	if _, ok := subscriber.ValidUntil("SUB-STOP"); !ok {
+		t.Fatalf("Expected subscription to be active until the producer responds")
+	}
+
+	// The producer does not return a status for a subscription it doesn't know,
+	// the other subscriptions are still terminated
+	err = subscriber.Terminate(when, "SUB-UNKNOWN", "SUB-STOP")
+	expectedError = errors.New(`Subscription "SUB-UNKNOWN" failed: no response status`)
+	if err == nil || err.Error() != expectedError.Error() {
+		t.Fatalf("Expected error '%s'; got '%v'", expectedError, err)
+	}
+	if requests := server.Received(); len(requests) != 5 || requests[4] != "TerminateSubscriptionRequest" {
+		t.Fatalf("Expected subscription to be terminated; got %v", requests)
+	}
+	if _, ok := subscriber.ValidUntil("SUB-STOP"); ok {
+		t.Fatalf("Expected terminated subscription not to be active")
+	}
+}
+
+func TestSubscriberInvalidDuration(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	server := siritest.NewProducer(t)
+	defer server.Close()
+
+	client := &traveline.Client{URL: server.URL, Client: server.Client()}
+
+	for _, duration := range []time.Duration{0, -time.Hour} {
+		subscriber := traveline.NewSubscriber(client, "https://example.com/siri", duration)
+
+		_, err := subscriber.Subscribe(traveline.Subscription{
+			SubscriptionRef: "SUB-STOP",
+			Type:            traveline.StopMonitoringSubscription,
+			MonitoringRef:   "0180BAC30249",
+		}, when)
+
+		expectedError := fmt.Sprintf("Invalid duration %s, it must be positive", duration)
+		if err == nil || err.Error() != expectedError {
+			t.Errorf("Expected error '%s'; got '%v'", expectedError, err)
+		}
+	}
+
+	// The producer is not asked for the subscription
+	if requests := server.Received(); len(requests) != 0 {
+		t.Errorf("Expected no requests; got %v", requests)
+	}
+}
+
+func TestSubscriberRunInvalidInterval(t *testing.T) {
+	server := siritest.NewProducer(t)
+	defer server.Close()
+
+	client := &traveline.Client{URL: server.URL, Client: server.Client()}
+	subscriber := traveline.NewSubscriber(client, "https://example.com/siri", time.Hour)
+
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		err := subscriber.Run(context.Background(), interval)
+
+		expectedError := fmt.Sprintf("Invalid interval %s, it must be positive", interval)
+		if err == nil || err.Error() != expectedError {
+			t.Errorf("Expected error '%s'; got '%v'", expectedError, err)
+		}
+	}
+}
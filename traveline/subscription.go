@@ -0,0 +1,161 @@
+package traveline
+
+import (
+	"log"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SubscriptionType is the Siri service that a subscription is for
+type SubscriptionType int
+
+// Siri services that can be subscribed to
+const (
+	StopMonitoringSubscription SubscriptionType = iota
+	VehicleMonitoringSubscription
+	SituationExchangeSubscription
+)
+
+// Subscription represents a subscription to the deliveries that a producer pushes for one of the Siri services
+type Subscription struct {
+	// SubscriptionRef identifies the subscription in deliveries, it is reused when the subscription is renewed
+	SubscriptionRef string
+	Type            SubscriptionType
+	// MonitoringRef is the NaPTAN code of the stop of a stop monitoring subscription
+	MonitoringRef string
+	// Filter selects the vehicles of a vehicle monitoring subscription
+	Filter VehicleFilter
+	// HeartbeatInterval is how often the producer should send heartbeat notifications,
+	// the producer default is used if not set
+	HeartbeatInterval time.Duration
+}
+
+// BuildSubscriptionRequest will return the XML for the request for the producer to push deliveries for the
+// subscription to the consumer address until the termination time
+func (c *Client) BuildSubscriptionRequest(
+	subscription Subscription,
+	consumerAddress string,
+	terminationTime time.Time,
+	when time.Time,
+) (string, error) {
+	request, err := c.newRequest()
+	if err != nil {
+		return "", err
+	}
+
+	if consumerAddress == "" {
+		return "", errors.New("consumer address is required")
+	}
+	// Deliveries, renewals and terminations are matched to the subscription by its ref
+	if subscription.SubscriptionRef == "" {
+		return "", errors.New("subscription ref is required")
+	}
+	if subscription.HeartbeatInterval < 0 {
+		return "", errors.Errorf("invalid heartbeat interval: %s", subscription.HeartbeatInterval)
+	}
+
+	subscriptionRequest := &SubscriptionRequest{
+		Requestor:       c.requestor(when),
+		ConsumerAddress: consumerAddress,
+	}
+	if subscription.HeartbeatInterval > 0 {
+		subscriptionRequest.SubscriptionContext = &SubscriptionContext{
+			HeartbeatInterval: formatDuration(subscription.HeartbeatInterval),
+		}
+	}
+
+	switch subscription.Type {
+	case StopMonitoringSubscription:
+		if subscription.MonitoringRef == "" {
+			return "", errors.New("monitoring ref is required for stop monitoring subscriptions")
+		}
+		subscriptionRequest.StopMonitoringSubscriptionRequest = &StopMonitoringSubscriptionRequest{
+			SubscriberRef:          c.Username,
+			SubscriptionIdentifier: subscription.SubscriptionRef,
+			InitialTerminationTime: terminationTime.Format(time.RFC3339),
+			RequestTimestamp:       when.Format(time.RFC3339),
+			MonitoringRef:          subscription.MonitoringRef,
+		}
+	case VehicleMonitoringSubscription:
+		subscriptionRequest.VehicleMonitoringSubscriptionRequest = &VehicleMonitoringSubscriptionRequest{
+			SubscriberRef:          c.Username,
+			SubscriptionIdentifier: subscription.SubscriptionRef,
+			InitialTerminationTime: terminationTime.Format(time.RFC3339),
+			RequestTimestamp:       when.Format(time.RFC3339),
+			LineRef:                subscription.Filter.LineRef,
+			OperatorRef:            subscription.Filter.OperatorRef,
+		}
+	case SituationExchangeSubscription:
+		subscriptionRequest.SituationExchangeSubscriptionRequest = &SituationExchangeSubscriptionRequest{
+			SubscriberRef:          c.Username,
+			SubscriptionIdentifier: subscription.SubscriptionRef,
+			InitialTerminationTime: terminationTime.Format(time.RFC3339),
+			RequestTimestamp:       when.Format(time.RFC3339),
+		}
+	default:
+		return "", errors.Errorf("unknown subscription type: %d", subscription.Type)
+	}
+
+	request.SubscriptionRequest = subscriptionRequest
+
+	log.Printf("SubscriptionIdentifier: %s, InitialTerminationTime: %s", subscription.SubscriptionRef, terminationTime.Format(time.RFC3339))
+
+	return request.Marshal()
+}
+
+// ParseSubscriptionResponse will parse the response from the producer and return the status of each subscription
+func (c *Client) ParseSubscriptionResponse(response string) ([]ResponseStatus, error) {
+	subscriptionResponse := SubscriptionResponse{}
+	err := unmarshal([]byte(response), &subscriptionResponse, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := subscriptionResponse.SubscriptionResponse.ResponseStatus
+	for _, status := range statuses {
+		log.Printf("SubscriptionRef: %s, Status: %t, ValidUntil: %s", status.SubscriptionRef, status.Status, status.ValidUntil)
+	}
+
+	return statuses, nil
+}
+
+// BuildTerminateSubscriptionRequest will return the XML for the request to end the subscriptions,
+// all the subscriptions of the requestor are ended if none are given
+func (c *Client) BuildTerminateSubscriptionRequest(subscriptionRefs []string, when time.Time) (string, error) {
+	request, err := c.newRequest()
+	if err != nil {
+		return "", err
+	}
+
+	terminateRequest := &TerminateSubscriptionRequest{
+		Requestor:       c.requestor(when),
+		SubscriptionRef: subscriptionRefs,
+	}
+	if len(subscriptionRefs) == 0 {
+		terminateRequest.All = &struct{}{}
+	}
+
+	request.TerminateSubscriptionRequest = terminateRequest
+
+	log.Printf("TerminateSubscriptionRequest: %d", len(subscriptionRefs))
+
+	return request.Marshal()
+}
+
+// ParseTerminateSubscriptionResponse will parse the response from the producer and return the status
+// of each subscription that was ended
+func (c *Client) ParseTerminateSubscriptionResponse(response string) ([]ResponseStatus, error) {
+	terminateResponse := TerminateSubscriptionResponse{}
+	err := unmarshal([]byte(response), &terminateResponse, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := terminateResponse.TerminateSubscriptionResponse.TerminationResponseStatus
+	for _, status := range statuses {
+		log.Printf("SubscriptionRef: %s, Status: %t", status.SubscriptionRef, status.Status)
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,342 @@
+package traveline_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildSubscriptionRequest(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	terminationTime, _ := time.Parse(time.RFC3339, "2020-03-30T13:34:56+01:00")
+
+	tests := []struct {
+		name            string
+		subscription    traveline.Subscription
+		consumerAddress string
+		expectedRequest string
+		expectedError   error
+	}{
+		{
+			name: "Stop monitoring",
+			subscription: traveline.Subscription{
+				SubscriptionRef:   "SUB-STOP",
+				Type:              traveline.StopMonitoringSubscription,
+				MonitoringRef:     "0180BAC30249",
+				HeartbeatInterval: 5 * time.Minute,
+			},
+			consumerAddress: "https://example.com/siri",
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><SubscriptionRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<ConsumerAddress>https://example.com/siri</ConsumerAddress>` +
+				`<SubscriptionContext><HeartbeatInterval>PT5M</HeartbeatInterval></SubscriptionContext>` +
+				`<StopMonitoringSubscriptionRequest><SubscriberRef>TravelineAPI999</SubscriberRef>` +
+				`<SubscriptionIdentifier>SUB-STOP</SubscriptionIdentifier>` +
+				`<InitialTerminationTime>2020-03-30T13:34:56+01:00</InitialTerminationTime>` +
+				`<StopMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MonitoringRef>0180BAC30249</MonitoringRef></StopMonitoringRequest>` +
+				`</StopMonitoringSubscriptionRequest></SubscriptionRequest></Siri>`,
+		},
+		{
+			name: "Vehicle monitoring",
+			subscription: traveline.Subscription{
+				SubscriptionRef: "SUB-VEHICLES",
+				Type:            traveline.VehicleMonitoringSubscription,
+				Filter:          traveline.VehicleFilter{LineRef: "42"},
+			},
+			consumerAddress: "https://example.com/siri",
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><SubscriptionRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<ConsumerAddress>https://example.com/siri</ConsumerAddress>` +
+				`<VehicleMonitoringSubscriptionRequest><SubscriberRef>TravelineAPI999</SubscriberRef>` +
+				`<SubscriptionIdentifier>SUB-VEHICLES</SubscriptionIdentifier>` +
+				`<InitialTerminationTime>2020-03-30T13:34:56+01:00</InitialTerminationTime>` +
+				`<VehicleMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<LineRef>42</LineRef></VehicleMonitoringRequest>` +
+				`</VehicleMonitoringSubscriptionRequest></SubscriptionRequest></Siri>`,
+		},
+		{
+			name: "Situation exchange",
+			subscription: traveline.Subscription{
+				SubscriptionRef: "SUB-SITUATIONS",
+				Type:            traveline.SituationExchangeSubscription,
+			},
+			consumerAddress: "https://example.com/siri",
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><SubscriptionRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<ConsumerAddress>https://example.com/siri</ConsumerAddress>` +
+				`<SituationExchangeSubscriptionRequest><SubscriberRef>TravelineAPI999</SubscriberRef>` +
+				`<SubscriptionIdentifier>SUB-SITUATIONS</SubscriptionIdentifier>` +
+				`<InitialTerminationTime>2020-03-30T13:34:56+01:00</InitialTerminationTime>` +
+				`<SituationExchangeRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp></SituationExchangeRequest>` +
+				`</SituationExchangeSubscriptionRequest></SubscriptionRequest></Siri>`,
+		},
+		{
+			name: "No consumer address",
+			subscription: traveline.Subscription{
+				SubscriptionRef: "SUB-SITUATIONS",
+				Type:            traveline.SituationExchangeSubscription,
+			},
+			expectedError: errors.New("consumer address is required"),
+		},
+		{
+			name: "No monitoring ref",
+			subscription: traveline.Subscription{
+				SubscriptionRef: "SUB-STOP",
+				Type:            traveline.StopMonitoringSubscription,
+			},
+			consumerAddress: "https://example.com/siri",
+			expectedError:   errors.New("monitoring ref is required for stop monitoring subscriptions"),
+		},
+		{
+			name: "No subscription ref",
+			subscription: traveline.Subscription{
+				Type: traveline.SituationExchangeSubscription,
+			},
+			consumerAddress: "https://example.com/siri",
+			expectedError:   errors.New("subscription ref is required"),
+		},
+		{
+			name: "Unknown type",
+			subscription: traveline.Subscription{
+				SubscriptionRef: "SUB-UNKNOWN",
+				Type:            traveline.SubscriptionType(42),
+			},
+			consumerAddress: "https://example.com/siri",
+			expectedError:   errors.New("unknown subscription type: 42"),
+		},
+		{
+			name: "Negative heartbeat interval",
+			subscription: traveline.Subscription{
+				SubscriptionRef:   "SUB-SITUATIONS",
+				Type:              traveline.SituationExchangeSubscription,
+				HeartbeatInterval: -time.Minute,
+			},
+			consumerAddress: "https://example.com/siri",
+			expectedError:   errors.New("invalid heartbeat interval: -1m0s"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+			request, err := client.BuildSubscriptionRequest(test.subscription, test.consumerAddress, terminationTime, when)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			if request != test.expectedRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedRequest, request))
+			}
+		})
+	}
+}
+
+func TestParseSubscriptionResponse(t *testing.T) {
+	response, err := os.ReadFile("testdata/subscription_response.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+	statuses, err := client.ParseSubscriptionResponse(string(response))
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	var results []string
+	for _, status := range statuses {
+		results = append(results, status.SubscriptionRef+" "+status.ValidUntil+" "+status.ErrorCondition.Description)
+	}
+	expected := []string{
+		"SUB-STOP 2020-03-30T13:34:56+01:00 ",
+		"SUB-VEHICLES  Vehicle monitoring is not supported",
+	}
+	if diff := cmp.Diff(expected, results); diff != "" {
+		t.Errorf("ParseSubscriptionResponse() (-want +got):\n%s", diff)
+	}
+	if !statuses[0].Status || statuses[1].Status {
+		t.Errorf("Expected statuses true, false; got %t, %t", statuses[0].Status, statuses[1].Status)
+	}
+
+	if _, err := client.ParseSubscriptionResponse("<Siri"); err == nil {
+		t.Fatalf("Expected error for invalid XML; got no error")
+	}
+}
+
+func TestBuildTerminateSubscriptionRequest(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+
+	tests := []struct {
+		name             string
+		subscriptionRefs []string
+		expectedRequest  string
+	}{
+		{
+			name:             "Subscriptions",
+			subscriptionRefs: []string{"SUB-STOP", "SUB-VEHICLES"},
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><TerminateSubscriptionRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<SubscriptionRef>SUB-STOP</SubscriptionRef><SubscriptionRef>SUB-VEHICLES</SubscriptionRef>` +
+				`</TerminateSubscriptionRequest></Siri>`,
+		},
+		{
+			name: "All subscriptions",
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><TerminateSubscriptionRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<All></All></TerminateSubscriptionRequest></Siri>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+			request, err := client.BuildTerminateSubscriptionRequest(test.subscriptionRefs, when)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			if request != test.expectedRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedRequest, request))
+			}
+		})
+	}
+}
+
+func TestParseTerminateSubscriptionResponse(t *testing.T) {
+	response, err := os.ReadFile("testdata/terminate_subscription_response.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+	statuses, err := client.ParseTerminateSubscriptionResponse(string(response))
+	if err != nil {
+		t.Fatalf("Expected no error; got '%s'", err)
+	}
+
+	if len(statuses) != 1 || statuses[0].SubscriptionRef != "SUB-STOP" || !statuses[0].Status {
+		t.Errorf("Expected SUB-STOP terminated; got %+v", statuses)
+	}
+}
+
+func TestSubscriptionVersions(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	terminationTime, _ := time.Parse(time.RFC3339, "2020-03-30T13:34:56+01:00")
+
+	tests := []struct {
+		name                     string
+		version                  string
+		expectedRequest          string
+		response                 string
+		expectedTerminateRequest string
+		terminateResponse        string
+	}{
+		{
+			name:    "Siri 1.0",
+			version: traveline.SiriVersion1,
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><SubscriptionRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<ConsumerAddress>https://example.com/siri</ConsumerAddress>` +
+				`<SituationExchangeSubscriptionRequest><SubscriberRef>TravelineAPI999</SubscriberRef>` +
+				`<SubscriptionIdentifier>SUB-SITUATIONS</SubscriptionIdentifier>` +
+				`<InitialTerminationTime>2020-03-30T13:34:56+01:00</InitialTerminationTime>` +
+				`<SituationExchangeRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp></SituationExchangeRequest>` +
+				`</SituationExchangeSubscriptionRequest></SubscriptionRequest></Siri>`,
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><SubscriptionResponse><ResponseStatus>` +
+				`<SubscriptionRef>SUB-SITUATIONS</SubscriptionRef><Status>true</Status>` +
+				`<ValidUntil>2020-03-30T13:34:56+01:00</ValidUntil></ResponseStatus></SubscriptionResponse></Siri>`,
+			expectedTerminateRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><TerminateSubscriptionRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<SubscriptionRef>SUB-SITUATIONS</SubscriptionRef></TerminateSubscriptionRequest></Siri>`,
+			terminateResponse: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><TerminateSubscriptionResponse>` +
+				`<TerminationResponseStatus><SubscriptionRef>SUB-SITUATIONS</SubscriptionRef><Status>true</Status>` +
+				`</TerminationResponseStatus></TerminateSubscriptionResponse></Siri>`,
+		},
+		{
+			name:    "Siri 2.0",
+			version: traveline.SiriVersion2,
+			expectedRequest: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><SubscriptionRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<ConsumerAddress>https://example.com/siri</ConsumerAddress>` +
+				`<SituationExchangeSubscriptionRequest><SubscriberRef>TravelineAPI999</SubscriberRef>` +
+				`<SubscriptionIdentifier>SUB-SITUATIONS</SubscriptionIdentifier>` +
+				`<InitialTerminationTime>2020-03-30T13:34:56+01:00</InitialTerminationTime>` +
+				`<SituationExchangeRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp></SituationExchangeRequest>` +
+				`</SituationExchangeSubscriptionRequest></SubscriptionRequest></Siri>`,
+			response: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><SubscriptionResponse version="2.0"><ResponseStatus>` +
+				`<SubscriptionRef>SUB-SITUATIONS</SubscriptionRef><Status>true</Status>` +
+				`<ValidUntil>2020-03-30T13:34:56+01:00</ValidUntil></ResponseStatus></SubscriptionResponse></Siri>`,
+			expectedTerminateRequest: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><TerminateSubscriptionRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<SubscriptionRef>SUB-SITUATIONS</SubscriptionRef></TerminateSubscriptionRequest></Siri>`,
+			terminateResponse: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><TerminateSubscriptionResponse version="2.0">` +
+				`<TerminationResponseStatus><SubscriptionRef>SUB-SITUATIONS</SubscriptionRef><Status>true</Status>` +
+				`</TerminationResponseStatus></TerminateSubscriptionResponse></Siri>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein", Version: test.version}
+
+			request, err := client.BuildSubscriptionRequest(
+				traveline.Subscription{SubscriptionRef: "SUB-SITUATIONS", Type: traveline.SituationExchangeSubscription},
+				"https://example.com/siri",
+				terminationTime,
+				when,
+			)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if request != test.expectedRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedRequest, request))
+			}
+
+			statuses, err := client.ParseSubscriptionResponse(test.response)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if len(statuses) != 1 || statuses[0].SubscriptionRef != "SUB-SITUATIONS" || !statuses[0].Status ||
+				statuses[0].ValidUntil != "2020-03-30T13:34:56+01:00" {
+				t.Fatalf("Response statuses not as expected: %+v", statuses)
+			}
+
+			request, err = client.BuildTerminateSubscriptionRequest([]string{"SUB-SITUATIONS"}, when)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if request != test.expectedTerminateRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedTerminateRequest, request))
+			}
+
+			statuses, err = client.ParseTerminateSubscriptionResponse(test.terminateResponse)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if len(statuses) != 1 || statuses[0].SubscriptionRef != "SUB-SITUATIONS" || !statuses[0].Status {
+				t.Fatalf("Termination statuses not as expected: %+v", statuses)
+			}
+		})
+	}
+}
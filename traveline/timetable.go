@@ -0,0 +1,60 @@
+package traveline
+
+import (
+	"log"
+	"time"
+)
+
+// JourneyRef returns the reference of the journey, from the framed reference or the dated journey code
+func (j EstimatedVehicleJourney) JourneyRef() string {
+	if j.FramedVehicleJourneyRef.DatedVehicleJourneyRef != "" {
+		return j.FramedVehicleJourneyRef.DatedVehicleJourneyRef
+	}
+
+	return j.DatedVehicleJourneyCode
+}
+
+// BuildEstimatedTimetableRequest will return the XML for the request for the estimated timetable of the line,
+// all lines are requested if the line ref is empty
+func (c *Client) BuildEstimatedTimetableRequest(requestRef string, lineRef string, when time.Time) (string, error) {
+	request, err := c.NewServiceRequest(when)
+	if err != nil {
+		return "", err
+	}
+
+	estimatedTimetableRequest := EstimatedTimetableRequest{
+		FunctionalRequest: NewFunctionalRequest(requestRef, when),
+	}
+	if lineRef != "" {
+		estimatedTimetableRequest.Lines = &Lines{
+			LineDirection: []LineDirection{{LineRef: lineRef}},
+		}
+	}
+	serviceRequest := request.ServiceRequest
+	serviceRequest.EstimatedTimetableRequest = append(serviceRequest.EstimatedTimetableRequest, estimatedTimetableRequest)
+
+	log.Printf("EstimatedTimetableRequestMessageIdentifier: %s", estimatedTimetableRequest.MessageIdentifier)
+
+	return request.Marshal()
+}
+
+// ParseEstimatedTimetableDelivery will parse the response from the Traveline API and return the estimated journeys
+// in the deliveries for the request
+func (c *Client) ParseEstimatedTimetableDelivery(requestRef string, response string) ([]EstimatedVehicleJourney, error) {
+	serviceDelivery := ServiceDelivery{}
+	err := unmarshal([]byte(response), &serviceDelivery, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	var journeys []EstimatedVehicleJourney
+	for _, delivery := range serviceDelivery.ServiceDelivery.EstimatedTimetableDelivery {
+		if !delivery.answers(requestRef) {
+			continue
+		}
+		log.Printf("RequestMessageRef: %s, Journeys: %d", delivery.RequestMessageRef, len(delivery.EstimatedVehicleJourney))
+		journeys = append(journeys, delivery.EstimatedVehicleJourney...)
+	}
+
+	return journeys, nil
+}
@@ -0,0 +1,206 @@
+package traveline_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/conradhodge/travel-api-client/traveline"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildEstimatedTimetableRequest(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+
+	tests := []struct {
+		name            string
+		lineRef         string
+		expectedRequest string
+	}{
+		{
+			name: "All lines",
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<EstimatedTimetableRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`</EstimatedTimetableRequest></ServiceRequest></Siri>`,
+		},
+		{
+			name:    "Line",
+			lineRef: "GLBE:42",
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<EstimatedTimetableRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<Lines><LineDirection><LineRef>GLBE:42</LineRef></LineDirection></Lines>` +
+				`</EstimatedTimetableRequest></ServiceRequest></Siri>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+			request, err := client.BuildEstimatedTimetableRequest("ab7c1e9b-d06f-44cc-b190-4d36fb564386", test.lineRef, when)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			if request != test.expectedRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedRequest, request))
+			}
+		})
+	}
+}
+
+func TestParseEstimatedTimetableDelivery(t *testing.T) {
+	response, err := os.ReadFile("testdata/estimated_timetable.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tests := []struct {
+		name             string
+		response         string
+		expectedJourneys []string
+		expectedError    error
+	}{
+		{
+			name:             "Estimated journeys",
+			response:         string(response),
+			expectedJourneys: []string{"1234", "5678"},
+		},
+		{
+			name:     "No journeys",
+			response: `<Siri><ServiceDelivery><EstimatedTimetableDelivery></EstimatedTimetableDelivery></ServiceDelivery></Siri>`,
+		},
+		{
+			name:          "Invalid XML",
+			response:      `<Siri>`,
+			expectedError: errors.New("XML syntax error on line 1: unexpected EOF"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+			journeys, err := client.ParseEstimatedTimetableDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", test.response)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			if len(journeys) != len(test.expectedJourneys) {
+				t.Fatalf("Expected %d journeys; got %d", len(test.expectedJourneys), len(journeys))
+			}
+			for i, journey := range journeys {
+				if journey.JourneyRef() != test.expectedJourneys[i] {
+					t.Fatalf("Expected journey '%s'; got '%s'", test.expectedJourneys[i], journey.JourneyRef())
+				}
+			}
+		})
+	}
+
+	// Check the calls of the first journey are parsed
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+	journeys, _ := client.ParseEstimatedTimetableDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", string(response))
+	journey := journeys[0]
+	if len(journey.RecordedCalls) != 1 || journey.RecordedCalls[0].ActualDepartureTime != "2020-03-30T12:02:00+01:00" {
+		t.Fatalf("Unexpected recorded calls: %+v", journey.RecordedCalls)
+	}
+	if len(journey.EstimatedCalls) != 2 || journey.EstimatedCalls[1].Order != 3 {
+		t.Fatalf("Unexpected estimated calls: %+v", journey.EstimatedCalls)
+	}
+}
+
+func TestEstimatedTimetableVersions(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+
+	tests := []struct {
+		name            string
+		version         string
+		expectedRequest string
+		response        string
+	}{
+		{
+			name:    "Siri 1.0",
+			version: traveline.SiriVersion1,
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<EstimatedTimetableRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<Lines><LineDirection><LineRef>GLBE:42</LineRef></LineDirection></Lines>` +
+				`</EstimatedTimetableRequest></ServiceRequest></Siri>`,
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><EstimatedTimetableDelivery>` +
+				`<EstimatedJourneyVersionFrame><EstimatedVehicleJourney><LineRef>GLBE:42</LineRef>` +
+				`<DatedVehicleJourneyRef>1234</DatedVehicleJourneyRef>` +
+				`<RecordedCalls><RecordedCall><StopPointRef>020035800</StopPointRef><VisitNumber>1</VisitNumber></RecordedCall></RecordedCalls>` +
+				`<EstimatedCalls><EstimatedCall><StopPointRef>020035811</StopPointRef><VisitNumber>2</VisitNumber></EstimatedCall></EstimatedCalls>` +
+				`</EstimatedVehicleJourney></EstimatedJourneyVersionFrame></EstimatedTimetableDelivery></ServiceDelivery></Siri>`,
+		},
+		{
+			name:    "Siri 2.0",
+			version: traveline.SiriVersion2,
+			expectedRequest: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<EstimatedTimetableRequest version="2.0"><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<Lines><LineDirection><LineRef>GLBE:42</LineRef></LineDirection></Lines>` +
+				`</EstimatedTimetableRequest></ServiceRequest></Siri>`,
+			response: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><ServiceDelivery><EstimatedTimetableDelivery version="2.0">` +
+				`<EstimatedJourneyVersionFrame><EstimatedVehicleJourney><LineRef>GLBE:42</LineRef>` +
+				`<FramedVehicleJourneyRef><DataFrameRef>2020-03-30</DataFrameRef><DatedVehicleJourneyRef>1234</DatedVehicleJourneyRef></FramedVehicleJourneyRef>` +
+				`<RecordedCalls><RecordedCall><StopPointRef>020035800</StopPointRef><Order>1</Order></RecordedCall></RecordedCalls>` +
+				`<EstimatedCalls><EstimatedCall><StopPointRef>020035811</StopPointRef><Order>2</Order></EstimatedCall></EstimatedCalls>` +
+				`</EstimatedVehicleJourney></EstimatedJourneyVersionFrame></EstimatedTimetableDelivery></ServiceDelivery></Siri>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein", Version: test.version}
+
+			request, err := client.BuildEstimatedTimetableRequest("ab7c1e9b-d06f-44cc-b190-4d36fb564386", "GLBE:42", when)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if request != test.expectedRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedRequest, request))
+			}
+
+			journeys, err := client.ParseEstimatedTimetableDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", test.response)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if len(journeys) != 1 {
+				t.Fatalf("Expected 1 journey; got %d", len(journeys))
+			}
+			journey := journeys[0]
+			if journey.JourneyRef() != "1234" || journey.LineRef != "GLBE:42" {
+				t.Fatalf("Unexpected journey: %+v", journey)
+			}
+
+			expectedRecordedCalls := []traveline.RecordedCall{{StopPointRef: "020035800", Order: 1}}
+			if diff := cmp.Diff(expectedRecordedCalls, journey.RecordedCalls); diff != "" {
+				t.Errorf("RecordedCalls (-want +got):\n%s", diff)
+			}
+			expectedEstimatedCalls := []traveline.EstimatedCall{{StopPointRef: "020035811", Order: 2}}
+			if diff := cmp.Diff(expectedEstimatedCalls, journey.EstimatedCalls); diff != "" {
+				t.Errorf("EstimatedCalls (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
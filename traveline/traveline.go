@@ -4,9 +4,83 @@ import (
 	"time"
 )
 
-// API represents the interface to the Traveline API
-type API interface {
-	BuildServiceRequest(requestRef string, naptanCode string, when time.Time) (string, error)
-	ParseServiceDelivery(response string) (*MonitoredVehicleJourney, error)
+// Sender sends the requests of every service to the producer
+type Sender interface {
 	Send(request string) (string, error)
 }
+
+// StopMonitoringAPI represents the Stop Monitoring service, the departures from a stop
+type StopMonitoringAPI interface {
+	Sender
+	BuildServiceRequest(requestRef string, naptanCode string, when time.Time, options RequestOptions) (string, error)
+	ParseServiceDelivery(response string) (*MonitoredVehicleJourney, error)
+	ParseStopMonitoringDelivery(requestRef string, response string) ([]MonitoredStopVisit, error)
+}
+
+// VehicleMonitoringAPI represents the Vehicle Monitoring service, the positions of vehicles
+type VehicleMonitoringAPI interface {
+	Sender
+	BuildVehicleMonitoringRequest(requestRef string, filter VehicleFilter, when time.Time) (string, error)
+	ParseVehicleMonitoringDelivery(requestRef string, response string) ([]VehicleActivity, error)
+}
+
+// SituationExchangeAPI represents the Situation Exchange service, the disruptions to the network
+type SituationExchangeAPI interface {
+	Sender
+	BuildSituationExchangeRequest(requestRef string, when time.Time) (string, error)
+	ParseSituationExchangeDelivery(requestRef string, response string) ([]PtSituationElement, error)
+}
+
+// EstimatedTimetableAPI represents the Estimated Timetable service, the predictions for whole journeys
+type EstimatedTimetableAPI interface {
+	Sender
+	BuildEstimatedTimetableRequest(requestRef string, lineRef string, when time.Time) (string, error)
+	ParseEstimatedTimetableDelivery(requestRef string, response string) ([]EstimatedVehicleJourney, error)
+}
+
+// SubscriptionAPI represents the requests to subscribe to deliveries pushed by the producer
+type SubscriptionAPI interface {
+	Sender
+	BuildSubscriptionRequest(subscription Subscription, consumerAddress string, terminationTime time.Time, when time.Time) (string, error)
+	ParseSubscriptionResponse(response string) ([]ResponseStatus, error)
+	BuildTerminateSubscriptionRequest(subscriptionRefs []string, when time.Time) (string, error)
+	ParseTerminateSubscriptionResponse(response string) ([]ResponseStatus, error)
+}
+
+// CheckStatusAPI represents the Check Status requests for the status of the producer
+type CheckStatusAPI interface {
+	Sender
+	BuildCheckStatusRequest(when time.Time) (string, error)
+	ParseCheckStatusResponse(response string) (*ProducerStatus, error)
+}
+
+// DiscoveryAPI represents the discovery requests for the stops and lines that the producer covers
+type DiscoveryAPI interface {
+	Sender
+	BuildStopPointsRequest(when time.Time) (string, error)
+	ParseStopPointsDelivery(response string) ([]AnnotatedStopPointRef, error)
+	BuildLinesRequest(when time.Time) (string, error)
+	ParseLinesDelivery(response string) ([]AnnotatedLineRef, error)
+}
+
+// RealTimeAPI represents the services for the real time information about stops, vehicles and the network
+type RealTimeAPI interface {
+	StopMonitoringAPI
+	VehicleMonitoringAPI
+	SituationExchangeAPI
+	EstimatedTimetableAPI
+}
+
+// LiteAPI represents the services of Siri-Lite, which has no subscriptions or Check Status
+type LiteAPI interface {
+	RealTimeAPI
+	DiscoveryAPI
+}
+
+// API represents the interface to the Traveline API, every service of the Siri producer
+type API interface {
+	RealTimeAPI
+	SubscriptionAPI
+	CheckStatusAPI
+	DiscoveryAPI
+}
@@ -2,49 +2,605 @@ package traveline
 
 import "encoding/xml"
 
-// ServiceRequest represents the Siri Service Request XML
+// Siri represents the Siri XML envelope of a request, with the version and namespace of the request.
+// Only one of the requests in the envelope is set.
+type Siri struct {
+	XMLName                      xml.Name                      `xml:"Siri"`
+	Version                      string                        `xml:"version,attr"`
+	XMLNS                        string                        `xml:"xmlns,attr"`
+	ServiceRequest               *ServiceRequest               `xml:"ServiceRequest,omitempty"`
+	SubscriptionRequest          *SubscriptionRequest          `xml:"SubscriptionRequest,omitempty"`
+	TerminateSubscriptionRequest *TerminateSubscriptionRequest `xml:"TerminateSubscriptionRequest,omitempty"`
+	CheckStatusRequest           *Requestor                    `xml:"CheckStatusRequest,omitempty"`
+	StopPointsRequest            *Requestor                    `xml:"StopPointsRequest,omitempty"`
+	LinesRequest                 *Requestor                    `xml:"LinesRequest,omitempty"`
+}
+
+// Requestor represents the elements shared by the Siri requests, when and by whom the request was made
+type Requestor struct {
+	RequestTimestamp string `xml:"RequestTimestamp"`
+	RequestorRef     string `xml:"RequestorRef"`
+}
+
+// ServiceRequest represents the Siri Service Request XML, the functional requests for each of the Siri services.
+// Every functional request in the Service Request is made by the same requestor.
 type ServiceRequest struct {
-	XMLName                                xml.Name `xml:"Siri"`
-	Version                                string   `xml:"version,attr"`
-	XMLNS                                  string   `xml:"xmlns,attr"`
-	ServiceRequestRequestTimestamp         string   `xml:"ServiceRequest>RequestTimestamp"`
-	ServiceRequestRequestorRef             string   `xml:"ServiceRequest>RequestorRef"`
-	StopMonitoringRequestRequestTimestamp  string   `xml:"ServiceRequest>StopMonitoringRequest>RequestTimestamp"`
-	StopMonitoringRequestMessageIdentifier string   `xml:"ServiceRequest>StopMonitoringRequest>MessageIdentifier"`
-	StopMonitoringRequestMonitoringRef     string   `xml:"ServiceRequest>StopMonitoringRequest>MonitoringRef"`
+	Requestor
+	StopMonitoringRequest     []StopMonitoringRequest     `xml:"StopMonitoringRequest"`
+	VehicleMonitoringRequest  []VehicleMonitoringRequest  `xml:"VehicleMonitoringRequest"`
+	SituationExchangeRequest  []SituationExchangeRequest  `xml:"SituationExchangeRequest"`
+	EstimatedTimetableRequest []EstimatedTimetableRequest `xml:"EstimatedTimetableRequest"`
+}
+
+// FunctionalRequest represents the elements shared by the Siri functional requests,
+// the MessageIdentifier is returned as the RequestMessageRef of the delivery
+type FunctionalRequest struct {
+	Version           string `xml:"version,attr,omitempty"`
+	RequestTimestamp  string `xml:"RequestTimestamp"`
+	MessageIdentifier string `xml:"MessageIdentifier"`
+}
+
+// StopMonitoringRequest represents the Siri Stop Monitoring Request XML
+type StopMonitoringRequest struct {
+	FunctionalRequest
+	PreviewInterval           string `xml:"PreviewInterval,omitempty"`
+	StartTime                 string `xml:"StartTime,omitempty"`
+	MonitoringRef             string `xml:"MonitoringRef"`
+	MaximumStopVisits         int    `xml:"MaximumStopVisits,omitempty"`
+	MinimumStopVisitsPerLine  int    `xml:"MinimumStopVisitsPerLine,omitempty"`
+	StopMonitoringDetailLevel string `xml:"StopMonitoringDetailLevel,omitempty"`
 }
 
-// ServiceDelivery represents the Siri Service Delivery XML response
+// VehicleMonitoringRequest represents the Siri Vehicle Monitoring Request XML
+type VehicleMonitoringRequest struct {
+	FunctionalRequest
+	LineRef     string `xml:"LineRef,omitempty"`
+	OperatorRef string `xml:"OperatorRef,omitempty"`
+}
+
+// SituationExchangeRequest represents the Siri Situation Exchange Request XML
+type SituationExchangeRequest struct {
+	FunctionalRequest
+}
+
+// EstimatedTimetableRequest represents the Siri Estimated Timetable Request XML
+type EstimatedTimetableRequest struct {
+	FunctionalRequest
+	Lines *Lines `xml:"Lines,omitempty"`
+}
+
+// ServiceDelivery represents the Siri Service Delivery XML response, a delivery for each of the functional
+// requests of the Service Request
 type ServiceDelivery struct {
 	XMLName         xml.Name `xml:"Siri"`
 	Version         string   `xml:"version,attr"`
 	XMLNS           string   `xml:"xmlns,attr"`
 	ServiceDelivery struct {
-		ResponseTimestamp      string `xml:"ResponseTimestamp"`
-		StopMonitoringDelivery struct {
-			ResponseTimestamp  string `xml:"ResponseTimestamp"`
-			RequestMessageRef  string `xml:"RequestMessageRef"`
-			MonitoredStopVisit []struct {
-				RecordedAtTime          string                  `xml:"RecordedAtTime"`
-				MonitoringRef           string                  `xml:"MonitoringRef"`
-				MonitoredVehicleJourney MonitoredVehicleJourney `xml:"MonitoredVehicleJourney"`
-			} `xml:"MonitoredStopVisit"`
-		} `xml:"StopMonitoringDelivery"`
+		ResponseTimestamp          string                       `xml:"ResponseTimestamp"`
+		ProducerRef                string                       `xml:"ProducerRef"`
+		StopMonitoringDelivery     []StopMonitoringDelivery     `xml:"StopMonitoringDelivery"`
+		VehicleMonitoringDelivery  []VehicleMonitoringDelivery  `xml:"VehicleMonitoringDelivery"`
+		SituationExchangeDelivery  []SituationExchangeDelivery  `xml:"SituationExchangeDelivery"`
+		EstimatedTimetableDelivery []EstimatedTimetableDelivery `xml:"EstimatedTimetableDelivery"`
 	} `xml:"ServiceDelivery"`
 }
 
+// FunctionalDelivery represents the elements shared by the Siri deliveries, the RequestMessageRef is the
+// MessageIdentifier of the request answered and the SubscriptionRef is set on pushed deliveries
+type FunctionalDelivery struct {
+	ResponseTimestamp string `xml:"ResponseTimestamp"`
+	RequestMessageRef string `xml:"RequestMessageRef"`
+	SubscriptionRef   string `xml:"SubscriptionRef"`
+}
+
+// StopMonitoringDelivery represents the Siri Stop Monitoring Delivery XML
+type StopMonitoringDelivery struct {
+	FunctionalDelivery
+	MonitoredStopVisit []MonitoredStopVisit `xml:"MonitoredStopVisit"`
+}
+
 // MonitoredVehicleJourney represents the Siri Monitored Vehicle Journey XML
 type MonitoredVehicleJourney struct {
 	FramedVehicleJourneyRef struct {
 		DataFrameRef           string `xml:"DataFrameRef"`
 		DatedVehicleJourneyRef string `xml:"DatedVehicleJourneyRef"`
 	} `xml:"FramedVehicleJourneyRef"`
-	VehicleMode       string `xml:"VehicleMode"`
-	PublishedLineName string `xml:"PublishedLineName"`
-	DirectionName     string `xml:"DirectionName"`
-	OperatorRef       string `xml:"OperatorRef"`
-	MonitoredCall     struct {
-		AimedDepartureTime    string `xml:"AimedDepartureTime"`
-		ExpectedDepartureTime string `xml:"ExpectedDepartureTime"`
-	} `xml:"MonitoredCall"`
+	VehicleMode       string         `xml:"VehicleMode"`
+	PublishedLineName string         `xml:"PublishedLineName"`
+	DirectionName     string         `xml:"DirectionName"`
+	OperatorRef       string         `xml:"OperatorRef"`
+	DestinationRef    string         `xml:"DestinationRef"`
+	DestinationName   string         `xml:"DestinationName"`
+	Monitored         string         `xml:"Monitored"`
+	Occupancy         string         `xml:"Occupancy"`
+	VehicleFeatureRef []string       `xml:"VehicleFeatureRef"`
+	MonitoredCall     MonitoredCall  `xml:"MonitoredCall"`
+	PreviousCalls     []PreviousCall `xml:"PreviousCalls>PreviousCall"`
+	OnwardCalls       []OnwardCall   `xml:"OnwardCalls>OnwardCall"`
+	Extensions        struct {
+		VehicleJourney struct {
+			SeatedOccupancy     string `xml:"SeatedOccupancy"`
+			SeatedCapacity      string `xml:"SeatedCapacity"`
+			WheelchairOccupancy string `xml:"WheelchairOccupancy"`
+			WheelchairCapacity  string `xml:"WheelchairCapacity"`
+		} `xml:"VehicleJourney"`
+	} `xml:"Extensions"`
+}
+
+// UnmarshalXML maps the occupancy of the journey from either Siri version,
+// Siri 2.0 has more occupancy levels than Siri 1.0
+func (j *MonitoredVehicleJourney) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type monitoredVehicleJourney MonitoredVehicleJourney
+	if err := d.DecodeElement((*monitoredVehicleJourney)(j), &start); err != nil {
+		return err
+	}
+
+	if occupancy, ok := occupancyLevels[j.Occupancy]; ok {
+		j.Occupancy = occupancy
+	}
+
+	return nil
+}
+
+// MonitoredCall represents the Siri Monitored Call XML, the call of the journey at the monitored stop.
+// There is no departure from a stop that the journey terminates at, and producers may only publish
+// the departure from stops that it passes through.
+type MonitoredCall struct {
+	AimedArrivalTime      string `xml:"AimedArrivalTime"`
+	ExpectedArrivalTime   string `xml:"ExpectedArrivalTime"`
+	ArrivalStatus         string `xml:"ArrivalStatus"`
+	AimedDepartureTime    string `xml:"AimedDepartureTime"`
+	ExpectedDepartureTime string `xml:"ExpectedDepartureTime"`
+	DepartureStatus       string `xml:"DepartureStatus"`
+}
+
+// HasDeparture returns whether the call has a departure time, which may be left out both at the stop
+// a journey terminates at and at stops it passes through
+func (c MonitoredCall) HasDeparture() bool {
+	return c.AimedDepartureTime != "" || c.ExpectedDepartureTime != ""
+}
+
+// PreviousCall represents the Siri Previous Call XML, a stop the journey called at before the monitored stop,
+// only returned when the calls detail level is requested
+type PreviousCall struct {
+	StopPointRef        string `xml:"StopPointRef"`
+	VisitNumber         int    `xml:"VisitNumber"`
+	StopPointName       string `xml:"StopPointName"`
+	AimedArrivalTime    string `xml:"AimedArrivalTime"`
+	ActualArrivalTime   string `xml:"ActualArrivalTime"`
+	AimedDepartureTime  string `xml:"AimedDepartureTime"`
+	ActualDepartureTime string `xml:"ActualDepartureTime"`
+}
+
+// UnmarshalXML maps the position of the call from either Siri version,
+// Siri 2.0 orders the calls of the journey by Order rather than VisitNumber
+func (c *PreviousCall) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type previousCall PreviousCall
+	call := struct {
+		previousCall
+		Order int `xml:"Order"`
+	}{}
+	if err := d.DecodeElement(&call, &start); err != nil {
+		return err
+	}
+
+	*c = PreviousCall(call.previousCall)
+	if c.VisitNumber == 0 {
+		c.VisitNumber = call.Order
+	}
+
+	return nil
+}
+
+// OnwardCall represents the Siri Onward Call XML, a stop the journey calls at after the monitored stop,
+// only returned when the calls detail level is requested
+type OnwardCall struct {
+	StopPointRef          string `xml:"StopPointRef"`
+	VisitNumber           int    `xml:"VisitNumber"`
+	StopPointName         string `xml:"StopPointName"`
+	AimedArrivalTime      string `xml:"AimedArrivalTime"`
+	ExpectedArrivalTime   string `xml:"ExpectedArrivalTime"`
+	AimedDepartureTime    string `xml:"AimedDepartureTime"`
+	ExpectedDepartureTime string `xml:"ExpectedDepartureTime"`
+}
+
+// UnmarshalXML maps the position of the call from either Siri version,
+// Siri 2.0 orders the calls of the journey by Order rather than VisitNumber
+func (c *OnwardCall) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type onwardCall OnwardCall
+	call := struct {
+		onwardCall
+		Order int `xml:"Order"`
+	}{}
+	if err := d.DecodeElement(&call, &start); err != nil {
+		return err
+	}
+
+	*c = OnwardCall(call.onwardCall)
+	if c.VisitNumber == 0 {
+		c.VisitNumber = call.Order
+	}
+
+	return nil
+}
+
+// VehicleMonitoringDelivery represents the Siri Vehicle Monitoring Delivery XML
+type VehicleMonitoringDelivery struct {
+	FunctionalDelivery
+	VehicleActivity []VehicleActivity `xml:"VehicleActivity"`
+}
+
+// VehicleActivity represents the Siri Vehicle Activity XML, the position of a vehicle on a journey
+type VehicleActivity struct {
+	RecordedAtTime          string `xml:"RecordedAtTime"`
+	ValidUntilTime          string `xml:"ValidUntilTime"`
+	MonitoredVehicleJourney struct {
+		LineRef                 string `xml:"LineRef"`
+		DirectionRef            string `xml:"DirectionRef"`
+		FramedVehicleJourneyRef struct {
+			DataFrameRef           string `xml:"DataFrameRef"`
+			DatedVehicleJourneyRef string `xml:"DatedVehicleJourneyRef"`
+		} `xml:"FramedVehicleJourneyRef"`
+		PublishedLineName string `xml:"PublishedLineName"`
+		OperatorRef       string `xml:"OperatorRef"`
+		OriginRef         string `xml:"OriginRef"`
+		DestinationRef    string `xml:"DestinationRef"`
+		DestinationName   string `xml:"DestinationName"`
+		VehicleLocation   struct {
+			Longitude float64 `xml:"Longitude"`
+			Latitude  float64 `xml:"Latitude"`
+		} `xml:"VehicleLocation"`
+		Bearing    float64 `xml:"Bearing"`
+		Delay      string  `xml:"Delay"`
+		VehicleRef string  `xml:"VehicleRef"`
+	} `xml:"MonitoredVehicleJourney"`
+}
+
+// SituationExchangeDelivery represents the Siri Situation Exchange Delivery XML
+type SituationExchangeDelivery struct {
+	FunctionalDelivery
+	Situations []PtSituationElement `xml:"Situations>PtSituationElement"`
+}
+
+// PtSituationElement represents the Siri PtSituationElement XML, a disruption to the network
+type PtSituationElement struct {
+	CreationTime    string `xml:"CreationTime"`
+	ParticipantRef  string `xml:"ParticipantRef"`
+	SituationNumber string `xml:"SituationNumber"`
+	Version         string `xml:"Version"`
+	Progress        string `xml:"Progress"`
+	ValidityPeriod  []struct {
+		StartTime string `xml:"StartTime"`
+		EndTime   string `xml:"EndTime"`
+	} `xml:"ValidityPeriod"`
+	MiscellaneousReason string `xml:"MiscellaneousReason"`
+	Severity            string `xml:"Severity"`
+	Summary             string `xml:"Summary"`
+	Description         string `xml:"Description"`
+	Affects             struct {
+		Lines []struct {
+			LineRef           string `xml:"LineRef"`
+			PublishedLineName string `xml:"PublishedLineName"`
+		} `xml:"Networks>AffectedNetwork>AffectedLine"`
+		StopPoints []struct {
+			StopPointRef  string `xml:"StopPointRef"`
+			StopPointName string `xml:"StopPointName"`
+		} `xml:"StopPoints>AffectedStopPoint"`
+	} `xml:"Affects"`
+}
+
+// Lines represents the Siri Lines XML, the lines a request is for
+type Lines struct {
+	LineDirection []LineDirection `xml:"LineDirection"`
+}
+
+// LineDirection represents the Siri LineDirection XML, a line and optionally its direction
+type LineDirection struct {
+	LineRef      string `xml:"LineRef"`
+	DirectionRef string `xml:"DirectionRef,omitempty"`
+}
+
+// EstimatedTimetableDelivery represents the Siri Estimated Timetable Delivery XML
+type EstimatedTimetableDelivery struct {
+	FunctionalDelivery
+	EstimatedVehicleJourney []EstimatedVehicleJourney `xml:"EstimatedJourneyVersionFrame>EstimatedVehicleJourney"`
+}
+
+// EstimatedVehicleJourney represents the Siri Estimated Vehicle Journey XML, the predictions for a whole journey
+type EstimatedVehicleJourney struct {
+	LineRef                 string `xml:"LineRef"`
+	DirectionRef            string `xml:"DirectionRef"`
+	DatedVehicleJourneyCode string `xml:"DatedVehicleJourneyCode"`
+	FramedVehicleJourneyRef struct {
+		DataFrameRef           string `xml:"DataFrameRef"`
+		DatedVehicleJourneyRef string `xml:"DatedVehicleJourneyRef"`
+	} `xml:"FramedVehicleJourneyRef"`
+	PublishedLineName string          `xml:"PublishedLineName"`
+	OperatorRef       string          `xml:"OperatorRef"`
+	VehicleRef        string          `xml:"VehicleRef"`
+	RecordedCalls     []RecordedCall  `xml:"RecordedCalls>RecordedCall"`
+	EstimatedCalls    []EstimatedCall `xml:"EstimatedCalls>EstimatedCall"`
+}
+
+// UnmarshalXML maps the reference of the journey from either Siri version,
+// Siri 1.0 has the DatedVehicleJourneyRef without the frame it is in
+func (j *EstimatedVehicleJourney) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type estimatedVehicleJourney EstimatedVehicleJourney
+	journey := struct {
+		estimatedVehicleJourney
+		DatedVehicleJourneyRef string `xml:"DatedVehicleJourneyRef"`
+	}{}
+	if err := d.DecodeElement(&journey, &start); err != nil {
+		return err
+	}
+
+	*j = EstimatedVehicleJourney(journey.estimatedVehicleJourney)
+	if j.FramedVehicleJourneyRef.DatedVehicleJourneyRef == "" {
+		j.FramedVehicleJourneyRef.DatedVehicleJourneyRef = journey.DatedVehicleJourneyRef
+	}
+
+	return nil
+}
+
+// RecordedCall represents the Siri Recorded Call XML, a stop the journey has already called at
+type RecordedCall struct {
+	StopPointRef        string `xml:"StopPointRef"`
+	Order               int    `xml:"Order"`
+	StopPointName       string `xml:"StopPointName"`
+	AimedArrivalTime    string `xml:"AimedArrivalTime"`
+	ActualArrivalTime   string `xml:"ActualArrivalTime"`
+	AimedDepartureTime  string `xml:"AimedDepartureTime"`
+	ActualDepartureTime string `xml:"ActualDepartureTime"`
+}
+
+// UnmarshalXML maps the position of the call from either Siri version,
+// Siri 1.0 orders the calls of the journey by VisitNumber rather than Order
+func (c *RecordedCall) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type recordedCall RecordedCall
+	call := struct {
+		recordedCall
+		VisitNumber int `xml:"VisitNumber"`
+	}{}
+	if err := d.DecodeElement(&call, &start); err != nil {
+		return err
+	}
+
+	*c = RecordedCall(call.recordedCall)
+	if c.Order == 0 {
+		c.Order = call.VisitNumber
+	}
+
+	return nil
+}
+
+// EstimatedCall represents the Siri Estimated Call XML, a stop the journey is still to call at
+type EstimatedCall struct {
+	StopPointRef          string `xml:"StopPointRef"`
+	Order                 int    `xml:"Order"`
+	StopPointName         string `xml:"StopPointName"`
+	AimedArrivalTime      string `xml:"AimedArrivalTime"`
+	ExpectedArrivalTime   string `xml:"ExpectedArrivalTime"`
+	AimedDepartureTime    string `xml:"AimedDepartureTime"`
+	ExpectedDepartureTime string `xml:"ExpectedDepartureTime"`
+}
+
+// UnmarshalXML maps the position of the call from either Siri version,
+// Siri 1.0 orders the calls of the journey by VisitNumber rather than Order
+func (c *EstimatedCall) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type estimatedCall EstimatedCall
+	call := struct {
+		estimatedCall
+		VisitNumber int `xml:"VisitNumber"`
+	}{}
+	if err := d.DecodeElement(&call, &start); err != nil {
+		return err
+	}
+
+	*c = EstimatedCall(call.estimatedCall)
+	if c.Order == 0 {
+		c.Order = call.VisitNumber
+	}
+
+	return nil
+}
+
+// SubscriptionRequest represents the Siri Subscription Request XML, only one of the subscription requests is set
+type SubscriptionRequest struct {
+	Requestor
+	ConsumerAddress                      string                                `xml:"ConsumerAddress"`
+	SubscriptionContext                  *SubscriptionContext                  `xml:"SubscriptionContext,omitempty"`
+	StopMonitoringSubscriptionRequest    *StopMonitoringSubscriptionRequest    `xml:"StopMonitoringSubscriptionRequest,omitempty"`
+	VehicleMonitoringSubscriptionRequest *VehicleMonitoringSubscriptionRequest `xml:"VehicleMonitoringSubscriptionRequest,omitempty"`
+	SituationExchangeSubscriptionRequest *SituationExchangeSubscriptionRequest `xml:"SituationExchangeSubscriptionRequest,omitempty"`
+}
+
+// SubscriptionContext represents the Siri Subscription Context XML
+type SubscriptionContext struct {
+	HeartbeatInterval string `xml:"HeartbeatInterval"`
+}
+
+// StopMonitoringSubscriptionRequest represents the Siri Stop Monitoring Subscription Request XML
+type StopMonitoringSubscriptionRequest struct {
+	SubscriberRef          string `xml:"SubscriberRef"`
+	SubscriptionIdentifier string `xml:"SubscriptionIdentifier"`
+	InitialTerminationTime string `xml:"InitialTerminationTime"`
+	RequestTimestamp       string `xml:"StopMonitoringRequest>RequestTimestamp"`
+	MonitoringRef          string `xml:"StopMonitoringRequest>MonitoringRef"`
+}
+
+// VehicleMonitoringSubscriptionRequest represents the Siri Vehicle Monitoring Subscription Request XML
+type VehicleMonitoringSubscriptionRequest struct {
+	SubscriberRef          string `xml:"SubscriberRef"`
+	SubscriptionIdentifier string `xml:"SubscriptionIdentifier"`
+	InitialTerminationTime string `xml:"InitialTerminationTime"`
+	RequestTimestamp       string `xml:"VehicleMonitoringRequest>RequestTimestamp"`
+	LineRef                string `xml:"VehicleMonitoringRequest>LineRef,omitempty"`
+	OperatorRef            string `xml:"VehicleMonitoringRequest>OperatorRef,omitempty"`
+}
+
+// SituationExchangeSubscriptionRequest represents the Siri Situation Exchange Subscription Request XML
+type SituationExchangeSubscriptionRequest struct {
+	SubscriberRef          string `xml:"SubscriberRef"`
+	SubscriptionIdentifier string `xml:"SubscriptionIdentifier"`
+	InitialTerminationTime string `xml:"InitialTerminationTime"`
+	RequestTimestamp       string `xml:"SituationExchangeRequest>RequestTimestamp"`
+}
+
+// SubscriptionResponse represents the Siri Subscription Response XML
+type SubscriptionResponse struct {
+	XMLName              xml.Name `xml:"Siri"`
+	SubscriptionResponse struct {
+		ResponseTimestamp string           `xml:"ResponseTimestamp"`
+		ResponderRef      string           `xml:"ResponderRef"`
+		ResponseStatus    []ResponseStatus `xml:"ResponseStatus"`
+	} `xml:"SubscriptionResponse"`
+}
+
+// ResponseStatus represents the Siri Response Status XML, the outcome of a subscription or its termination
+type ResponseStatus struct {
+	SubscriptionRef string `xml:"SubscriptionRef"`
+	Status          bool   `xml:"Status"`
+	ValidUntil      string `xml:"ValidUntil"`
+	ErrorCondition  struct {
+		Description string `xml:"Description"`
+	} `xml:"ErrorCondition"`
+}
+
+// TerminateSubscriptionRequest represents the Siri Terminate Subscription Request XML,
+// all subscriptions are terminated if All is set
+type TerminateSubscriptionRequest struct {
+	Requestor
+	All             *struct{} `xml:"All,omitempty"`
+	SubscriptionRef []string  `xml:"SubscriptionRef,omitempty"`
+}
+
+// TerminateSubscriptionResponse represents the Siri Terminate Subscription Response XML
+type TerminateSubscriptionResponse struct {
+	XMLName                       xml.Name `xml:"Siri"`
+	TerminateSubscriptionResponse struct {
+		ResponseTimestamp         string           `xml:"ResponseTimestamp"`
+		TerminationResponseStatus []ResponseStatus `xml:"TerminationResponseStatus"`
+	} `xml:"TerminateSubscriptionResponse"`
+}
+
+// PushedDelivery represents the Siri XML pushed by a producer to the consumer address of a subscription,
+// either a Service Delivery for any of the subscribed services or a Heartbeat Notification
+type PushedDelivery struct {
+	XMLName         xml.Name `xml:"Siri"`
+	Version         string   `xml:"version,attr"`
+	ServiceDelivery *struct {
+		ResponseTimestamp         string                      `xml:"ResponseTimestamp"`
+		ProducerRef               string                      `xml:"ProducerRef"`
+		StopMonitoringDelivery    []StopMonitoringDelivery    `xml:"StopMonitoringDelivery"`
+		VehicleMonitoringDelivery []VehicleMonitoringDelivery `xml:"VehicleMonitoringDelivery"`
+		SituationExchangeDelivery []SituationExchangeDelivery `xml:"SituationExchangeDelivery"`
+	} `xml:"ServiceDelivery"`
+	HeartbeatNotification *struct {
+		RequestTimestamp string `xml:"RequestTimestamp"`
+		ProducerRef      string `xml:"ProducerRef"`
+		Status           bool   `xml:"Status"`
+	} `xml:"HeartbeatNotification"`
+}
+
+// DataReceivedAcknowledgement represents the Siri Data Received Acknowledgement XML, the reply to a pushed delivery
+type DataReceivedAcknowledgement struct {
+	XMLName           xml.Name `xml:"Siri"`
+	Version           string   `xml:"version,attr"`
+	XMLNS             string   `xml:"xmlns,attr"`
+	ResponseTimestamp string   `xml:"DataReceivedAcknowledgement>ResponseTimestamp"`
+	ConsumerRef       string   `xml:"DataReceivedAcknowledgement>ConsumerRef,omitempty"`
+	Status            bool     `xml:"DataReceivedAcknowledgement>Status"`
+	ErrorDescription  string   `xml:"DataReceivedAcknowledgement>ErrorCondition>Description,omitempty"`
+}
+
+// MonitoredStopVisit represents the Siri Monitored Stop Visit XML, a journey calling at the monitored stop
+type MonitoredStopVisit struct {
+	RecordedAtTime          string                  `xml:"RecordedAtTime"`
+	MonitoringRef           string                  `xml:"MonitoringRef"`
+	MonitoredVehicleJourney MonitoredVehicleJourney `xml:"MonitoredVehicleJourney"`
+}
+
+// CheckStatusResponse represents the Siri Check Status Response XML
+type CheckStatusResponse struct {
+	XMLName             xml.Name       `xml:"Siri"`
+	CheckStatusResponse ProducerStatus `xml:"CheckStatusResponse"`
+}
+
+// ProducerStatus represents the status of the producer in the Siri Check Status Response XML
+type ProducerStatus struct {
+	ResponseTimestamp string `xml:"ResponseTimestamp"`
+	ProducerRef       string `xml:"ProducerRef"`
+	Status            bool   `xml:"Status"`
+	ErrorCondition    struct {
+		Description string `xml:"Description"`
+	} `xml:"ErrorCondition"`
+	ServiceStartedTime string `xml:"ServiceStartedTime"`
+}
+
+// StopPointsDelivery represents the Siri Stop Points Discovery Delivery XML
+type StopPointsDelivery struct {
+	XMLName            xml.Name `xml:"Siri"`
+	StopPointsDelivery struct {
+		ResponseTimestamp     string                  `xml:"ResponseTimestamp"`
+		AnnotatedStopPointRef []AnnotatedStopPointRef `xml:"AnnotatedStopPointRef"`
+	} `xml:"StopPointsDelivery"`
+}
+
+// AnnotatedStopPointRef represents a stop covered by the producer in the Siri Stop Points Discovery Delivery XML
+type AnnotatedStopPointRef struct {
+	StopPointRef string   `xml:"StopPointRef"`
+	Monitored    bool     `xml:"Monitored"`
+	StopName     string   `xml:"StopName"`
+	LineRef      []string `xml:"Lines>LineRef"`
+}
+
+// UnmarshalXML maps the lines of the stop from either Siri version,
+// Siri 2.0 wraps the LineRef of each line in a LineDirection
+func (s *AnnotatedStopPointRef) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type annotatedStopPointRef AnnotatedStopPointRef
+	stopPoint := struct {
+		annotatedStopPointRef
+		LineDirectionRef []string `xml:"Lines>LineDirection>LineRef"`
+	}{}
+	if err := d.DecodeElement(&stopPoint, &start); err != nil {
+		return err
+	}
+
+	*s = AnnotatedStopPointRef(stopPoint.annotatedStopPointRef)
+	s.LineRef = append(s.LineRef, stopPoint.LineDirectionRef...)
+
+	return nil
+}
+
+// LinesDelivery represents the Siri Lines Discovery Delivery XML
+type LinesDelivery struct {
+	XMLName       xml.Name `xml:"Siri"`
+	LinesDelivery struct {
+		ResponseTimestamp string             `xml:"ResponseTimestamp"`
+		AnnotatedLineRef  []AnnotatedLineRef `xml:"AnnotatedLineRef"`
+	} `xml:"LinesDelivery"`
+}
+
+// AnnotatedLineRef represents a line covered by the producer in the Siri Lines Discovery Delivery XML
+type AnnotatedLineRef struct {
+	LineRef      string `xml:"LineRef"`
+	LineName     string `xml:"LineName"`
+	Monitored    bool   `xml:"Monitored"`
+	Destinations []struct {
+		DestinationRef string `xml:"DestinationRef"`
+		PlaceName      string `xml:"PlaceName"`
+	} `xml:"Destinations>Destination"`
+	Directions []struct {
+		DirectionRef  string `xml:"DirectionRef"`
+		DirectionName string `xml:"DirectionName"`
+	} `xml:"Directions>Direction"`
+}
+
+// soapFault represents the Fault in the body of a SOAP 1.1 or SOAP 1.2 envelope
+type soapFault struct {
+	FaultCode   string `xml:"faultcode"`
+	FaultString string `xml:"faultstring"`
+	Code        string `xml:"Code>Value"`
+	Subcode     string `xml:"Code>Subcode>Value"`
+	Reason      string `xml:"Reason>Text"`
 }
@@ -0,0 +1,65 @@
+package traveline
+
+import (
+	"log"
+	"time"
+)
+
+// VehicleFilter selects the vehicles in a Vehicle Monitoring request, all vehicles are requested if not set
+type VehicleFilter struct {
+	LineRef     string
+	OperatorRef string
+}
+
+// Matches returns whether the vehicle activity is for the line and operator of the filter,
+// as not all producers filter the vehicles they return
+func (f VehicleFilter) Matches(activity VehicleActivity) bool {
+	journey := activity.MonitoredVehicleJourney
+	if f.LineRef != "" && f.LineRef != journey.LineRef && f.LineRef != journey.PublishedLineName {
+		return false
+	}
+
+	return f.OperatorRef == "" || f.OperatorRef == journey.OperatorRef
+}
+
+// BuildVehicleMonitoringRequest will return the XML for the request for the positions of the vehicles
+// that the filter selects
+func (c *Client) BuildVehicleMonitoringRequest(requestRef string, filter VehicleFilter, when time.Time) (string, error) {
+	request, err := c.NewServiceRequest(when)
+	if err != nil {
+		return "", err
+	}
+
+	vehicleMonitoringRequest := VehicleMonitoringRequest{
+		FunctionalRequest: NewFunctionalRequest(requestRef, when),
+		LineRef:           filter.LineRef,
+		OperatorRef:       filter.OperatorRef,
+	}
+	serviceRequest := request.ServiceRequest
+	serviceRequest.VehicleMonitoringRequest = append(serviceRequest.VehicleMonitoringRequest, vehicleMonitoringRequest)
+
+	log.Printf("VehicleMonitoringRequestMessageIdentifier: %s", vehicleMonitoringRequest.MessageIdentifier)
+
+	return request.Marshal()
+}
+
+// ParseVehicleMonitoringDelivery will parse the response from the Traveline API and return the vehicle activities
+// in the deliveries for the request
+func (c *Client) ParseVehicleMonitoringDelivery(requestRef string, response string) ([]VehicleActivity, error) {
+	serviceDelivery := ServiceDelivery{}
+	err := unmarshal([]byte(response), &serviceDelivery, c.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	var activities []VehicleActivity
+	for _, delivery := range serviceDelivery.ServiceDelivery.VehicleMonitoringDelivery {
+		if !delivery.answers(requestRef) {
+			continue
+		}
+		log.Printf("RequestMessageRef: %s, Vehicles: %d", delivery.RequestMessageRef, len(delivery.VehicleActivity))
+		activities = append(activities, delivery.VehicleActivity...)
+	}
+
+	return activities, nil
+}
@@ -0,0 +1,303 @@
+package traveline_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/conradhodge/travel-api-client/internal/siritest"
+	"github.com/conradhodge/travel-api-client/traveline"
+)
+
+func TestBuildVehicleMonitoringRequest(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+
+	tests := []struct {
+		name            string
+		filter          traveline.VehicleFilter
+		expectedRequest string
+	}{
+		{
+			name:   "All vehicles",
+			filter: traveline.VehicleFilter{},
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<VehicleMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`</VehicleMonitoringRequest></ServiceRequest></Siri>`,
+		},
+		{
+			name:   "Line and operator",
+			filter: traveline.VehicleFilter{LineRef: "42", OperatorRef: "GLBE"},
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<VehicleMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<LineRef>42</LineRef><OperatorRef>GLBE</OperatorRef>` +
+				`</VehicleMonitoringRequest></ServiceRequest></Siri>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+			request, err := client.BuildVehicleMonitoringRequest("ab7c1e9b-d06f-44cc-b190-4d36fb564386", test.filter, when)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			if request != test.expectedRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedRequest, request))
+			}
+		})
+	}
+}
+
+func TestParseVehicleMonitoringDelivery(t *testing.T) {
+	response, err := os.ReadFile("testdata/vehicle_monitoring.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tests := []struct {
+		name             string
+		response         string
+		expectedVehicles []string
+		expectedError    error
+	}{
+		{
+			name:             "Vehicle activities",
+			response:         string(response),
+			expectedVehicles: []string{"GLBE-1001", "ABCD-2002"},
+		},
+		{
+			name:     "No vehicles",
+			response: `<Siri><ServiceDelivery><VehicleMonitoringDelivery></VehicleMonitoringDelivery></ServiceDelivery></Siri>`,
+		},
+		{
+			name:          "Invalid XML",
+			response:      `<Siri>`,
+			expectedError: errors.New("XML syntax error on line 1: unexpected EOF"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+
+			activities, err := client.ParseVehicleMonitoringDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", test.response)
+
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			if len(activities) != len(test.expectedVehicles) {
+				t.Fatalf("Expected %d vehicles; got %d", len(test.expectedVehicles), len(activities))
+			}
+			for i, activity := range activities {
+				if activity.MonitoredVehicleJourney.VehicleRef != test.expectedVehicles[i] {
+					t.Fatalf("Expected vehicle '%s'; got '%s'", test.expectedVehicles[i], activity.MonitoredVehicleJourney.VehicleRef)
+				}
+			}
+		})
+	}
+
+	// Check the fields of the first vehicle are parsed
+	client := traveline.NewClient("TravelineAPI999", "letmein", &http.Client{})
+	activities, _ := client.ParseVehicleMonitoringDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", string(response))
+	journey := activities[0].MonitoredVehicleJourney
+	if journey.VehicleLocation.Latitude != 51.94911 || journey.VehicleLocation.Longitude != -0.53385 {
+		t.Fatalf("Expected location 51.94911,-0.53385; got %f,%f", journey.VehicleLocation.Latitude, journey.VehicleLocation.Longitude)
+	}
+	if journey.Bearing != 180 || journey.Delay != "PT2M30S" || journey.LineRef != "42" {
+		t.Fatalf("Unexpected vehicle journey: %+v", journey)
+	}
+}
+
+func TestVehicleMonitoringVersions(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+
+	tests := []struct {
+		name            string
+		version         string
+		expectedRequest string
+		response        string
+	}{
+		{
+			name:    "Siri 1.0",
+			version: traveline.SiriVersion1,
+			expectedRequest: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<VehicleMonitoringRequest><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<LineRef>42</LineRef></VehicleMonitoringRequest></ServiceRequest></Siri>`,
+			response: `<Siri version="1.0" xmlns="http://www.siri.org.uk/"><ServiceDelivery><VehicleMonitoringDelivery>` +
+				`<VehicleActivity><MonitoredVehicleJourney><LineRef>42</LineRef>` +
+				`<FramedVehicleJourneyRef><DatedVehicleJourneyRef>1234</DatedVehicleJourneyRef></FramedVehicleJourneyRef>` +
+				`<VehicleLocation><Longitude>-0.53385</Longitude><Latitude>51.94911</Latitude></VehicleLocation>` +
+				`<VehicleRef>GLBE-1001</VehicleRef></MonitoredVehicleJourney></VehicleActivity>` +
+				`</VehicleMonitoringDelivery></ServiceDelivery></Siri>`,
+		},
+		{
+			name:    "Siri 2.0",
+			version: traveline.SiriVersion2,
+			expectedRequest: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><ServiceRequest>` +
+				`<RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp><RequestorRef>TravelineAPI999</RequestorRef>` +
+				`<VehicleMonitoringRequest version="2.0"><RequestTimestamp>2020-03-30T12:34:56+01:00</RequestTimestamp>` +
+				`<MessageIdentifier>ab7c1e9b-d06f-44cc-b190-4d36fb564386</MessageIdentifier>` +
+				`<LineRef>42</LineRef></VehicleMonitoringRequest></ServiceRequest></Siri>`,
+			response: `<Siri version="2.0" xmlns="http://www.siri.org.uk/siri"><ServiceDelivery><VehicleMonitoringDelivery version="2.0">` +
+				`<VehicleActivity><MonitoredVehicleJourney><LineRef>42</LineRef>` +
+				`<FramedVehicleJourneyRef><DatedVehicleJourneyRef>1234</DatedVehicleJourneyRef></FramedVehicleJourneyRef>` +
+				`<VehicleLocation><Longitude>-0.53385</Longitude><Latitude>51.94911</Latitude></VehicleLocation>` +
+				`<VehicleRef>GLBE-1001</VehicleRef></MonitoredVehicleJourney></VehicleActivity>` +
+				`</VehicleMonitoringDelivery></ServiceDelivery></Siri>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &traveline.Client{Username: "TravelineAPI999", Password: "letmein", Version: test.version}
+
+			request, err := client.BuildVehicleMonitoringRequest(
+				"ab7c1e9b-d06f-44cc-b190-4d36fb564386",
+				traveline.VehicleFilter{LineRef: "42"},
+				when,
+			)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if request != test.expectedRequest {
+				t.Fatalf("Request not as expected (~~want ++got):\n%s", diff.CharacterDiff(test.expectedRequest, request))
+			}
+
+			activities, err := client.ParseVehicleMonitoringDelivery("ab7c1e9b-d06f-44cc-b190-4d36fb564386", test.response)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if len(activities) != 1 {
+				t.Fatalf("Expected 1 vehicle; got %d", len(activities))
+			}
+			journey := activities[0].MonitoredVehicleJourney
+			if journey.VehicleRef != "GLBE-1001" || journey.LineRef != "42" || journey.FramedVehicleJourneyRef.DatedVehicleJourneyRef != "1234" {
+				t.Fatalf("Unexpected vehicle journey: %+v", journey)
+			}
+			if journey.VehicleLocation.Latitude != 51.94911 || journey.VehicleLocation.Longitude != -0.53385 {
+				t.Fatalf("Expected location 51.94911,-0.53385; got %f,%f", journey.VehicleLocation.Latitude, journey.VehicleLocation.Longitude)
+			}
+		})
+	}
+}
+
+func TestVehicleFilterMatches(t *testing.T) {
+	activity := traveline.VehicleActivity{}
+	activity.MonitoredVehicleJourney.LineRef = "GLBE:42"
+	activity.MonitoredVehicleJourney.PublishedLineName = "42"
+	activity.MonitoredVehicleJourney.OperatorRef = "GLBE"
+
+	tests := []struct {
+		name     string
+		filter   traveline.VehicleFilter
+		expected bool
+	}{
+		{name: "No filter", filter: traveline.VehicleFilter{}, expected: true},
+		{name: "Line ref", filter: traveline.VehicleFilter{LineRef: "GLBE:42"}, expected: true},
+		{name: "Published line name", filter: traveline.VehicleFilter{LineRef: "42"}, expected: true},
+		{name: "Other line", filter: traveline.VehicleFilter{LineRef: "43"}, expected: false},
+		{name: "Operator", filter: traveline.VehicleFilter{OperatorRef: "GLBE"}, expected: true},
+		{name: "Other operator", filter: traveline.VehicleFilter{LineRef: "42", OperatorRef: "ABCD"}, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := test.filter.Matches(activity); result != test.expected {
+				t.Fatalf("Expected %t; got %t", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestVehicleMonitoringWithFakeServer(t *testing.T) {
+	when, _ := time.Parse(time.RFC3339, "2020-03-30T12:34:56+01:00")
+	response, err := os.ReadFile("testdata/vehicle_monitoring.xml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	tests := []struct {
+		name             string
+		statusCode       int
+		response         string
+		expectedVehicles int
+		expectedError    error
+	}{
+		{
+			name:             "Vehicles returned",
+			statusCode:       http.StatusOK,
+			response:         string(response),
+			expectedVehicles: 2,
+		},
+		{
+			name:          "Error status",
+			statusCode:    http.StatusServiceUnavailable,
+			response:      "Service unavailable",
+			expectedError: errors.New("error status from API: 503"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := siritest.NewProducer(t).
+				WithAuth("TravelineAPI999", "letmein").
+				Respond("VehicleMonitoringRequest", test.statusCode, test.response)
+			defer server.Close()
+
+			client := &traveline.Client{
+				Username: "TravelineAPI999",
+				Password: "letmein",
+				URL:      server.URL,
+				Client:   server.Client(),
+			}
+
+			request, err := client.BuildVehicleMonitoringRequest("1", traveline.VehicleFilter{LineRef: "42"}, when)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			response, err := client.Send(request)
+			if test.expectedError != nil {
+				if err == nil {
+					t.Fatalf("Expected error '%s'; got no error", test.expectedError)
+				}
+				if err.Error() != test.expectedError.Error() {
+					t.Fatalf("Expected error '%s'; got '%s'", test.expectedError.Error(), err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+
+			activities, err := client.ParseVehicleMonitoringDelivery("1", response)
+			if err != nil {
+				t.Fatalf("Expected no error; got '%s'", err)
+			}
+			if len(activities) != test.expectedVehicles {
+				t.Fatalf("Expected %d vehicles; got %d", test.expectedVehicles, len(activities))
+			}
+		})
+	}
+}